@@ -2,16 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	_ "github.com/Ktuty/docs"
+	"github.com/Ktuty/internal/enrichment"
 	"github.com/Ktuty/internal/handlers"
 	"github.com/Ktuty/internal/repository"
 	"github.com/Ktuty/internal/services"
 	"github.com/Ktuty/server"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
@@ -40,40 +46,148 @@ func main() {
 	// Установка формата логирования
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 
-	db, err := repository.NewPostgres(repository.Config{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     os.Getenv("DB_PORT"),
-		Username: os.Getenv("DB_USER"),
-		Password: os.Getenv("DB_PASS"),
-		DBName:   os.Getenv("DB_NAME"),
-		SSLMode:  os.Getenv("DB_SSLMODE"),
-	})
-	if err != nil {
-		logrus.Fatalf("failed to initialize db: %s", err.Error())
+	// Выбор хранилища: PostgreSQL по умолчанию или память для локальных запусков
+	var db *pgxpool.Pool
+	var repo *repository.Repository
+	if strings.ToLower(os.Getenv("STORAGE")) == "memory" {
+		repo = repository.NewMemoryRepository()
+		logrus.Printf("using in-memory storage")
+	} else {
+		db, err = repository.NewPostgres(repository.Config{
+			Host:           os.Getenv("DB_HOST"),
+			Port:           os.Getenv("DB_PORT"),
+			Username:       os.Getenv("DB_USER"),
+			Password:       os.Getenv("DB_PASS"),
+			DBName:         os.Getenv("DB_NAME"),
+			SSLMode:        os.Getenv("DB_SSLMODE"),
+			SearchLanguage: os.Getenv("SEARCH_LANGUAGE"),
+		})
+		if err != nil {
+			logrus.Fatalf("failed to initialize db: %s", err.Error())
+		}
+
+		repo = repository.NewRepository(db, repository.Options{
+			QueryTimeout:   getEnvAsDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+			FuzzyThreshold: getEnvAsFloat("FUZZY_THRESHOLD", 0),
+		})
 	}
 
-	repo := repository.NewRepository(db)
-	service := services.NewService(repo)
-	handler := handlers.NewHandler(service, os.Getenv("URL"))
+	// Клиент внешнего API со сведениями о песнях; без URL песни не обогащаются
+	// и получают статус обогащения failed
+	var client enrichment.Client = enrichment.DisabledClient{}
+	if apiURL := os.Getenv("URL"); apiURL != "" {
+		httpClient, err := enrichment.NewHTTPClient(enrichment.Config{
+			URL:              apiURL,
+			Timeout:          getEnvAsDuration("EXTERNAL_API_TIMEOUT", 10*time.Second),
+			MaxRetries:       getEnvAsInt("EXTERNAL_API_RETRIES", 2),
+			RetryBackoff:     getEnvAsDuration("EXTERNAL_API_RETRY_BACKOFF", 500*time.Millisecond),
+			MaxRetryBackoff:  getEnvAsDuration("EXTERNAL_API_MAX_RETRY_BACKOFF", 5*time.Second),
+			BreakerThreshold: getEnvAsInt("EXTERNAL_API_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  getEnvAsDuration("EXTERNAL_API_BREAKER_COOLDOWN", 30*time.Second),
+		})
+		if err != nil {
+			logrus.Fatalf("failed to initialize external API client: %s", err.Error())
+		}
+		client = httpClient
+	} else {
+		logrus.Warn("URL is not set, songs will not be enriched from the external API")
+	}
+
+	// Фоновое обогащение новых песен сведениями внешнего API
+	enricher := services.NewEnricher(repo, client, services.EnricherOptions{
+		Workers:      getEnvAsInt("ENRICH_WORKERS", 4),
+		MaxAttempts:  getEnvAsInt("ENRICH_MAX_ATTEMPTS", 5),
+		Timeout:      getEnvAsDuration("ENRICH_TIMEOUT", time.Minute),
+		Backoff:      getEnvAsDuration("ENRICH_BACKOFF", 5*time.Second),
+		MaxBackoff:   getEnvAsDuration("ENRICH_MAX_BACKOFF", 10*time.Minute),
+		PollInterval: getEnvAsDuration("ENRICH_POLL_INTERVAL", 5*time.Second),
+	})
+
+	service := services.NewService(repo, enricher)
+	handler := handlers.NewHandler(service)
 
 	srv := new(server.Server)
 	go func() {
-		if err := srv.Run(os.Getenv("port"), handler.InitRouts()); err != nil {
+		if err := srv.Run(os.Getenv("port"), handler.InitRouts()); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logrus.Fatalf("error occurred while running http server: %s", err.Error())
 		}
 	}()
 
 	logrus.Printf("Server Started on port %s", os.Getenv("port"))
 
+	// Фоновая очистка корзины; TRASH_PURGE_INTERVAL=0 отключает её
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if interval := getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour); interval > 0 {
+		go services.NewPurger(repo, getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour), interval).Run(purgeCtx)
+	}
+
+	enrichCtx, stopEnrich := context.WithCancel(context.Background())
+	defer stopEnrich()
+	enrichDone := make(chan struct{})
+	go func() {
+		defer close(enrichDone)
+		enricher.Run(enrichCtx)
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
 	logrus.Printf("Server Shutdown")
+	stopPurge()
+	stopEnrich()
 
-	if err := srv.Shutdown(context.Background()); err != nil {
-		logrus.Fatalf("error server Shutdown Failed: %s", err.Error())
+	// Ожидание завершения активных запросов не дольше SHUTDOWN_TIMEOUT
+	ctx, cancel := context.WithTimeout(context.Background(), getEnvAsDuration("SHUTDOWN_TIMEOUT", 15*time.Second))
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logrus.Errorf("error server Shutdown Failed: %s", err.Error())
 	}
 
-	db.Close()
+	// Начатые попытки обогащения сохраняют результат до закрытия пула соединений
+	<-enrichDone
+	if db != nil {
+		db.Close()
+	}
+}
+
+// Функция для получения длительности из переменной окружения с значением по умолчанию
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		logrus.Fatalf("error parsing %s: %s", key, err.Error())
+	}
+	return value
+}
+
+// Функция для получения целого числа из переменной окружения с значением по умолчанию
+func getEnvAsInt(key string, defaultValue int) int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		logrus.Fatalf("error parsing %s: %s", key, err.Error())
+	}
+	return value
+}
+
+// Функция для получения дробного числа из переменной окружения с значением по умолчанию
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		logrus.Fatalf("error parsing %s: %s", key, err.Error())
+	}
+	return value
 }
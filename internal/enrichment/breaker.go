@@ -0,0 +1,89 @@
+package enrichment
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния предохранителя
+const (
+	breakerClosed   = iota // запросы проходят, неудачи подряд подсчитываются
+	breakerOpen            // запросы отклоняются до истечения паузы
+	breakerHalfOpen        // после паузы проходит один пробный запрос
+)
+
+// Структура breaker - предохранитель, который после threshold неудач подряд перестаёт пропускать
+// запросы на время cooldown, а затем пропускает один пробный запрос
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     int
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// Функция для создания предохранителя; threshold меньше 1 отключает его
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Метод для проверки, можно ли отправить запрос. Разрешённый запрос должен сообщить результат через done или cancel.
+func (b *breaker) allow() bool {
+	if b.threshold < 1 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// Пробный запрос уже отправлен
+		return false
+	default:
+		return true
+	}
+}
+
+// Метод для учёта результата разрешённого запроса: failed - внешний API недоступен
+func (b *breaker) done(failed bool) {
+	if b.threshold < 1 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state, b.openedAt = breakerOpen, b.now()
+	}
+}
+
+// Метод для учёта разрешённого запроса, прерванного вызывающим кодом: его результат ничего не говорит
+// о внешнем API, поэтому после паузы будет пропущен новый пробный запрос
+func (b *breaker) cancel() {
+	if b.threshold < 1 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
@@ -0,0 +1,67 @@
+package enrichment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	// Шаги сценария: allow и deny - ожидаемый результат allow, ok и fail - результат
+	// пропущенного запроса, cancel - прерванный запрос, wait - истечение паузы предохранителя
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []string
+	}{
+		{
+			name:      "opens after threshold failures in a row",
+			threshold: 2,
+			steps:     []string{"allow", "fail", "allow", "ok", "allow", "fail", "allow", "fail", "deny", "deny"},
+		},
+		{
+			name:      "half-open probe closes on success",
+			threshold: 1,
+			steps:     []string{"allow", "fail", "deny", "wait", "allow", "deny", "ok", "allow", "fail", "deny"},
+		},
+		{
+			name:      "failed probe opens again",
+			threshold: 3,
+			steps:     []string{"allow", "fail", "allow", "fail", "allow", "fail", "wait", "allow", "fail", "deny", "wait", "allow", "ok", "allow"},
+		},
+		{
+			name:      "cancelled probe lets another probe through",
+			threshold: 1,
+			steps:     []string{"allow", "fail", "wait", "allow", "cancel", "allow", "deny", "fail", "deny"},
+		},
+		{
+			name:      "disabled",
+			threshold: 0,
+			steps:     []string{"allow", "fail", "allow", "fail", "allow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			b := newBreaker(tt.threshold, time.Minute)
+			b.now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				switch step {
+				case "allow", "deny":
+					if got := b.allow(); got != (step == "allow") {
+						t.Fatalf("step %d: allow = %t, want %t", i, got, step == "allow")
+					}
+				case "ok":
+					b.done(false)
+				case "fail":
+					b.done(true)
+				case "cancel":
+					b.cancel()
+				case "wait":
+					now = now.Add(time.Minute)
+				}
+			}
+		})
+	}
+}
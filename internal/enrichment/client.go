@@ -0,0 +1,204 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Наибольший размер ответа внешнего API со сведениями о песне
+const maxResponseSize = 4 << 20
+
+// Интерфейс Client, получающий сведения о песне из внешнего API
+type Client interface {
+	// Метод для получения песни группы с датой выпуска, текстом и ссылкой из внешнего API.
+	// Причину ошибки можно узнать через errors.Is с ErrNotFound, ErrRejected, ErrUnavailable,
+	// ErrInvalidResponse и ErrDisabled.
+	FetchSong(ctx context.Context, group, song string) (models.Songs, error)
+}
+
+// Структура Config с настройками клиента внешнего API
+type Config struct {
+	URL              string        // адрес метода /info внешнего API
+	Timeout          time.Duration // ограничение времени одного запроса (0 - без ограничения)
+	MaxRetries       int           // количество повторов после первого запроса, если внешний API недоступен
+	RetryBackoff     time.Duration // задержка перед первым повтором, удваивается с каждым следующим
+	MaxRetryBackoff  time.Duration // наибольшая задержка перед повтором
+	BreakerThreshold int           // количество неудач подряд, после которого запросы приостанавливаются (0 - без предохранителя)
+	BreakerCooldown  time.Duration // пауза предохранителя перед пробным запросом
+}
+
+// Структура DisabledClient, реализующая Client без внешнего API: используется, когда адрес
+// внешнего API не настроен, например при запуске с STORAGE=memory для разработки
+type DisabledClient struct{}
+
+// Метод, сразу возвращающий ErrDisabled
+func (DisabledClient) FetchSong(ctx context.Context, group, song string) (models.Songs, error) {
+	return models.Songs{}, ErrDisabled
+}
+
+// Структура HTTPClient, реализующая Client запросами GET <url>?group=...&song=...
+type HTTPClient struct {
+	url     *url.URL
+	client  *http.Client
+	config  Config
+	breaker *breaker
+}
+
+// Функция для создания нового экземпляра HTTPClient; адрес внешнего API должен быть абсолютным
+func NewHTTPClient(config Config) (*HTTPClient, error) {
+	endpoint, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external API url: %w", err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid external API url %q: must be an absolute http or https URL", config.URL)
+	}
+
+	config.MaxRetries = max(config.MaxRetries, 0)
+	config.MaxRetryBackoff = max(config.MaxRetryBackoff, config.RetryBackoff)
+	return &HTTPClient{
+		url:     endpoint,
+		client:  &http.Client{Timeout: config.Timeout},
+		config:  config,
+		breaker: newBreaker(config.BreakerThreshold, config.BreakerCooldown),
+	}, nil
+}
+
+// Метод для получения песни из внешнего API. Если внешний API недоступен, запрос повторяется
+// до MaxRetries раз со случайной экспоненциальной задержкой; при открытом предохранителе
+// запрос не отправляется и сразу возвращается ErrCircuitOpen. Если предохранитель открылся
+// из-за неудач этого же вызова, возвращается ошибка последнего отправленного запроса.
+func (c *HTTPClient) FetchSong(ctx context.Context, group, song string) (models.Songs, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		detail, err := c.fetch(ctx, group, song)
+		if errors.Is(err, ErrCircuitOpen) && lastErr != nil {
+			return models.Songs{}, lastErr
+		}
+		lastErr = err
+		if err == nil {
+			if err := detail.validate(); err != nil {
+				return models.Songs{}, err
+			}
+			return detail.toSong(group, song), nil
+		}
+
+		retry := errors.Is(err, ErrUnavailable) && !errors.Is(err, ErrCircuitOpen) && attempt < c.config.MaxRetries
+		if !retry {
+			return models.Songs{}, err
+		}
+
+		delay := c.retryDelay(attempt)
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Warn("Retrying external API request")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return models.Songs{}, err
+		case <-timer.C:
+		}
+	}
+}
+
+// Метод для одного запроса через предохранитель
+func (c *HTTPClient) fetch(ctx context.Context, group, song string) (songDetail, error) {
+	if !c.breaker.allow() {
+		return songDetail{}, ErrCircuitOpen
+	}
+
+	detail, err := c.do(ctx, group, song)
+	if err != nil && ctx.Err() != nil {
+		c.breaker.cancel()
+	} else {
+		c.breaker.done(errors.Is(err, ErrUnavailable))
+	}
+	return detail, err
+}
+
+// Метод для отправки запроса и разбора ответа по статусу
+func (c *HTTPClient) do(ctx context.Context, group, song string) (songDetail, error) {
+	endpoint := *c.url
+	query := endpoint.Query()
+	query.Set("group", group)
+	query.Set("song", song)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return songDetail{}, fmt.Errorf("error building external API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	logrus.WithField("apiURL", endpoint.String()).Debug("Requesting data from external API")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return songDetail{}, fmt.Errorf("external API request cancelled: %w", ctx.Err())
+		}
+		return songDetail{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	logrus.WithField("status", resp.StatusCode).Debug("Received response from external API")
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return songDetail{}, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return songDetail{}, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	default:
+		return songDetail{}, fmt.Errorf("%w: status %d", ErrRejected, resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+			return songDetail{}, fmt.Errorf("%w: unexpected content type %q", ErrInvalidResponse, contentType)
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return songDetail{}, fmt.Errorf("external API request cancelled: %w", ctx.Err())
+		}
+		return songDetail{}, fmt.Errorf("%w: error reading response: %v", ErrUnavailable, err)
+	}
+	if len(body) > maxResponseSize {
+		return songDetail{}, fmt.Errorf("%w: response is larger than %d bytes", ErrInvalidResponse, maxResponseSize)
+	}
+
+	var detail songDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return songDetail{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return detail, nil
+}
+
+// Метод для вычисления задержки перед повтором после attempt-го запроса: экспоненциальная
+// задержка со случайной половиной, чтобы повторы клиентов не совпадали
+func (c *HTTPClient) retryDelay(attempt int) time.Duration {
+	delay := c.config.RetryBackoff
+	for i := 0; i < attempt && delay < c.config.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.config.MaxRetryBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
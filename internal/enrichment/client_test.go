@@ -0,0 +1,130 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Ответ внешнего API со всеми полями схемы
+const validDetail = `{"releaseDate":"16.07.2006","text":"Paranoia is in bloom","link":"https://www.youtube.com/watch?v=Xsp3_a-PMTw"}`
+
+// Структура fakeResponse - ответ тестового внешнего API
+type fakeResponse struct {
+	status      int
+	contentType string
+	body        string
+}
+
+// Функция для запуска тестового внешнего API, отвечающего responses по порядку (последним - на все
+// следующие запросы). Возвращает адрес и счётчик запросов.
+func newFakeAPI(t *testing.T, responses ...fakeResponse) (string, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1)) - 1
+		if r.URL.Query().Get("group") != "Muse" || r.URL.Query().Get("song") != "Uprising" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		response := responses[min(n, len(responses)-1)]
+		if response.contentType == "" {
+			response.contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", response.contentType)
+		w.WriteHeader(response.status)
+		_, _ = w.Write([]byte(response.body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/info", &requests
+}
+
+func TestHTTPClientFetchSong(t *testing.T) {
+	unavailable := fakeResponse{status: http.StatusServiceUnavailable}
+	ok := fakeResponse{status: http.StatusOK, body: validDetail}
+	tests := []struct {
+		name         string
+		responses    []fakeResponse
+		maxRetries   int
+		wantErr      error
+		wantRequests int32
+	}{
+		{name: "success", responses: []fakeResponse{ok}, wantRequests: 1},
+		{name: "retries until success", responses: []fakeResponse{unavailable, unavailable, ok}, maxRetries: 2, wantRequests: 3},
+		{name: "gives up after retries", responses: []fakeResponse{unavailable}, maxRetries: 1, wantErr: ErrUnavailable, wantRequests: 2},
+		{name: "too many requests is retried", responses: []fakeResponse{{status: http.StatusTooManyRequests}, ok}, maxRetries: 1, wantRequests: 2},
+		{name: "not found is not retried", responses: []fakeResponse{{status: http.StatusNotFound}}, maxRetries: 3, wantErr: ErrNotFound, wantRequests: 1},
+		{name: "rejected is not retried", responses: []fakeResponse{{status: http.StatusBadRequest}}, maxRetries: 3, wantErr: ErrRejected, wantRequests: 1},
+		{name: "missing fields", responses: []fakeResponse{{status: http.StatusOK, body: `{"text":"x"}`}}, maxRetries: 3, wantErr: ErrInvalidResponse, wantRequests: 1},
+		{name: "invalid date", responses: []fakeResponse{{status: http.StatusOK, body: `{"releaseDate":"31.02.2006","text":"x","link":"https://example.com"}`}}, wantErr: ErrInvalidResponse, wantRequests: 1},
+		{name: "not JSON", responses: []fakeResponse{{status: http.StatusOK, body: `<html>`}}, wantErr: ErrInvalidResponse, wantRequests: 1},
+		{name: "wrong content type", responses: []fakeResponse{{status: http.StatusOK, contentType: "text/html", body: validDetail}}, wantErr: ErrInvalidResponse, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, requests := newFakeAPI(t, tt.responses...)
+			client, err := NewHTTPClient(Config{URL: url, MaxRetries: tt.maxRetries})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			song, err := client.FetchSong(context.Background(), "Muse", "Uprising")
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUpstream) {
+				t.Errorf("error %v does not wrap ErrUpstream", err)
+			}
+			if err == nil && (song.Group != "Muse" || song.Song != "Uprising" || song.ReleaseDate != "16.07.2006" || song.Link == "") {
+				t.Errorf("song = %+v", song)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	url, requests := newFakeAPI(t, fakeResponse{status: http.StatusBadGateway})
+	client, err := NewHTTPClient(Config{URL: url, MaxRetries: 5, BreakerThreshold: 2, BreakerCooldown: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Предохранитель открывается на втором запросе: повторы прекращаются с ошибкой последнего запроса
+	_, err = client.FetchSong(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("first call error = %v, want ErrUnavailable", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+
+	_, err = client.FetchSong(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call error = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want no request while the breaker is open", got)
+	}
+}
+
+func TestNewHTTPClientURL(t *testing.T) {
+	for _, url := range []string{"", "external_api", "/info", "ftp://example.com/info", "http://"} {
+		if _, err := NewHTTPClient(Config{URL: url}); err == nil {
+			t.Errorf("NewHTTPClient(%q) error = nil, want an error", url)
+		}
+	}
+}
+
+func TestDisabledClient(t *testing.T) {
+	_, err := DisabledClient{}.FetchSong(context.Background(), "Muse", "Uprising")
+	if !errors.Is(err, ErrDisabled) || !errors.Is(err, ErrUpstream) {
+		t.Errorf("error = %v, want ErrDisabled", err)
+	}
+}
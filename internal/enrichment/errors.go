@@ -0,0 +1,46 @@
+package enrichment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Ошибки клиента внешнего API, по которым вызывающий код решает, повторять ли запрос позже
+var (
+	// ErrUpstream - общая ошибка внешнего API, в которую завёрнуты все остальные ошибки клиента
+	ErrUpstream = errors.New("external API")
+	// ErrNotFound - внешний API не знает песню (404); повтор не поможет
+	ErrNotFound = fmt.Errorf("%w: song not found", ErrUpstream)
+	// ErrRejected - внешний API отклонил запрос с другим статусом 4xx; повтор не поможет
+	ErrRejected = fmt.Errorf("%w: request rejected", ErrUpstream)
+	// ErrUnavailable - внешний API недоступен: ошибка сети, таймаут или статус 5xx; запрос можно повторить позже
+	ErrUnavailable = fmt.Errorf("%w: unavailable", ErrUpstream)
+	// ErrCircuitOpen - запрос не отправлен, потому что внешний API недавно был недоступен
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+	// ErrInvalidResponse - ответ внешнего API не соответствует схеме
+	ErrInvalidResponse = fmt.Errorf("%w: invalid response", ErrUpstream)
+	// ErrDisabled - адрес внешнего API не настроен, запросы не отправляются
+	ErrDisabled = fmt.Errorf("%w: url is not configured", ErrUpstream)
+)
+
+// Структура SchemaError - ErrInvalidResponse с описанием полей ответа, не прошедших проверку
+type SchemaError struct {
+	Fields []models.FieldError
+}
+
+// Метод для получения текстового описания всех ошибок схемы
+func (e *SchemaError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidResponse, strings.Join(messages, "; "))
+}
+
+// Метод, позволяющий сравнивать SchemaError с ErrInvalidResponse через errors.Is
+func (e *SchemaError) Unwrap() error {
+	return ErrInvalidResponse
+}
@@ -0,0 +1,60 @@
+package enrichment
+
+import (
+	"net/url"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Структура songDetail - тело ответа внешнего API; указатели отличают отсутствующие поля от пустых
+type songDetail struct {
+	ReleaseDate *string `json:"releaseDate"`
+	Text        *string `json:"text"`
+	Link        *string `json:"link"`
+}
+
+// Метод для проверки ответа по схеме внешнего API: все поля обязательны, дата выпуска в формате
+// DD.MM.YYYY или YYYY-MM-DD, ссылка - абсолютный адрес http или https
+func (d songDetail) validate() error {
+	var fields []models.FieldError
+	add := func(field, message string) {
+		fields = append(fields, models.FieldError{Field: field, Message: message})
+	}
+
+	switch {
+	case d.ReleaseDate == nil || *d.ReleaseDate == "":
+		add("releaseDate", "is required")
+	default:
+		if _, err := models.ParseReleaseDate(*d.ReleaseDate); err != nil {
+			add("releaseDate", "must be a date in DD.MM.YYYY or YYYY-MM-DD format")
+		}
+	}
+	if d.Text == nil || *d.Text == "" {
+		add("text", "is required")
+	}
+	switch {
+	case d.Link == nil || *d.Link == "":
+		add("link", "is required")
+	default:
+		link, err := url.Parse(*d.Link)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+			add("link", "must be an absolute http or https URL")
+		}
+	}
+
+	if len(fields) > 0 {
+		return &SchemaError{Fields: fields}
+	}
+	return nil
+}
+
+// Метод для преобразования проверенного ответа в модель песни группы
+func (d songDetail) toSong(group, song string) models.Songs {
+	return models.Songs{
+		Group:       group,
+		Song:        song,
+		ReleaseDate: *d.ReleaseDate,
+		Text:        *d.Text,
+		Link:        *d.Link,
+	}
+}
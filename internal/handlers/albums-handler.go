@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Ktuty/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+//	@Summary		Get all albums
+//	@Description	Get a page of albums with optional filtering by title and group
+//	@Tags			albums
+//	@Produce		json
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Param			title		query		string	false	"Album title substring"
+//	@Param			group		query		string	false	"Group name substring"
+//	@Success		200			{object}	models.AlbumsPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/albums [get]
+func (h *Handler) Albums(w http.ResponseWriter, r *http.Request) {
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	filter := models.AlbumFilter{
+		Title: r.URL.Query().Get("title"),
+		Group: r.URL.Query().Get("group"),
+	}
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+		"filter":   filter,
+	}).Info("Albums: parameters")
+
+	response, err := h.services.GetAllAlbums(r.Context(), filter, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении альбомов")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Create an album
+//	@Description	Create an album of a group; the group is found or created by name, as for songs
+//	@Tags			albums
+//	@Accept			json
+//	@Produce		json
+//	@Param			album	body		models.Album	true	"Album title, group, release date and cover link"
+//	@Success		201		{object}	models.Album
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/albums [post]
+func (h *Handler) NewAlbum(w http.ResponseWriter, r *http.Request) {
+	var album models.Album
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.services.CreateAlbum(r.Context(), album)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при создании альбома")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+//	@Summary		Get an album by ID
+//	@Description	Get an album with its tracks ordered by track number
+//	@Tags			albums
+//	@Produce		json
+//	@Param			id	path		int	true	"Album ID"
+//	@Success		200	{object}	models.Album
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/albums/{id} [get]
+func (h *Handler) AlbumByID(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := albumIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	album, err := h.services.GetAlbumByID(r.Context(), albumID)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении альбома")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, album)
+}
+
+//	@Summary		Update an album
+//	@Description	Update the title, release date or cover link of an album; empty fields are left unchanged
+//	@Tags			albums
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Album ID"
+//	@Param			album	body		models.Album	true	"Album fields to update"
+//	@Success		200		{object}	models.Album
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/albums/{id} [patch]
+func (h *Handler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := albumIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var album models.Album
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.services.UpdateAlbum(r.Context(), albumID, album)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при обновлении альбома")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+//	@Summary		Delete an album
+//	@Description	Delete an album; its songs are kept without an album
+//	@Tags			albums
+//	@Produce		json
+//	@Param			id	path	int	true	"Album ID"
+//	@Success		200
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/albums/{id} [delete]
+func (h *Handler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := albumIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.DeleteAlbum(r.Context(), albumID); err != nil {
+		logrus.WithError(err).Error("Ошибка при удалении альбома")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Summary		Set album tracks
+//	@Description	Replace the track list of an album. Songs must belong to the album's group; songs removed from the list lose their album.
+//	@Tags			albums
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Album ID"
+//	@Param			tracks	body		models.AlbumTracksRequest	true	"Track numbers and song IDs"
+//	@Success		200		{object}	models.Album
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/albums/{id}/tracks [put]
+func (h *Handler) SetAlbumTracks(w http.ResponseWriter, r *http.Request) {
+	albumID, ok := albumIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.AlbumTracksRequest
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	album, err := h.services.SetAlbumTracks(r.Context(), albumID, request)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при изменении треков альбома")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, album)
+}
+
+// Функция для получения ID альбома из переменных маршрута; при ошибке ответ уже записан
+func albumIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	albumID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании albumID в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	logrus.WithField("albumID", albumID).Info("Album request: albumID")
+	return albumID, true
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestAlbums(t *testing.T) {
+	router := newTestRouter(t)
+	uprising := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	resistance := createSong(t, router, `{"group":"Muse","song":"Resistance"}`)
+	other := createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody"}`)
+
+	rec := serve(t, router, http.MethodPost, "/albums", "application/json", `{"title":"The Resistance","group":"muse","releaseDate":"14.09.2009"}`)
+	expectStatus(t, rec, http.StatusCreated)
+	album := decode[models.Album](t, rec)
+	if album.Group != "Muse" || album.GroupID != uprising.Artists[0].GroupID {
+		t.Errorf("album = %+v, want an album of Muse", album)
+	}
+	path := "/albums/" + strconv.Itoa(album.ID)
+	expectStatus(t, serve(t, router, http.MethodPost, "/albums", "application/json", `{"group":"Muse"}`), http.StatusUnprocessableEntity)
+
+	tracks := `{"tracks":[{"trackNumber":1,"songId":` + strconv.Itoa(uprising.ID) + `},{"trackNumber":2,"songId":` + strconv.Itoa(resistance.ID) + `}]}`
+	rec = serve(t, router, http.MethodPut, path+"/tracks", "application/json", tracks)
+	expectStatus(t, rec, http.StatusOK)
+	if album = decode[models.Album](t, rec); len(album.Tracks) != 2 || album.Tracks[1].Song != "Resistance" {
+		t.Errorf("tracks = %+v", album.Tracks)
+	}
+	song := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(resistance.ID), "", ""))
+	if song.Album == nil || song.Album.ID != album.ID || song.Album.TrackNumber != 2 {
+		t.Errorf("song album = %+v, want track 2 of album %d", song.Album, album.ID)
+	}
+	page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?album=resistance", "", ""))
+	if songTitles(page.Songs) != "Uprising,Resistance" {
+		t.Errorf("songs of the album = %s", songTitles(page.Songs))
+	}
+
+	// Песня другой группы и повтор номера трека отклоняются
+	rec = serve(t, router, http.MethodPut, path+"/tracks", "application/json", `{"tracks":[{"trackNumber":1,"songId":`+strconv.Itoa(other.ID)+`}]}`)
+	expectStatus(t, rec, http.StatusConflict)
+	rec = serve(t, router, http.MethodPut, path+"/tracks", "application/json", `{"tracks":[{"trackNumber":1,"songId":1},{"trackNumber":1,"songId":2}]}`)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+
+	rec = serve(t, router, http.MethodPatch, path, "application/json", `{"coverLink":"https://example.com/cover.jpg"}`)
+	expectStatus(t, rec, http.StatusOK)
+	albums := decode[models.AlbumsPage](t, serve(t, router, http.MethodGet, "/albums?title=resist", "", ""))
+	if len(albums.Albums) != 1 || albums.Albums[0].CoverLink != "https://example.com/cover.jpg" {
+		t.Errorf("albums = %+v", albums.Albums)
+	}
+
+	// Удаление альбома оставляет песни без альбома
+	expectStatus(t, serve(t, router, http.MethodDelete, path, "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodGet, path, "", ""), http.StatusNotFound)
+	if song = decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(resistance.ID), "", "")); song.Album != nil {
+		t.Errorf("song album after delete = %+v", song.Album)
+	}
+}
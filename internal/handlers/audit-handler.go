@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//	@Summary		Get the audit log
+//	@Description	Get a page of the audit log of mutating API calls, newest first. Every entry stores the actor, action, entity state before and after the change, request id and client IP.
+//	@Tags			audit
+//	@Produce		json
+//	@Param			actor		query		string	false	"Author of the change (X-Author header)"
+//	@Param			action		query		string	false	"Action"	Enums(create, update, delete, restore, revert, merge, enrich)
+//	@Param			entity		query		string	false	"Entity type"	Enums(song, group, album)
+//	@Param			entityId	query		int		false	"Entity ID"
+//	@Param			requestId	query		string	false	"Request ID (X-Request-ID header)"
+//	@Param			from		query		string	false	"Entries created at or after (RFC 3339)"
+//	@Param			to			query		string	false	"Entries created before (RFC 3339)"
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Success		200			{object}	models.AuditPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/audit [get]
+func (h *Handler) Audit(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilterFromQuery(r)
+	if err != nil {
+		logrus.WithError(err).Error("Некорректные параметры фильтра журнала аудита")
+		writeServiceError(w, err)
+		return
+	}
+
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	logrus.WithFields(logrus.Fields{
+		"filter":   filter,
+		"page":     page,
+		"pageSize": pageSize,
+	}).Info("Audit: parameters")
+
+	response, err := h.services.GetAudit(r.Context(), filter, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении журнала аудита")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Функция для построения фильтра журнала аудита из параметров запроса
+func auditFilterFromQuery(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		Entity:    query.Get("entity"),
+		RequestID: query.Get("requestId"),
+	}
+
+	var fields []models.FieldError
+	if value := query.Get("entityId"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			fields = append(fields, models.FieldError{Field: "entityId", Message: "must be an integer"})
+		}
+		filter.EntityID = id
+	}
+	for _, bound := range []struct {
+		param  string
+		target *time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		value := query.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		moment, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			fields = append(fields, models.FieldError{Field: bound.param, Message: "must be a time in RFC 3339 format"})
+			continue
+		}
+		*bound.target = moment
+	}
+	if len(fields) > 0 {
+		return filter, &services.ValidationError{Fields: fields}
+	}
+
+	return filter, nil
+}
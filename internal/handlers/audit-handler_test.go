@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestAuditLog(t *testing.T) {
+	router := newTestRouter(t)
+	rec := serve(t, router, http.MethodPost, "/songs", "application/json", `{"group":"Muse","song":"Uprising"}`, "X-Author", "alice", "X-Request-ID", "req-1")
+	expectStatus(t, rec, http.StatusCreated)
+	song := decode[models.Songs](t, rec)
+	expectStatus(t, serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"Paranoia is in bloom"}`, "X-Author", "bob"), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(song.ID), "", "", "X-Author", "bob"), http.StatusOK)
+
+	// Отклонённый запрос не попадает в журнал
+	expectStatus(t, serve(t, router, http.MethodPost, "/songs", "application/json", `{}`, "X-Author", "alice"), http.StatusUnprocessableEntity)
+
+	page := decode[models.AuditPage](t, serve(t, router, http.MethodGet, "/audit?entity=song&entityId="+strconv.Itoa(song.ID), "", ""))
+	var actions []string
+	for _, entry := range page.Entries {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 3 || actions[0] != models.AuditActionDelete || actions[2] != models.AuditActionCreate {
+		t.Fatalf("actions = %v, want delete, update, create", actions)
+	}
+
+	created := page.Entries[2]
+	if created.Actor != "alice" || created.RequestID != "req-1" || created.ClientIP == "" || len(created.Before) != 0 {
+		t.Errorf("create entry = %+v", created)
+	}
+	var after models.Songs
+	if err := json.Unmarshal(created.After, &after); err != nil || after.Song != "Uprising" {
+		t.Errorf("create entry after = %s (%v)", created.After, err)
+	}
+	if deleted := page.Entries[0]; len(deleted.Before) == 0 || len(deleted.After) != 0 {
+		t.Errorf("delete entry = %+v, want only before", deleted)
+	}
+
+	page = decode[models.AuditPage](t, serve(t, router, http.MethodGet, "/audit?actor=bob&action=update", "", ""))
+	if len(page.Entries) != 1 || page.Entries[0].Action != models.AuditActionUpdate {
+		t.Errorf("entries of bob = %+v, want one update", page.Entries)
+	}
+	page = decode[models.AuditPage](t, serve(t, router, http.MethodGet, "/audit?requestId=req-1", "", ""))
+	if len(page.Entries) != 1 {
+		t.Errorf("entries of req-1 = %+v, want one", page.Entries)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, "/audit?from=yesterday", "", ""), http.StatusUnprocessableEntity)
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Количество песен, после записи которых выгрузка отправляет ответ клиенту и продлевает срок записи;
+// совпадает с порцией, которую репозиторий читает из курсора
+const exportFlushSize = 500
+
+// Срок записи порции выгрузки: клиент, который перестал читать ответ, удерживает транзакцию
+// и курсор выгрузки не дольше этого срока
+const exportWriteTimeout = 30 * time.Second
+
+// Типы содержимого выгрузки каталога песен по форматам
+var exportContentTypes = map[string]string{
+	models.ExportFormatCSV:    "text/csv; charset=utf-8",
+	models.ExportFormatNDJSON: ndjsonType,
+	models.ExportFormatJSON:   "application/json",
+}
+
+//	@Summary		Export the song catalog
+//	@Description	Download every song matching the filters of GET /songs, without pagination, as CSV, JSON Lines or a JSON array. Rows are streamed from the database as they are read, so large libraries are exported with constant memory. Instead of the server write timeout, every batch of 500 songs must be written within 30 seconds, after which the response is flushed to the client.
+//	@Description	CSV has a header with the columns id, group, song, text, releaseDate and link and can be imported back through POST /songs/import. JSON Lines and JSON contain full song objects as in GET /songs.
+//	@Description	The response is sent as an attachment and is gzip-compressed when the client sends Accept-Encoding: gzip. An error after the download has started truncates the response.
+//	@Tags			songs
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		json
+//	@Param			format			query		string	false	"Export format"	Enums(csv, ndjson, json)	default(json)
+//	@Param			song			query		string	false	"Song name"
+//	@Param			group			query		string	false	"Group name, matches any credited artist (primary, featured, composer, lyricist)"
+//	@Param			text			query		string	false	"Song text"
+//	@Param			releaseDate		query		string	false	"Release date"
+//	@Param			releaseDateFrom	query		string	false	"Released on or after (DD.MM.YYYY or YYYY-MM-DD)"
+//	@Param			releaseDateTo	query		string	false	"Released on or before (DD.MM.YYYY or YYYY-MM-DD)"
+//	@Param			link			query		string	false	"Link"
+//	@Param			album			query		string	false	"Album title"
+//	@Param			genre			query		string	false	"Comma-separated genre names"
+//	@Param			genreMode		query		string	false	"Match songs with any or all of the genres"	Enums(any, all)	default(any)
+//	@Param			tag				query		string	false	"Comma-separated tag names"
+//	@Param			tagMode			query		string	false	"Match songs with any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			fuzzy			query		bool	false	"Match song and group by trigram similarity"	default(false)
+//	@Param			sort			query		string	false	"Comma-separated sort keys (id, song, group, releaseDate, link), prefix with - for descending"	default(id)
+//	@Param			Accept-Encoding	header		string	false	"gzip to compress the response"
+//	@Success		200				{array}		models.Songs
+//	@Failure		400				{object}	models.ErrorResponse
+//	@Failure		422				{object}	models.ErrorResponse
+//	@Failure		500				{object}	models.ErrorResponse
+//	@Router			/songs/export [get]
+func (h *Handler) ExportSongs(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.ExportFormatJSON
+	}
+	if err := services.ValidateExportFormat(format); err != nil {
+		logrus.WithError(err).Error("Некорректный формат выгрузки")
+		writeServiceError(w, err)
+		return
+	}
+
+	filter, err := songFilterFromQuery(r)
+	if err != nil {
+		logrus.WithError(err).Error("Некорректные параметры фильтра")
+		writeServiceError(w, err)
+		return
+	}
+	sort, err := services.ParseSongSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		logrus.WithError(err).Error("Некорректный параметр сортировки")
+		writeServiceError(w, err)
+		return
+	}
+	compress := acceptsGzip(r)
+	rc := http.NewResponseController(w)
+	deadlines := startExportDeadlines(rc)
+	logrus.WithFields(logrus.Fields{
+		"format": format,
+		"filter": filter,
+		"sort":   sort,
+		"gzip":   compress,
+	}).Info("ExportSongs: parameters")
+
+	// Заголовки отправляются с первой песней или после пустой выгрузки, поэтому ошибка
+	// до начала выгрузки возвращается обычным ответом об ошибке
+	var encoder songEncoder
+	var gz *gzip.Writer
+	start := func() error {
+		if encoder != nil {
+			return nil
+		}
+		w.Header().Set("Content-Type", exportContentTypes[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="songs-%s.%s"`, time.Now().UTC().Format("20060102-150405"), format))
+		w.Header().Add("Vary", "Accept-Encoding")
+		var out io.Writer = w
+		if compress {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz = gzip.NewWriter(w)
+			out = gz
+		}
+		w.WriteHeader(http.StatusOK)
+
+		encoder = newSongEncoder(format, out)
+		return encoder.begin()
+	}
+
+	// Порция отправляется клиенту, после чего срок записи ответа продлевается на следующую порцию
+	flush := func() error {
+		if err := encoder.flush(); err != nil {
+			return err
+		}
+		if gz != nil {
+			if err := gz.Flush(); err != nil {
+				return err
+			}
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if !deadlines {
+			return nil
+		}
+		return rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	}
+
+	count := 0
+	err = h.services.Export(r.Context(), filter, sort, func(song models.Songs) error {
+		if err := start(); err != nil {
+			return err
+		}
+		count++
+		if err := encoder.encode(song); err != nil {
+			return err
+		}
+		if count%exportFlushSize == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = start()
+	}
+	if err == nil {
+		err = encoder.end()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		if encoder == nil {
+			logrus.WithError(err).Error("Ошибка при выгрузке песен")
+			writeServiceError(w, err)
+			return
+		}
+		// Ответ уже начат: выгрузка обрывается, и клиент получает неполное тело
+		logrus.WithError(err).WithField("songs", count).Error("Выгрузка песен прервана")
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"format": format,
+		"songs":  count,
+	}).Info("ExportSongs: exported")
+}
+
+// Функция для замены ограничений времени сервера для выгрузки: ограничение времени чтения запроса
+// снимается, так как по его истечении сервер отменяет контекст запроса, а ограничение времени записи
+// ответа заменяется сроком записи первой порции. Возвращает false, если соединение не поддерживает
+// изменение сроков; тогда выгрузка выполняется с ограничениями сервера.
+func startExportDeadlines(rc *http.ResponseController) bool {
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		logrus.WithError(err).Warn("Error clearing read deadline")
+		return false
+	}
+	if err := rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		logrus.WithError(err).Warn("Error setting write deadline")
+		return false
+	}
+	return true
+}
+
+// Функция для проверки, принимает ли клиент ответ, сжатый gzip (Accept-Encoding с ненулевым q)
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if name = strings.TrimSpace(name); name != "gzip" {
+				continue
+			}
+			q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !ok {
+				return true
+			}
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+	}
+	return false
+}
+
+// Интерфейс songEncoder, записывающий выгружаемые песни в формате выгрузки
+type songEncoder interface {
+	// Метод для записи начала выгрузки
+	begin() error
+	// Метод для записи очередной песни
+	encode(song models.Songs) error
+	// Метод для сброса буферов без записи конца выгрузки
+	flush() error
+	// Метод для записи конца выгрузки и сброса буферов
+	end() error
+}
+
+// Функция для создания songEncoder для проверенного формата выгрузки
+func newSongEncoder(format string, w io.Writer) songEncoder {
+	switch format {
+	case models.ExportFormatCSV:
+		return &csvSongEncoder{writer: csv.NewWriter(w)}
+	case models.ExportFormatNDJSON:
+		return &ndjsonSongEncoder{encoder: json.NewEncoder(w)}
+	default:
+		return &jsonSongEncoder{w: w}
+	}
+}
+
+// Столбцы CSV-выгрузки; совпадают со столбцами CSV-импорта
+var csvExportColumns = []string{"id", "group", "song", "text", "releaseDate", "link"}
+
+// Структура csvSongEncoder, записывающая песни строками CSV с заголовком
+type csvSongEncoder struct {
+	writer *csv.Writer
+}
+
+// Метод для записи заголовка CSV
+func (c *csvSongEncoder) begin() error {
+	return c.writer.Write(csvExportColumns)
+}
+
+// Метод для записи песни строкой CSV
+func (c *csvSongEncoder) encode(song models.Songs) error {
+	return c.writer.Write([]string{strconv.Itoa(song.ID), song.Group, song.Song, song.Text, song.ReleaseDate, song.Link})
+}
+
+// Метод для сброса буфера CSV
+func (c *csvSongEncoder) flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// Метод для записи конца выгрузки; у CSV это сброс буфера
+func (c *csvSongEncoder) end() error {
+	return c.flush()
+}
+
+// Структура ndjsonSongEncoder, записывающая по одной песне в строке JSON Lines
+type ndjsonSongEncoder struct {
+	encoder *json.Encoder
+}
+
+// Метод для записи начала выгрузки; у JSON Lines его нет
+func (n *ndjsonSongEncoder) begin() error {
+	return nil
+}
+
+// Метод для записи песни отдельной строкой
+func (n *ndjsonSongEncoder) encode(song models.Songs) error {
+	return n.encoder.Encode(song)
+}
+
+// Метод для сброса буферов; JSON Lines пишется без буфера
+func (n *ndjsonSongEncoder) flush() error {
+	return nil
+}
+
+// Метод для записи конца выгрузки; у JSON Lines его нет
+func (n *ndjsonSongEncoder) end() error {
+	return nil
+}
+
+// Структура jsonSongEncoder, записывающая песни элементами одного JSON-массива
+type jsonSongEncoder struct {
+	w     io.Writer
+	count int
+}
+
+// Метод для записи начала массива
+func (j *jsonSongEncoder) begin() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+// Метод для записи песни элементом массива
+func (j *jsonSongEncoder) encode(song models.Songs) error {
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		data = append([]byte(","), data...)
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+// Метод для сброса буферов; массив пишется без буфера
+func (j *jsonSongEncoder) flush() error {
+	return nil
+}
+
+// Метод для записи конца массива
+func (j *jsonSongEncoder) end() error {
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestExportSongs(t *testing.T) {
+	router := newTestRouter(t)
+	createSong(t, router, `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom,\n\"PR\" transmissions","releaseDate":"2006-07-16"}`)
+	createSong(t, router, `{"group":"Muse","song":"Madness"}`)
+	createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody"}`)
+
+	rec := serve(t, router, http.MethodGet, "/songs/export?format=csv&group=muse&sort=-id", "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="songs-`) || !strings.HasSuffix(got, `.csv"`) {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != "id,group,song,text,releaseDate,link" || records[1][2] != "Madness" ||
+		records[2][3] != "Paranoia is in bloom,\n\"PR\" transmissions" || records[2][4] != "16.07.2006" {
+		t.Errorf("CSV records = %q", records)
+	}
+
+	rec = serve(t, router, http.MethodGet, "/songs/export?format=ndjson", "", "")
+	expectStatus(t, rec, http.StatusOK)
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("NDJSON lines = %q", lines)
+	}
+	var song models.Songs
+	if err := json.Unmarshal([]byte(lines[2]), &song); err != nil || song.Song != "Bohemian Rhapsody" {
+		t.Errorf("last NDJSON line = %s (%v)", lines[2], err)
+	}
+
+	rec = serve(t, router, http.MethodGet, "/songs/export?song=uprising", "", "", "Accept-Encoding", "gzip")
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var songs []models.Songs
+	if err := json.Unmarshal(body, &songs); err != nil || songTitles(songs) != "Uprising" {
+		t.Errorf("JSON export = %s (%v)", body, err)
+	}
+
+	// Пустая выгрузка - корректный пустой документ
+	rec = serve(t, router, http.MethodGet, "/songs/export?group=abba", "", "", "Accept-Encoding", "gzip;q=0")
+	expectStatus(t, rec, http.StatusOK)
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Errorf("empty JSON export = %q", got)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs/export?format=xml", "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestExportSongsServer(t *testing.T) {
+	// Настоящее соединение поддерживает сроки записи, которые выгрузка продлевает после каждой порции
+	server := httptest.NewUnstartedServer(newTestRouter(t))
+	server.Config.ReadTimeout, server.Config.WriteTimeout = 10*time.Second, 10*time.Second
+	server.Start()
+	defer server.Close()
+
+	var catalog strings.Builder
+	for i := 1; i <= 2*exportFlushSize+1; i++ {
+		fmt.Fprintf(&catalog, `{"group":"Muse","song":"Song %d"}`+"\n", i)
+	}
+	resp, err := http.Post(server.URL+"/songs/import", ndjsonType, strings.NewReader(catalog.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("import status = %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/songs/export?format=csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2*exportFlushSize+2 || records[len(records)-1][2] != fmt.Sprintf("Song %d", 2*exportFlushSize+1) {
+		t.Errorf("exported %d CSV records, want %d", len(records), 2*exportFlushSize+2)
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+//	@Summary		Get all groups
+//	@Description	Get a page of groups with optional filtering by name
+//	@Tags			groups
+//	@Produce		json
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Param			name		query		string	false	"Group name substring"
+//	@Success		200			{object}	models.GroupsPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/groups [get]
+func (h *Handler) Groups(w http.ResponseWriter, r *http.Request) {
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	name := r.URL.Query().Get("name")
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+		"name":     name,
+	}).Info("Groups: parameters")
+
+	response, err := h.services.GetAllGroups(r.Context(), name, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении групп")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Create a group
+//	@Description	Create a group. Names are unique ignoring case, extra whitespace and diacritics.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Param			group	body		models.Group	true	"Group name"
+//	@Success		201		{object}	models.Group
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/groups [post]
+func (h *Handler) NewGroup(w http.ResponseWriter, r *http.Request) {
+	var group models.Group
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.services.CreateGroup(r.Context(), group)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при создании группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+//	@Summary		Get a group by ID
+//	@Description	Get a group and the number of its songs
+//	@Tags			groups
+//	@Produce		json
+//	@Param			id	path		int	true	"Group ID"
+//	@Success		200	{object}	models.Group
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/groups/{id} [get]
+func (h *Handler) GroupByID(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	group, err := h.services.GetGroupByID(r.Context(), groupID)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, group)
+}
+
+//	@Summary		Rename a group
+//	@Description	Rename a group; all songs of the group get the new name
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Group ID"
+//	@Param			group	body		models.Group	true	"New group name"
+//	@Success		200		{object}	models.Group
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/groups/{id} [patch]
+func (h *Handler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	renamed, err := h.services.RenameGroup(r.Context(), groupID, group)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при переименовании группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renamed)
+}
+
+//	@Summary		Delete a group
+//	@Description	Delete a group. A group with songs is deleted only with cascade=true, together with its songs.
+//	@Tags			groups
+//	@Produce		json
+//	@Param			id		path	int		true	"Group ID"
+//	@Param			cascade	query	bool	false	"Also delete the songs of the group"	default(false)
+//	@Success		200
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		409	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/groups/{id} [delete]
+func (h *Handler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	cascade := getQueryParamAsBool(r, "cascade", false)
+	if err := h.services.DeleteGroup(r.Context(), groupID, cascade); err != nil {
+		logrus.WithError(err).Error("Ошибка при удалении группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Summary		Get songs of a group
+//	@Description	Get a page of songs of the group
+//	@Tags			groups
+//	@Produce		json
+//	@Param			id			path		int		true	"Group ID"
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Param			sort		query		string	false	"Comma-separated sort keys (id, song, group, releaseDate, link), prefix with - for descending"	default(id)
+//	@Success		200			{object}	models.SongsPage
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		404			{object}	models.ErrorResponse
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/groups/{id}/songs [get]
+func (h *Handler) GroupSongs(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+
+	// Разбор параметра сортировки
+	sort, err := services.ParseSongSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		logrus.WithError(err).Error("Некорректный параметр сортировки")
+		writeServiceError(w, err)
+		return
+	}
+
+	response, err := h.services.GetGroupSongs(r.Context(), groupID, sort, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении песен группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Add a group alias
+//	@Description	Add an alternative spelling of the group name. New songs with this spelling are attached to the group.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Group ID"
+//	@Param			alias	body		models.GroupAlias	true	"Alias"
+//	@Success		201		{object}	models.Group
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/groups/{id}/aliases [post]
+func (h *Handler) NewGroupAlias(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var alias models.GroupAlias
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	group, err := h.services.AddGroupAlias(r.Context(), groupID, alias)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при добавлении псевдонима группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, group)
+}
+
+//	@Summary		Delete a group alias
+//	@Description	Delete an alias of the group by its ID
+//	@Tags			groups
+//	@Produce		json
+//	@Param			id		path	int	true	"Group ID"
+//	@Param			aliasId	path	int	true	"Alias ID"
+//	@Success		200
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/groups/{id}/aliases/{aliasId} [delete]
+func (h *Handler) DeleteGroupAlias(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	aliasID, err := strconv.Atoi(mux.Vars(r)["aliasId"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании aliasID в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.DeleteGroupAlias(r.Context(), groupID, aliasID); err != nil {
+		logrus.WithError(err).Error("Ошибка при удалении псевдонима группы")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Summary		Merge groups
+//	@Description	Move all songs and aliases of the source groups into the target group, record the source names as aliases and delete the source groups
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Target group ID"
+//	@Param			merge	body		models.GroupMergeRequest	true	"Source group IDs"
+//	@Success		200		{object}	models.GroupMerge
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		409		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/groups/{id}/merge [post]
+func (h *Handler) MergeGroups(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.GroupMergeRequest
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	merge, err := h.services.MergeGroups(r.Context(), groupID, request)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при объединении групп")
+		writeServiceError(w, err)
+		return
+	}
+	logrus.WithField("response", merge).Info("MergeGroups: response")
+
+	writeJSON(w, http.StatusOK, merge)
+}
+
+// Функция для получения ID группы из переменных маршрута; при ошибке ответ уже записан
+func groupIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	groupID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании groupID в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	logrus.WithField("groupID", groupID).Info("Group request: groupID")
+	return groupID, true
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Функция для получения пути группы
+func groupPath(id int, suffix ...string) string {
+	path := "/groups/" + strconv.Itoa(id)
+	for _, part := range suffix {
+		path += part
+	}
+	return path
+}
+
+func TestGroups(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := serve(t, router, http.MethodPost, "/groups", "application/json", `{"name":"  Beyoncé  Knowles "}`)
+	expectStatus(t, rec, http.StatusCreated)
+	group := decode[models.Group](t, rec)
+	if group.Name != "Beyoncé Knowles" {
+		t.Errorf("name = %q, want normalized name", group.Name)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, "/groups", "application/json", `{"name":"beyonce knowles"}`), http.StatusConflict)
+
+	// Песня с другим написанием группы попадает в существующую группу
+	song := createSong(t, router, `{"group":"BEYONCE KNOWLES","song":"Halo"}`)
+	if song.Group != "Beyoncé Knowles" {
+		t.Errorf("song group = %q, want %q", song.Group, group.Name)
+	}
+	group = decode[models.Group](t, serve(t, router, http.MethodGet, groupPath(group.ID), "", ""))
+	if group.SongCount != 1 {
+		t.Errorf("songCount = %d, want 1", group.SongCount)
+	}
+	page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, groupPath(group.ID, "/songs"), "", ""))
+	if songTitles(page.Songs) != "Halo" {
+		t.Errorf("group songs = %s, want Halo", songTitles(page.Songs))
+	}
+
+	rec = serve(t, router, http.MethodPatch, groupPath(group.ID), "application/json", `{"name":"Beyoncé"}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(song.ID), "", "")).Group; got != "Beyoncé" {
+		t.Errorf("song group after rename = %q", got)
+	}
+
+	// Группу с песнями можно удалить только вместе с песнями
+	expectStatus(t, serve(t, router, http.MethodDelete, groupPath(group.ID), "", ""), http.StatusConflict)
+	expectStatus(t, serve(t, router, http.MethodDelete, groupPath(group.ID, "?cascade=true"), "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodGet, groupPath(group.ID), "", ""), http.StatusNotFound)
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID), "", ""), http.StatusNotFound)
+
+	groups := decode[models.GroupsPage](t, serve(t, router, http.MethodGet, "/groups", "", ""))
+	if len(groups.Groups) != 0 {
+		t.Errorf("groups = %+v, want none", groups.Groups)
+	}
+}
+
+func TestGroupAliasesAndMerge(t *testing.T) {
+	router := newTestRouter(t)
+	target := createSong(t, router, `{"group":"The Beatles","song":"Yesterday"}`)
+	source := createSong(t, router, `{"group":"Beatles","song":"Help!"}`)
+	duplicate := createSong(t, router, `{"group":"Beatles","song":"yesterday"}`)
+	targetID, sourceID := target.Artists[0].GroupID, source.Artists[0].GroupID
+
+	rec := serve(t, router, http.MethodPost, groupPath(targetID, "/aliases"), "application/json", `{"alias":"Битлз"}`)
+	expectStatus(t, rec, http.StatusCreated)
+	group := decode[models.Group](t, rec)
+	if len(group.Aliases) != 1 || group.Aliases[0].Alias != "Битлз" {
+		t.Fatalf("aliases = %+v", group.Aliases)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, groupPath(sourceID, "/aliases"), "application/json", `{"alias":"битлз"}`), http.StatusConflict)
+
+	// Песня с псевдонимом группы попадает в группу псевдонима
+	if song := createSong(t, router, `{"group":"битлз","song":"Let It Be"}`); song.Group != "The Beatles" {
+		t.Errorf("song group by alias = %q, want The Beatles", song.Group)
+	}
+
+	// Слияние с повтором названия песни отклоняется
+	rec = serve(t, router, http.MethodPost, groupPath(targetID, "/merge"), "application/json", `{"sources":[`+strconv.Itoa(sourceID)+`]}`)
+	expectStatus(t, rec, http.StatusConflict)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(duplicate.ID), "", ""), http.StatusOK)
+
+	rec = serve(t, router, http.MethodPost, groupPath(targetID, "/merge"), "application/json", `{"sources":[`+strconv.Itoa(sourceID)+`]}`)
+	expectStatus(t, rec, http.StatusOK)
+	merge := decode[models.GroupMerge](t, rec)
+	if merge.MovedSongs != 2 || merge.Group.SongCount != 3 {
+		t.Errorf("merge = %+v, want 2 moved songs and 3 songs in the group", merge)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, groupPath(sourceID), "", ""), http.StatusNotFound)
+	if got := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(source.ID), "", "")).Group; got != "The Beatles" {
+		t.Errorf("moved song group = %q, want The Beatles", got)
+	}
+
+	// Имя поглощённой группы становится псевдонимом
+	if song := createSong(t, router, `{"group":"beatles","song":"Something"}`); song.Group != "The Beatles" {
+		t.Errorf("song group by merged name = %q, want The Beatles", song.Group)
+	}
+
+	group = decode[models.Group](t, serve(t, router, http.MethodGet, groupPath(targetID), "", ""))
+	for _, alias := range group.Aliases {
+		expectStatus(t, serve(t, router, http.MethodDelete, groupPath(targetID, "/aliases/", strconv.Itoa(alias.ID)), "", ""), http.StatusOK)
+	}
+	if group = decode[models.Group](t, serve(t, router, http.MethodGet, groupPath(targetID), "", "")); len(group.Aliases) != 0 {
+		t.Errorf("aliases after delete = %+v", group.Aliases)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, groupPath(targetID, "/merge"), "application/json", `{"sources":[`+strconv.Itoa(targetID)+`]}`), http.StatusUnprocessableEntity)
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/Ktuty/internal/services"
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"net"
 	"net/http"
 )
 
@@ -11,29 +14,107 @@ import (
 type Handler struct {
 	services *services.Service
 	router   *mux.Router
-	url      string
 }
 
 // Функция для создания нового обработчика с заданными сервисами
-func NewHandler(services *services.Service, external_api string) *Handler {
-	return &Handler{services: services, url: external_api}
+func NewHandler(services *services.Service) *Handler {
+	return &Handler{services: services}
 }
 
 // Функция для инициализации маршрутов
 func (h *Handler) InitRouts() *mux.Router {
 	h.router = mux.NewRouter()
+	h.router.Use(requestMiddleware)
 	h.endpoints()
 
 	return h.router
 }
 
+// Заголовки запроса с автором изменений и идентификатором запроса, записываемыми в ревизии и журнал аудита
+const (
+	authorHeader    = "X-Author"
+	requestIDHeader = "X-Request-ID"
+)
+
+// Функция-посредник для передачи автора, идентификатора запроса и адреса клиента в контекст запроса.
+// Если клиент не передал X-Request-ID, идентификатор генерируется; он возвращается в ответе.
+func requestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			clientIP = r.RemoteAddr
+		}
+
+		ctx := services.WithRequestInfo(r.Context(), services.RequestInfo{
+			Author:    r.Header.Get(authorHeader),
+			RequestID: requestID,
+			ClientIP:  clientIP,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Функция для генерации случайного идентификатора запроса
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
 // Функция для настройки конечных точек маршрутизатора
 func (h *Handler) endpoints() {
 	h.router.HandleFunc("/songs", h.Songs).Methods(http.MethodGet)
 	h.router.HandleFunc("/songs", h.NewSong).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/search", h.SearchSongs).Methods(http.MethodGet)
+	h.router.HandleFunc("/songs/duplicates", h.SongDuplicates).Methods(http.MethodGet)
+	h.router.HandleFunc("/songs/import", h.ImportSongs).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/export", h.ExportSongs).Methods(http.MethodGet)
 	h.router.HandleFunc("/songs/{id}", h.SongByID).Methods(http.MethodGet)
 	h.router.HandleFunc("/songs/{id}", h.UpdateSong).Methods(http.MethodPatch)
+	h.router.HandleFunc("/songs/{id}", h.ReplaceSong).Methods(http.MethodPut)
 	h.router.HandleFunc("/songs/{id}", h.DeleteSongs).Methods(http.MethodDelete)
+	h.router.HandleFunc("/songs/{id}/restore", h.RestoreSong).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/{id}/enrich", h.EnrichSong).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/{id}/revisions", h.SongRevisions).Methods(http.MethodGet)
+	h.router.HandleFunc("/songs/{id}/revisions/diff", h.SongRevisionsDiff).Methods(http.MethodGet)
+	h.router.HandleFunc("/songs/{id}/revisions/{rev}", h.SongRevision).Methods(http.MethodGet)
+	h.router.HandleFunc("/songs/{id}/revisions/{rev}/revert", h.RevertSongRevision).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/{id}/genres", h.NewSongGenres).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/{id}/genres/{genre}", h.DeleteSongGenre).Methods(http.MethodDelete)
+	h.router.HandleFunc("/songs/{id}/tags", h.NewSongTags).Methods(http.MethodPost)
+	h.router.HandleFunc("/songs/{id}/tags/{tag}", h.DeleteSongTag).Methods(http.MethodDelete)
+
+	h.router.HandleFunc("/trash", h.Trash).Methods(http.MethodGet)
+
+	h.router.HandleFunc("/genres", h.Genres).Methods(http.MethodGet)
+	h.router.HandleFunc("/tags", h.Tags).Methods(http.MethodGet)
+
+	h.router.HandleFunc("/groups", h.Groups).Methods(http.MethodGet)
+	h.router.HandleFunc("/groups", h.NewGroup).Methods(http.MethodPost)
+	h.router.HandleFunc("/groups/{id}", h.GroupByID).Methods(http.MethodGet)
+	h.router.HandleFunc("/groups/{id}", h.UpdateGroup).Methods(http.MethodPatch)
+	h.router.HandleFunc("/groups/{id}", h.DeleteGroup).Methods(http.MethodDelete)
+	h.router.HandleFunc("/groups/{id}/songs", h.GroupSongs).Methods(http.MethodGet)
+	h.router.HandleFunc("/groups/{id}/aliases", h.NewGroupAlias).Methods(http.MethodPost)
+	h.router.HandleFunc("/groups/{id}/aliases/{aliasId}", h.DeleteGroupAlias).Methods(http.MethodDelete)
+	h.router.HandleFunc("/groups/{id}/merge", h.MergeGroups).Methods(http.MethodPost)
+
+	h.router.HandleFunc("/albums", h.Albums).Methods(http.MethodGet)
+	h.router.HandleFunc("/albums", h.NewAlbum).Methods(http.MethodPost)
+	h.router.HandleFunc("/albums/{id}", h.AlbumByID).Methods(http.MethodGet)
+	h.router.HandleFunc("/albums/{id}", h.UpdateAlbum).Methods(http.MethodPatch)
+	h.router.HandleFunc("/albums/{id}", h.DeleteAlbum).Methods(http.MethodDelete)
+	h.router.HandleFunc("/albums/{id}/tracks", h.SetAlbumTracks).Methods(http.MethodPut)
+
+	h.router.HandleFunc("/audit", h.Audit).Methods(http.MethodGet)
 
 	// Swagger маршрут
 	h.router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
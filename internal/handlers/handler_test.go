@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+	"github.com/Ktuty/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	logrus.SetLevel(logrus.FatalLevel)
+	os.Exit(m.Run())
+}
+
+// Функция для создания маршрутизатора поверх хранилища в памяти без обогащения песен
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	return newTestRouterWithRepo(t, repository.NewMemoryRepository())
+}
+
+// Функция для создания маршрутизатора поверх переданного хранилища без обогащения песен
+func newTestRouterWithRepo(t *testing.T, repo *repository.Repository) http.Handler {
+	t.Helper()
+	return NewHandler(services.NewService(repo, nil)).InitRouts()
+}
+
+// Функция для выполнения запроса к маршрутизатору; headers - пары имя, значение
+func serve(t *testing.T, router http.Handler, method, target, contentType, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// Функция для проверки статуса ответа
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body)
+	}
+}
+
+// Функция для разбора JSON-ответа
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+	}
+	return value
+}
+
+// Функция для создания песни через POST /songs
+func createSong(t *testing.T, router http.Handler, body string) models.Songs {
+	t.Helper()
+	rec := serve(t, router, http.MethodPost, "/songs", "application/json", body)
+	expectStatus(t, rec, http.StatusCreated)
+	return decode[models.Songs](t, rec)
+}
+
+// Функция для получения пути песни
+func songPath(id int, suffix ...string) string {
+	return "/songs/" + strconv.Itoa(id) + strings.Join(suffix, "")
+}
+
+// Функция для получения названий песен страницы
+func songTitles(songs []models.Songs) string {
+	titles := make([]string, 0, len(songs))
+	for _, song := range songs {
+		titles = append(titles, song.Song)
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestSongsMemoryCRUD(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	createSong(t, router, `{"group":"Muse","song":"Starlight"}`)
+	createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody"}`)
+
+	rec := serve(t, router, http.MethodGet, songPath(song.ID), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[models.Songs](t, rec); got.Song != "Uprising" || got.Group != "Muse" {
+		t.Errorf("song = %+v", got)
+	}
+
+	page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?group=muse&pageSize=1", "", ""))
+	if page.TotalPages != 2 || songTitles(page.Songs) != "Uprising" {
+		t.Errorf("page = %d pages, songs %s, want 2 pages, songs Uprising", page.TotalPages, songTitles(page.Songs))
+	}
+	page = decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?song=%25light", "", ""))
+	if songTitles(page.Songs) != "Starlight" {
+		t.Errorf("LIKE filter songs = %s, want Starlight", songTitles(page.Songs))
+	}
+
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"Paranoia is in bloom\n\nThe PR transmissions will resume"}`)
+	expectStatus(t, rec, http.StatusOK)
+	rec = serve(t, router, http.MethodGet, songPath(song.ID, "?vers=2"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[models.Songs](t, rec); got.Text != "The PR transmissions will resume" {
+		t.Errorf("second verse = %q", got.Text)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(song.ID), "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID), "", ""), http.StatusNotFound)
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs/abc", "", ""), http.StatusBadRequest)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/Ktuty/internal/services"
+	"github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// Типы содержимого импортируемого каталога песен
+const (
+	csvType    = "text/csv"
+	ndjsonType = "application/x-ndjson"
+)
+
+//	@Summary		Import a song catalog
+//	@Description	Import songs from a CSV or JSON Lines body without calling the external API: imported songs get enrichment status imported and are not queued for enrichment; POST /songs/{id}/enrich queues one. The body is read as a stream without the server read and write timeouts, and songs are inserted in batches of 1000, each batch in its own transaction: if the import fails, the batches inserted before the error stay saved and the report of the rows read so far is returned with the error status and message; valid rows that were not saved are not_processed.
+//	@Description	CSV needs a header with the columns group and song and optionally text, releaseDate and link; an id column, as in GET /songs/export, is ignored. Every JSON Lines line is a song object as in POST /songs or GET /songs/export?format=ndjson; a primary credit of the song's own group, as in the export, is ignored.
+//	@Description	Rows are validated as in POST /songs: invalid rows are reported as failed, rows whose group already has a song with the same title as skipped. With dryRun=true nothing is saved and the report shows what the import would do.
+//	@Tags			songs
+//	@Accept			text/csv
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			catalog		body		string	true	"CSV or JSON Lines catalog"
+//	@Param			dryRun		query		bool	false	"Validate and report without saving"	default(false)
+//	@Param			X-Author	header		string	false	"Author of the change"
+//	@Success		200			{object}	models.ImportReport
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		415			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/import [post]
+func (h *Handler) ImportSongs(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	clearDeadlines(w)
+
+	dryRun := getQueryParamAsBool(r, "dryRun", false)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	logrus.WithFields(logrus.Fields{
+		"contentType": mediaType,
+		"dryRun":      dryRun,
+	}).Info("ImportSongs: parameters")
+
+	var reader services.SongReader
+	switch mediaType {
+	case csvType:
+		var err error
+		if reader, err = services.NewCSVSongReader(r.Body); err != nil {
+			logrus.WithError(err).Error("Некорректный заголовок CSV")
+			writeServiceError(w, err)
+			return
+		}
+	case ndjsonType, "application/jsonl", "application/x-jsonlines":
+		reader = services.NewNDJSONSongReader(r.Body)
+	default:
+		logrus.WithField("contentType", mediaType).Error("Неподдерживаемый тип каталога")
+		newErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be "+csvType+" or "+ndjsonType)
+		return
+	}
+
+	report, err := h.services.Import(r.Context(), reader, dryRun)
+	if err != nil && len(report.Rows) == 0 {
+		logrus.WithError(err).Error("Ошибка при импорте каталога песен")
+		writeServiceError(w, err)
+		return
+	}
+	// Прерванный импорт возвращает отчёт о прочитанных строках со статусом ошибки
+	code := http.StatusOK
+	if err != nil {
+		code = errorStatus(err)
+		report.Error = errorMessage(err, code)
+		logrus.WithError(err).WithField("inserted", report.Inserted).Error("Импорт каталога песен прерван")
+	}
+	logrus.WithFields(logrus.Fields{
+		"inserted": report.Inserted,
+		"skipped":  report.Skipped,
+		"failed":   report.Failed,
+		"dryRun":   report.DryRun,
+	}).Info("ImportSongs: report")
+
+	writeJSON(w, code, report)
+}
+
+// Функция для снятия ограничений времени чтения запроса и записи ответа сервера для импорта,
+// который читает весь каталог песен. Если соединение не поддерживает снятие ограничений,
+// запрос выполняется с ними.
+func clearDeadlines(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		logrus.WithError(err).Warn("Error clearing read deadline")
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logrus.WithError(err).Warn("Error clearing write deadline")
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestImportSongs(t *testing.T) {
+	router := newTestRouter(t)
+	catalog := "group,song,releaseDate\nMuse,Uprising,16.07.2006\nMuse,uprising,\nMuse,,\nMuse,Starlight,31.02.2006\n"
+
+	rec := serve(t, router, http.MethodPost, "/songs/import?dryRun=true", "text/csv", catalog)
+	expectStatus(t, rec, http.StatusOK)
+	report := decode[models.ImportReport](t, rec)
+	if report.Inserted != 1 || report.Skipped != 1 || report.Failed != 2 {
+		t.Errorf("dry run report = %d inserted, %d skipped, %d failed, want 1, 1, 2", report.Inserted, report.Skipped, report.Failed)
+	}
+	if page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs", "", "")); len(page.Songs) != 0 {
+		t.Errorf("dry run saved songs: %s", songTitles(page.Songs))
+	}
+
+	rec = serve(t, router, http.MethodPost, "/songs/import", "text/csv", catalog)
+	expectStatus(t, rec, http.StatusOK)
+	report = decode[models.ImportReport](t, rec)
+	if report.Inserted != 1 || len(report.Rows) != 4 {
+		t.Fatalf("report = %+v, want 1 inserted of 4 rows", report)
+	}
+	if row := report.Rows[1]; row.Line != 3 || row.Status != models.ImportSkipped || row.ExistingID != report.Rows[0].ID {
+		t.Errorf("duplicate row = %+v", row)
+	}
+	if row := report.Rows[3]; row.Status != models.ImportFailed || len(row.Fields) != 1 || row.Fields[0].Field != "releaseDate" {
+		t.Errorf("invalid date row = %+v", row)
+	}
+
+	rec = serve(t, router, http.MethodPost, "/songs/import", "application/x-ndjson", `{"group":"Muse","song":"Madness","artists":[{"group":"Muse","role":"featured"}]}`+"\n\n"+`{"group":"Muse"`+"\n")
+	expectStatus(t, rec, http.StatusOK)
+	if report = decode[models.ImportReport](t, rec); report.Inserted != 1 || report.Failed != 1 || report.Rows[1].Line != 3 {
+		t.Errorf("NDJSON report = %+v, want line 3 failed", report)
+	}
+
+	// Прерванный импорт возвращает отчёт о прочитанных строках со статусом ошибки
+	long := `{"group":"Muse","song":"Uprising","text":"` + strings.Repeat("a", 4<<20) + `"}`
+	rec = serve(t, router, http.MethodPost, "/songs/import", "application/x-ndjson", `{"group":"Muse","song":"Hysteria"}`+"\n"+long+"\n")
+	expectStatus(t, rec, http.StatusBadRequest)
+	report = decode[models.ImportReport](t, rec)
+	if report.NotProcessed != 1 || len(report.Rows) != 1 || report.Rows[0].Status != models.ImportNotProcessed || !strings.Contains(report.Error, "line 2") {
+		t.Errorf("stopped import report = %+v", report)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodPost, "/songs/import", "application/json", catalog), http.StatusUnsupportedMediaType)
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	source := newTestRouter(t)
+	createSong(t, source, `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom","releaseDate":"16.07.2006","artists":[{"group":"Matt Bellamy","role":"composer"}]}`)
+	song := createSong(t, source, `{"group":"Queen","song":"Under Pressure","artists":[{"group":"David Bowie","role":"featured"}]}`)
+	// Поля представления песни, которые импорт не сохраняет, не мешают импорту
+	expectStatus(t, serve(t, source, http.MethodPost, songPath(song.ID, "/tags"), "application/json", `{"tags":["live"]}`), http.StatusOK)
+
+	rec := serve(t, source, http.MethodGet, "/songs/export?format=ndjson", "", "")
+	expectStatus(t, rec, http.StatusOK)
+	catalog := rec.Body.String()
+
+	target := newTestRouter(t)
+	rec = serve(t, target, http.MethodPost, "/songs/import", "application/x-ndjson", catalog)
+	expectStatus(t, rec, http.StatusOK)
+	if report := decode[models.ImportReport](t, rec); report.Inserted != 2 || report.Failed != 0 {
+		t.Fatalf("report = %+v, want 2 inserted", report)
+	}
+
+	page := decode[models.SongsPage](t, serve(t, target, http.MethodGet, "/songs?sort=id", "", ""))
+	if songTitles(page.Songs) != "Uprising,Under Pressure" {
+		t.Fatalf("imported songs = %s", songTitles(page.Songs))
+	}
+	imported := page.Songs[1]
+	if len(imported.Artists) != 2 || imported.Artists[0].Group != "Queen" || imported.Artists[0].Role != models.ArtistRolePrimary ||
+		imported.Artists[1].Group != "David Bowie" || imported.Artists[1].Role != models.ArtistRoleFeatured {
+		t.Errorf("imported artists = %+v", imported.Artists)
+	}
+	if page.Songs[0].ReleaseDate != "16.07.2006" || page.Songs[0].Text != "Paranoia is in bloom" {
+		t.Errorf("imported song = %+v", page.Songs[0])
+	}
+}
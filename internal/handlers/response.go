@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// Функция для записи ответа об ошибке в формате models.ErrorResponse
+func newErrorResponse(w http.ResponseWriter, code int, message string) {
+	writeErrorResponse(w, models.ErrorResponse{Code: code, Message: message})
+}
+
+// Функция для записи подготовленного тела ответа об ошибке
+func writeErrorResponse(w http.ResponseWriter, response models.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Code)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа об ошибке")
+	}
+}
+
+// Функция для записи ошибки сервисного слоя с HTTP-статусом, соответствующим её типу.
+// Текст внутренних ошибок не раскрывается клиенту и только пишется в лог.
+func writeServiceError(w http.ResponseWriter, err error) {
+	// Ошибки валидации полей возвращаются списком со статусом 422
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		writeErrorResponse(w, models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: services.ErrValidation.Error(),
+			Fields:  validationErr.Fields,
+		})
+		return
+	}
+
+	// Повтор названия песни в группе возвращается с ID существующей песни
+	var duplicateErr *services.DuplicateSongError
+	if errors.As(err, &duplicateErr) {
+		writeErrorResponse(w, models.ErrorResponse{
+			Code:       http.StatusConflict,
+			Message:    err.Error(),
+			ExistingID: duplicateErr.ExistingID,
+		})
+		return
+	}
+
+	code := errorStatus(err)
+	newErrorResponse(w, code, errorMessage(err, code))
+}
+
+// Функция для получения текста ошибки для клиента; текст внутренних ошибок заменяется текстом статуса
+func errorMessage(err error, code int) string {
+	if code == http.StatusInternalServerError {
+		return http.StatusText(code)
+	}
+	return err.Error()
+}
+
+// Функция для определения HTTP-статуса по типу ошибки
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, services.ErrUpstreamPaused):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, services.ErrUpstreamFailure):
+		return http.StatusBadGateway
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Функция для записи успешного ответа в формате JSON с заданным статусом
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+	}
+}
+
+// Функция для построения ETag песни по её версии
+func songETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Функция для получения ожидаемой версии из заголовка If-Match: 0 без заголовка или для "*".
+// Принимается только один сильный ETag; иначе ответ 412 уже записан.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 1 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		logrus.WithField("If-Match", header).Error("Некорректный заголовок If-Match")
+		newErrorResponse(w, http.StatusPreconditionFailed, "If-Match must be a single strong ETag of the song")
+		return 0, false
+	}
+	return version, true
+}
+
+// Функция для проверки заголовка If-None-Match: true, если "*" или один из ETag совпадает с etag
+// при слабом сравнении
+func noneMatchHit(r *http.Request, etag string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
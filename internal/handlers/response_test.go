@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "validation", err: services.ErrValidation, want: http.StatusBadRequest},
+		{name: "not found", err: services.ErrNotFound, want: http.StatusNotFound},
+		{name: "wrapped not found", err: fmt.Errorf("song with id 1: %w", services.ErrNotFound), want: http.StatusNotFound},
+		{name: "conflict", err: services.ErrConflict, want: http.StatusConflict},
+		{name: "upstream failure", err: services.ErrUpstreamFailure, want: http.StatusBadGateway},
+		{name: "circuit open", err: services.ErrUpstreamPaused, want: http.StatusServiceUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{name: "internal", err: http.ErrHandlerTimeout, want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorStatus(tt.err); got != tt.want {
+				t.Errorf("errorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorResponseBody(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := serve(t, router, http.MethodGet, "/songs/42", "", "")
+	expectStatus(t, rec, http.StatusNotFound)
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	body := decode[models.ErrorResponse](t, rec)
+	if body.Code != http.StatusNotFound || body.Message == "" {
+		t.Errorf("body = %+v", body)
+	}
+}
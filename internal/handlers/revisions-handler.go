@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+//	@Summary		Get song revisions
+//	@Description	Get a page of the song's revisions, newest first, without snapshots. Every create, update and revert of a song, as well as changes of its genres, tags and album tracks, is stored as a revision.
+//	@Tags			revisions
+//	@Produce		json
+//	@Param			id			path		int	true	"Song ID"
+//	@Param			page		query		int	false	"Page number"	default(1)
+//	@Param			pageSize	query		int	false	"Page size"		default(10)
+//	@Success		200			{object}	models.RevisionsPage
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		404			{object}	models.ErrorResponse
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/{id}/revisions [get]
+func (h *Handler) SongRevisions(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+	}).Info("SongRevisions: parameters")
+
+	response, err := h.services.GetRevisions(r.Context(), songID, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении ревизий песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Get a song revision
+//	@Description	Get a revision of the song with the full snapshot of its lyrics and metadata
+//	@Tags			revisions
+//	@Produce		json
+//	@Param			id	path		int	true	"Song ID"
+//	@Param			rev	path		int	true	"Revision number"
+//	@Success		200	{object}	models.Revision
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/songs/{id}/revisions/{rev} [get]
+func (h *Handler) SongRevision(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := revisionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.services.GetRevision(r.Context(), songID, revision)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении ревизии песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Diff two song revisions
+//	@Description	Compare two revisions of the song: the changed metadata fields and a line-based diff of the lyrics
+//	@Tags			revisions
+//	@Produce		json
+//	@Param			id		path		int	true	"Song ID"
+//	@Param			from	query		int	true	"Old revision number"
+//	@Param			to		query		int	true	"New revision number"
+//	@Success		200		{object}	models.RevisionDiff
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/songs/{id}/revisions/diff [get]
+func (h *Handler) SongRevisionsDiff(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	from := getQueryParamAsInt(r, "from", 0)
+	to := getQueryParamAsInt(r, "to", 0)
+	logrus.WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	}).Info("SongRevisionsDiff: parameters")
+
+	response, err := h.services.DiffRevisions(r.Context(), songID, from, to)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при сравнении ревизий песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Revert a song to a revision
+//	@Description	Restore the song's lyrics, metadata, genres, tags and album from a revision. The revert is stored as a new revision; reverting to a deleted album is a conflict.
+//	@Tags			revisions
+//	@Produce		json
+//	@Param			id			path		int		true	"Song ID"
+//	@Param			rev			path		int		true	"Revision number"
+//	@Param			X-Author	header		string	false	"Author of the change"
+//	@Success		200			{object}	models.Songs
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		404			{object}	models.ErrorResponse
+//	@Failure		409			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/{id}/revisions/{rev}/revert [post]
+func (h *Handler) RevertSongRevision(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := revisionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	song, err := h.services.RevertRevision(r.Context(), songID, revision)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при возврате песни к ревизии")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, song)
+}
+
+// Функция для получения номера ревизии из переменных маршрута; при ошибке ответ уже записан
+func revisionFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	revision, err := strconv.Atoi(mux.Vars(r)["rev"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании номера ревизии в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	logrus.WithField("revision", revision).Info("Revision request: revision")
+	return revision, true
+}
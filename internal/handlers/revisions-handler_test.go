@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestSongRevisions(t *testing.T) {
+	router := newTestRouter(t)
+	rec := serve(t, router, http.MethodPost, "/songs", "application/json", `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom\nThe PR transmissions will resume"}`, "X-Author", "alice")
+	expectStatus(t, rec, http.StatusCreated)
+	song := decode[models.Songs](t, rec)
+
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"Paranoia is in bloom\nThey'll try to push drugs","link":"https://example.com"}`, "X-Author", "bob")
+	expectStatus(t, rec, http.StatusOK)
+
+	page := decode[models.RevisionsPage](t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions"), "", ""))
+	if len(page.Revisions) != 2 || page.Revisions[0].Revision != 2 || page.Revisions[0].Author != "bob" || page.Revisions[1].Author != "alice" {
+		t.Fatalf("revisions = %+v, want 2 by bob and 1 by alice", page.Revisions)
+	}
+
+	rec = serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/diff?from=1&to=2"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	diff := decode[models.RevisionDiff](t, rec)
+	want := []models.DiffLine{
+		{Op: models.DiffEqual, Line: "Paranoia is in bloom"},
+		{Op: models.DiffDelete, Line: "The PR transmissions will resume"},
+		{Op: models.DiffInsert, Line: "They'll try to push drugs"},
+	}
+	if len(diff.Text) != len(want) {
+		t.Fatalf("text diff = %+v, want %+v", diff.Text, want)
+	}
+	for i := range want {
+		if diff.Text[i] != want[i] {
+			t.Errorf("text diff line %d = %+v, want %+v", i, diff.Text[i], want[i])
+		}
+	}
+	if len(diff.Fields) != 1 || diff.Fields[0] != (models.FieldChange{Field: "link", From: "", To: "https://example.com"}) {
+		t.Errorf("field changes = %+v, want link", diff.Fields)
+	}
+
+	// Откат создаёт новую ревизию с состоянием первой
+	rec = serve(t, router, http.MethodPost, songPath(song.ID, "/revisions/1/revert"), "", "", "X-Author", "carol")
+	expectStatus(t, rec, http.StatusOK)
+	reverted := decode[models.Songs](t, rec)
+	if reverted.Text != song.Text || reverted.Link != "" {
+		t.Errorf("reverted song = %+v, want the text and link of revision 1", reverted)
+	}
+	rec = serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/3"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	revision := decode[models.Revision](t, rec)
+	if revision.RevertedFrom == nil || *revision.RevertedFrom != 1 || revision.Author != "carol" || revision.Snapshot == nil || revision.Snapshot.Text != song.Text {
+		t.Errorf("revision 3 = %+v", revision)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/9"), "", ""), http.StatusNotFound)
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(song.ID, "/revisions/9/revert"), "", ""), http.StatusNotFound)
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/diff?from=1"), "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestSongRevisionsLabelsAndAlbum(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	rec := serve(t, router, http.MethodPost, "/albums", "application/json", `{"title":"The Resistance","group":"Muse"}`)
+	expectStatus(t, rec, http.StatusCreated)
+	album := decode[models.Album](t, rec)
+
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(song.ID, "/genres"), "application/json", `{"genres":["rock"]}`), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(song.ID, "/tags"), "application/json", `{"tags":["live"]}`), http.StatusOK)
+	// Повторное добавление не меняет песню и не создаёт ревизию
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(song.ID, "/tags"), "application/json", `{"tags":["live"]}`), http.StatusOK)
+	tracks := `{"tracks":[{"trackNumber":1,"songId":` + strconv.Itoa(song.ID) + `}]}`
+	expectStatus(t, serve(t, router, http.MethodPut, "/albums/"+strconv.Itoa(album.ID)+"/tracks", "application/json", tracks), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(song.ID, "/genres/rock"), "", ""), http.StatusOK)
+
+	page := decode[models.RevisionsPage](t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions"), "", ""))
+	if len(page.Revisions) != 5 {
+		t.Fatalf("revisions = %+v, want 5", page.Revisions)
+	}
+	revision := decode[models.Revision](t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/4"), "", ""))
+	if snapshot := revision.Snapshot; snapshot == nil || strings.Join(snapshot.Genres, ",") != "rock" || strings.Join(snapshot.Tags, ",") != "live" ||
+		snapshot.Album == nil || snapshot.Album.ID != album.ID || snapshot.Album.TrackNumber != 1 {
+		t.Fatalf("revision 4 snapshot = %+v", revision.Snapshot)
+	}
+
+	diff := decode[models.RevisionDiff](t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions/diff?from=1&to=4"), "", ""))
+	want := []models.FieldChange{
+		{Field: "genres", From: "", To: "rock"},
+		{Field: "tags", From: "", To: "live"},
+		{Field: "album", From: "", To: "The Resistance #1"},
+	}
+	if len(diff.Fields) != len(want) {
+		t.Fatalf("field changes = %+v, want %+v", diff.Fields, want)
+	}
+	for i := range want {
+		if diff.Fields[i] != want[i] {
+			t.Errorf("field change %d = %+v, want %+v", i, diff.Fields[i], want[i])
+		}
+	}
+
+	// Откат к первой ревизии снимает жанры, теги и альбом, откат к четвёртой возвращает их
+	rec = serve(t, router, http.MethodPost, songPath(song.ID, "/revisions/1/revert"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if reverted := decode[models.Songs](t, rec); len(reverted.Genres) != 0 || len(reverted.Tags) != 0 || reverted.Album != nil {
+		t.Errorf("song reverted to revision 1 = %+v", reverted)
+	}
+	rec = serve(t, router, http.MethodPost, songPath(song.ID, "/revisions/4/revert"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if reverted := decode[models.Songs](t, rec); strings.Join(reverted.Genres, ",") != "rock" || strings.Join(reverted.Tags, ",") != "live" ||
+		reverted.Album == nil || reverted.Album.ID != album.ID || reverted.Album.TrackNumber != 1 {
+		t.Errorf("song reverted to revision 4 = %+v", reverted)
+	}
+
+	// Удаление альбома сохраняется как ревизия, откат к удалённому альбому - конфликт
+	expectStatus(t, serve(t, router, http.MethodDelete, "/albums/"+strconv.Itoa(album.ID), "", ""), http.StatusOK)
+	page = decode[models.RevisionsPage](t, serve(t, router, http.MethodGet, songPath(song.ID, "/revisions"), "", ""))
+	if len(page.Revisions) != 8 {
+		t.Fatalf("revisions after album delete = %d, want 8", len(page.Revisions))
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(song.ID, "/revisions/4/revert"), "", ""), http.StatusConflict)
+}
@@ -4,13 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/services"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"io"
+	"math"
+	"mime"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// Типы содержимого частичного обновления песни
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
 )
 
 //	@Summary		Get all songs
@@ -21,12 +30,25 @@ import (
 //	@Param			page		query		int		false	"Page number"	default(1)
 //	@Param			pageSize	query		int		false	"Page size"		default(10)
 //	@Param			song		query		string	false	"Song name"
-//	@Param			group		query		string	false	"Group name"
+//	@Param			group		query		string	false	"Group name, matches any credited artist (primary, featured, composer, lyricist)"
 //	@Param			text		query		string	false	"Song text"
-//	@Param			releaseDate	query		string	false	"Release date"
-//	@Param			link		query		string	false	"Link"
-//	@Success		200			{object}	models.Songs
+//	@Param			releaseDate		query		string	false	"Release date"
+//	@Param			releaseDateFrom	query		string	false	"Released on or after (DD.MM.YYYY or YYYY-MM-DD)"
+//	@Param			releaseDateTo	query		string	false	"Released on or before (DD.MM.YYYY or YYYY-MM-DD)"
+//	@Param			link			query		string	false	"Link"
+//	@Param			album			query		string	false	"Album title"
+//	@Param			genre			query		string	false	"Comma-separated genre names"
+//	@Param			genreMode		query		string	false	"Match songs with any or all of the genres"	Enums(any, all)	default(any)
+//	@Param			tag				query		string	false	"Comma-separated tag names"
+//	@Param			tagMode			query		string	false	"Match songs with any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			fuzzy			query		bool	false	"Match song and group by trigram similarity, return score and didYouMean"	default(false)
+//	@Param			sort			query		string	false	"Comma-separated sort keys (id, song, group, releaseDate, link), prefix with - for descending"	default(id)
+//	@Param			cursor			query		string	false	"Opaque cursor from nextCursor/prevCursor, switches to cursor mode"
+//	@Param			limit			query		int		false	"Page size in cursor mode, switches to cursor mode"	default(10)
+//	@Param			withTotal		query		bool	false	"Return totalCount in cursor mode"	default(false)
+//	@Success		200				{object}	models.SongsPage
 //	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		422			{object}	models.ErrorResponse
 //	@Failure		500			{object}	models.ErrorResponse
 //	@Router			/songs [get]
 func (h *Handler) Songs(w http.ResponseWriter, r *http.Request) {
@@ -41,53 +63,85 @@ func (h *Handler) Songs(w http.ResponseWriter, r *http.Request) {
 	}).Info("Songs: page and pageSize parameters")
 
 	// Создание фильтра для поиска песен
-	filter := models.Songs{
-		Song:        r.URL.Query().Get("song"),
-		Group:       r.URL.Query().Get("group"),
-		Text:        r.URL.Query().Get("text"),
-		ReleaseDate: r.URL.Query().Get("releaseDate"),
-		Link:        r.URL.Query().Get("link"),
+	filter, err := songFilterFromQuery(r)
+	if err != nil {
+		logrus.WithError(err).Error("Некорректные параметры фильтра")
+		writeServiceError(w, err)
+		return
 	}
 	logrus.WithField("filter", filter).Info("Songs: filter parameters")
 
-	// Получение списка песен с использованием сервиса
-	songs, totalPages, err := h.services.GetAll(filter, page, pageSize)
+	// Режим курсора включается параметрами cursor или limit
+	if r.URL.Query().Has("cursor") || r.URL.Query().Has("limit") {
+		h.songsByCursor(w, r, filter)
+		return
+	}
+
+	// Разбор параметра сортировки
+	sort, err := services.ParseSongSort(r.URL.Query().Get("sort"))
 	if err != nil {
-		logrus.WithError(err).Error("Ошибка при получении песен")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Некорректный параметр сортировки")
+		writeServiceError(w, err)
 		return
 	}
+	logrus.WithField("sort", sort).Info("Songs: sort parameters")
 
-	// Формирование ответа
-	response := struct {
-		Songs       []models.Songs `json:"songs"`
-		TotalPages  int            `json:"totalPages"`
-		CurrentPage int            `json:"currentPage"`
-		PageSize    int            `json:"pageSize"`
-	}{
-		Songs:       songs,
-		TotalPages:  totalPages,
-		CurrentPage: page,
-		PageSize:    pageSize,
+	// Получение списка песен с использованием сервиса
+	response, err := h.services.GetAll(r.Context(), filter, sort, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении песен")
+		writeServiceError(w, err)
+		return
 	}
 	logrus.WithField("response", response).Info("Songs: response")
 
 	// Кодирование ответа в JSON
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logrus.WithError(err).Error("Ошибка при кодировании ответа")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// Функция для выдачи списка песен в режиме курсора (keyset-пагинация)
+func (h *Handler) songsByCursor(w http.ResponseWriter, r *http.Request, filter models.SongFilter) {
+	limit := getQueryParamAsInt(r, "limit", 10)
+	withTotal := getQueryParamAsBool(r, "withTotal", false)
+	logrus.WithFields(logrus.Fields{
+		"limit":     limit,
+		"withTotal": withTotal,
+	}).Info("Songs: cursor mode parameters")
+
+	page, err := h.services.GetByCursor(r.Context(), filter, r.URL.Query().Get("sort"), r.URL.Query().Get("cursor"), limit, withTotal)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении песен")
+		writeServiceError(w, err)
+		return
+	}
+	logrus.WithField("response", page).Info("Songs: response")
+
+	// Кодирование ответа в JSON
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+		newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 }
 
 //	@Summary		Create a new song
-//	@Description	Create a new song by sending a request to an external API and then saving the song details.
+//	@Description	Create a new song without waiting for the external API. The song is saved with enrichment status pending, and background workers fetch its release date, text and link from the external API, retrying with exponential backoff; the status becomes enriched or, after the last attempt, failed. Optional artists credit other groups as featured, composer or lyricist.
+//	@Description	A group cannot have two songs with the same title (ignoring case, spacing and diacritics). By default such a song is rejected with 409 and the id of the existing song in existingId; onConflict=skip returns the existing song unchanged and onConflict=update updates it with the non-empty fields of the new one and enriches it again.
 //	@Tags			songs
 //	@Accept			json
 //	@Produce		json
-//	@Param			song	body	models.Params	true	"Song details"
-//	@Success		201
-//	@Failure		400	{object}	models.ErrorResponse
+//	@Param			song		body		models.Params	true	"Song details"
+//	@Param			onConflict	query		string			false	"What to do when the group already has a song with the same title"	Enums(error, skip, update)	default(error)
+//	@Param			X-Author	header		string			false	"Author of the change"
+//	@Success		201			{object}	models.Songs
+//	@Success		200			{object}	models.Songs
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		409			{object}	models.ErrorResponse
+//	@Failure		422	{object}	models.ErrorResponse
 //	@Failure		500	{object}	models.ErrorResponse
 //	@Router			/songs [post]
 func (h *Handler) NewSong(w http.ResponseWriter, r *http.Request) {
@@ -100,71 +154,131 @@ func (h *Handler) NewSong(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&song)
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка при декодировании JSON")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	songStr := url.QueryEscape(song.Song)
-	groupStr := url.QueryEscape(song.Group)
+	// Проверка параметров до создания песни
+	if err := services.ValidateParams(models.Params{Group: song.Group, Song: song.Song, Artists: song.Artists}); err != nil {
+		logrus.WithError(err).Error("Некорректные параметры песни")
+		writeServiceError(w, err)
+		return
+	}
+	onConflict := r.URL.Query().Get("onConflict")
+	if err := services.ValidateOnConflict(onConflict); err != nil {
+		logrus.WithError(err).Error("Некорректный параметр onConflict")
+		writeServiceError(w, err)
+		return
+	}
 
-	apiURL := fmt.Sprintf("%s?group=%s&song=%s", h.url, groupStr, songStr)
-	logrus.WithField("apiURL", apiURL).Info("Requesting data from external API")
+	logrus.WithField("song", song).Info("Новая песня")
 
-	resp, err := http.Get(apiURL)
+	// Создание новой песни с использованием сервиса
+	result, created, err := h.services.Create(r.Context(), song, onConflict)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при создании песни")
+		writeServiceError(w, err)
+		return
 	}
-	defer resp.Body.Close()
 
-	logrus.WithField("status", resp.StatusCode).Info("Received response from external API")
-
-	if resp.StatusCode != http.StatusOK {
-		logrus.WithField("status", resp.StatusCode).Error("External API returned non-OK status")
-		http.Error(w, "External API returned non-OK status", http.StatusInternalServerError)
-		return
+	// Песня не создана, если в группе уже была песня с тем же названием
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+		w.Header().Set("Location", fmt.Sprintf("/songs/%d", result.ID))
 	}
+	w.Header().Set("ETag", songETag(result.Version))
+	writeJSON(w, code, result)
+}
+
+//	@Summary		Find near-duplicate songs
+//	@Description	Report pairs of songs whose lyrics are similar by trigram similarity, most similar first. Songs without lyrics and songs in the trash are skipped. The total number of pairs is not counted: hasMore tells whether the next page has pairs.
+//	@Tags			songs
+//	@Produce		json
+//	@Param			threshold	query		number	false	"Minimum lyrics similarity, from 0 (exclusive) to 1"	default(0.7)
+//	@Param			page		query		int		false	"Page number"											default(1)
+//	@Param			pageSize	query		int		false	"Page size"												default(10)
+//	@Success		200			{object}	models.DuplicatesPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/duplicates [get]
+func (h *Handler) SongDuplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := getQueryParamAsFloat(r, "threshold", defaultDuplicateThreshold)
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	logrus.WithFields(logrus.Fields{
+		"threshold": threshold,
+		"page":      page,
+		"pageSize":  pageSize,
+	}).Info("SongDuplicates: parameters")
 
-	body, err := io.ReadAll(resp.Body)
+	response, err := h.services.Duplicates(r.Context(), threshold, page, pageSize)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to read response body")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Ошибка при поиске похожих песен")
+		writeServiceError(w, err)
 		return
 	}
 
-	logrus.WithField("responseBody", string(body)).Info("Response body from external API")
+	writeJSON(w, http.StatusOK, response)
+}
 
-	if err := json.Unmarshal(body, &song); err != nil {
-		logrus.WithError(err).Error("Failed to unmarshal song data")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+// Порог схожести текстов по умолчанию для отчёта о похожих песнях
+const defaultDuplicateThreshold = 0.7
+
+//	@Summary		Search lyrics
+//	@Description	Full-text search over song titles and lyrics ranked by relevance, with highlighted snippets of the matching verses
+//	@Tags			songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			q			query		string	true	"Search query (websearch syntax: words, quoted phrases, or, -exclude)"
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Success		200			{object}	models.SearchPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/search [get]
+func (h *Handler) SearchSongs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
+	query := r.URL.Query().Get("q")
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
 	logrus.WithFields(logrus.Fields{
-		"song":        song,
-		"releaseDate": song.ReleaseDate,
-	}).Info("Новая песня")
+		"q":        query,
+		"page":     page,
+		"pageSize": pageSize,
+	}).Info("SearchSongs: parameters")
 
-	// Создание новой песни с использованием сервиса
-	if err := h.services.Create(song); err != nil {
-		logrus.WithError(err).Error("Ошибка при создании песни")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Поиск песен с использованием сервиса
+	result, err := h.services.Search(r.Context(), query, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при поиске песен")
+		writeServiceError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	// Кодирование ответа в JSON
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logrus.WithError(err).Error("Ошибка при кодировании ответа")
+		newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 }
 
 //	@Summary		Get a song by ID
-//	@Description	Get a song by its ID
+//	@Description	Get a song by its ID. The ETag header carries the song version; a matching If-None-Match returns 304.
 //	@Tags			songs
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		int	true	"Song ID"
-//	@Param			vers	query		int	false	"Verse number"	default(0)
-//	@Success		200		{object}	models.Songs
-//	@Failure		400		{object}	models.ErrorResponse
-//	@Failure		404		{object}	models.ErrorResponse
-//	@Failure		500		{object}	models.ErrorResponse
+//	@Param			id				path		int		true	"Song ID"
+//	@Param			vers			query		int		false	"Verse number"	default(0)
+//	@Param			If-None-Match	header		string	false	"ETag of the cached song"
+//	@Success		200				{object}	models.Songs
+//	@Header			200				{string}	ETag	"Song version"
+//	@Success		304
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
 //	@Router			/songs/{id} [get]
 func (h *Handler) SongByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -178,16 +292,24 @@ func (h *Handler) SongByID(w http.ResponseWriter, r *http.Request) {
 	songID, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка при преобразовании songID в int")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	logrus.WithField("songID", songID).Info("SongByID: songID")
 
 	// Получение песни по ID с использованием сервиса
-	song, err := h.services.GetByID(songID)
+	song, err := h.services.GetByID(r.Context(), songID)
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка при получении песни")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+
+	// Клиенту с актуальной версией песни тело не отправляется
+	etag := songETag(song.Version)
+	w.Header().Set("ETag", etag)
+	if noneMatchHit(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -196,7 +318,7 @@ func (h *Handler) SongByID(w http.ResponseWriter, r *http.Request) {
 		verse, err := splitTextDoubleMargins(song.Text, vers-1)
 		if err != nil {
 			logrus.WithError(err).Error("Ошибка при разделении текста на куплеты")
-			http.Error(w, err.Error(), http.StatusNotFound)
+			newErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
 
@@ -206,20 +328,29 @@ func (h *Handler) SongByID(w http.ResponseWriter, r *http.Request) {
 	// Кодирование ответа в JSON
 	if err := json.NewEncoder(w).Encode(song); err != nil {
 		logrus.WithError(err).Error("Ошибка при кодировании ответа")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 }
 
 //	@Summary		Update a song
-//	@Description	Update a song by its ID
+//	@Description	Update a song by its ID. With application/json empty fields are left unchanged and a non-null artists list replaces the non-primary credits.
+//	@Description	With application/merge-patch+json (RFC 7396) null clears a field; application/json-patch+json (RFC 6902) applies operations to the fields song, group, text, releaseDate, link and artists.
+//	@Description	With If-Match the song is updated only while its ETag matches.
 //	@Tags			songs
-//	@Accept			json
+//	@Accept			json,application/merge-patch+json,application/json-patch+json
 //	@Produce		json
-//	@Param			id		path	int				true	"Song ID"
-//	@Param			song	body	models.Songs	true	"Song details"
+//	@Param			id			path	int				true	"Song ID"
+//	@Param			song		body	models.Songs	true	"Song details, merge patch or JSON patch"
+//	@Param			X-Author	header	string			false	"Author of the change"
+//	@Param			If-Match	header	string			false	"ETag of the song being edited"
 //	@Success		200
+//	@Header			200	{string}	ETag	"New song version"
 //	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		409	{object}	models.ErrorResponse
+//	@Failure		412	{object}	models.ErrorResponse
+//	@Failure		422	{object}	models.ErrorResponse
 //	@Failure		500	{object}	models.ErrorResponse
 //	@Router			/songs/{id} [patch]
 func (h *Handler) UpdateSong(w http.ResponseWriter, r *http.Request) {
@@ -230,40 +361,114 @@ func (h *Handler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	songID, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка при преобразовании songID в int")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	logrus.WithField("songID", songID).Info("UpdateSong: songID")
 
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при чтении тела запроса")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Семантика обновления определяется типом содержимого: merge patch и JSON patch различают
+	// отсутствующие, null и пустые поля, любой другой тип разбирается как JSON с пропуском пустых значений
+	var updated models.Songs
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mergePatchType:
+		updated, err = h.services.MergePatch(r.Context(), songID, body, version)
+	case jsonPatchType:
+		updated, err = h.services.JSONPatch(r.Context(), songID, body, version)
+	default:
+		// Декодирование JSON из тела запроса
+		var song models.Songs
+		if err := json.Unmarshal(body, &song); err != nil {
+			logrus.WithError(err).Error("Ошибка при декодировании JSON")
+			newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		updated, err = h.services.Update(r.Context(), songID, song, version)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при обновлении песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", songETag(updated.Version))
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Summary		Replace a song
+//	@Description	Replace all fields of a song by its ID. Omitted optional fields are cleared and an omitted artists list removes the non-primary credits. With If-Match the song is replaced only while its ETag matches.
+//	@Tags			songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int				true	"Song ID"
+//	@Param			song		body		models.Songs	true	"Song details"
+//	@Param			X-Author	header		string			false	"Author of the change"
+//	@Param			If-Match	header		string			false	"ETag of the song being replaced"
+//	@Success		200			{object}	models.Songs
+//	@Header			200			{string}	ETag	"New song version"
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		404			{object}	models.ErrorResponse
+//	@Failure		409			{object}	models.ErrorResponse
+//	@Failure		412			{object}	models.ErrorResponse
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/{id} [put]
+func (h *Handler) ReplaceSong(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
 	var song models.Songs
 	defer r.Body.Close()
 
 	// Декодирование JSON из тела запроса
-	err = json.NewDecoder(r.Body).Decode(&song)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
 		logrus.WithError(err).Error("Ошибка при декодировании JSON")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Обновление песни с использованием сервиса
-	if err := h.services.Update(songID, song); err != nil {
-		logrus.WithError(err).Error("Ошибка при обновлении песни")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	replaced, err := h.services.Replace(r.Context(), songID, song, version)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при замене песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", songETag(replaced.Version))
+	writeJSON(w, http.StatusOK, replaced)
 }
 
 //	@Summary		Delete a song
-//	@Description	Delete a song by its ID
+//	@Description	Move a song to the trash by its ID. Songs in the trash are hidden and purged after the retention period. With If-Match the song is deleted only while its ETag matches.
 //	@Tags			songs
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	int	true	"Song ID"
+//	@Param			id			path	int		true	"Song ID"
+//	@Param			If-Match	header	string	false	"ETag of the song being deleted"
 //	@Success		200
 //	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		412	{object}	models.ErrorResponse
 //	@Failure		500	{object}	models.ErrorResponse
 //	@Router			/songs/{id} [delete]
 func (h *Handler) DeleteSongs(w http.ResponseWriter, r *http.Request) {
@@ -274,21 +479,57 @@ func (h *Handler) DeleteSongs(w http.ResponseWriter, r *http.Request) {
 	songID, err := strconv.Atoi(vars["id"])
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка при преобразовании songID в int")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	logrus.WithField("songID", songID).Info("DeleteSongs: songID")
 
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	// Удаление песни с использованием сервиса
-	if err := h.services.Delete(songID); err != nil {
+	if err := h.services.Delete(r.Context(), songID, version); err != nil {
 		logrus.WithError(err).Error("Ошибка при удалении песни")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+//	@Summary		Enrich a song again
+//	@Description	Queue a song for fetching its release date, text and link from the external API again, for example after the enrichment failed. The attempts are reset and the first one starts right away; the song is returned with enrichment status pending. Imported songs, with enrichment status imported, are enriched only this way.
+//	@Tags			songs
+//	@Produce		json
+//	@Param			id			path		int		true	"Song ID"
+//	@Param			X-Author	header		string	false	"Author of the change"
+//	@Success		202			{object}	models.Songs
+//	@Failure		400			{object}	models.ErrorResponse
+//	@Failure		404			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/songs/{id}/enrich [post]
+func (h *Handler) EnrichSong(w http.ResponseWriter, r *http.Request) {
+	// Получение ID песни из переменных маршрута
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании songID в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	logrus.WithField("songID", songID).Info("EnrichSong: songID")
+
+	song, err := h.services.Enrich(r.Context(), songID)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при постановке песни в очередь обогащения")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, song)
+}
+
 // Функция для получения параметра из запроса в виде целого числа
 func getQueryParamAsInt(r *http.Request, param string, defaultValue int) int {
 	valueStr := r.URL.Query().Get(param)
@@ -311,6 +552,102 @@ func getQueryParamAsInt(r *http.Request, param string, defaultValue int) int {
 	return value
 }
 
+// Функция для получения параметра из запроса в виде логического значения
+func getQueryParamAsBool(r *http.Request, param string, defaultValue bool) bool {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"param": param,
+			"error": err,
+			"value": valueStr,
+		}).Debug("getQueryParamAsBool: error converting valueStr to bool")
+		return defaultValue
+	}
+	return value
+}
+
+// Функция для получения дробного параметра запроса; значение по умолчанию, если параметр пустой.
+// Некорректное значение возвращается как NaN, чтобы его отклонила проверка сервиса.
+func getQueryParamAsFloat(r *http.Request, param string, defaultValue float64) float64 {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"param": param,
+			"error": err,
+			"value": valueStr,
+		}).Debug("getQueryParamAsFloat: error converting valueStr to float")
+		return math.NaN()
+	}
+	return value
+}
+
+// Функция для построения фильтра списка песен из параметров запроса
+func songFilterFromQuery(r *http.Request) (models.SongFilter, error) {
+	query := r.URL.Query()
+	filter := models.SongFilter{
+		Song:        query.Get("song"),
+		Group:       query.Get("group"),
+		Text:        query.Get("text"),
+		ReleaseDate: query.Get("releaseDate"),
+		Link:        query.Get("link"),
+		Album:       query.Get("album"),
+		Fuzzy:       getQueryParamAsBool(r, "fuzzy", false),
+	}
+
+	var fields []models.FieldError
+	for _, labels := range []struct {
+		param  string
+		target *models.LabelFilter
+	}{
+		{"genre", &filter.Genres},
+		{"tag", &filter.Tags},
+	} {
+		for _, name := range strings.Split(query.Get(labels.param), ",") {
+			if strings.TrimSpace(name) != "" {
+				labels.target.Names = append(labels.target.Names, name)
+			}
+		}
+		switch mode := query.Get(labels.param + "Mode"); mode {
+		case "", "any":
+		case "all":
+			labels.target.All = true
+		default:
+			fields = append(fields, models.FieldError{Field: labels.param + "Mode", Message: "must be one of any, all"})
+		}
+	}
+	for _, bound := range []struct {
+		param  string
+		target *time.Time
+	}{
+		{"releaseDateFrom", &filter.ReleaseDateFrom},
+		{"releaseDateTo", &filter.ReleaseDateTo},
+	} {
+		value := query.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		date, err := models.ParseReleaseDate(value)
+		if err != nil {
+			fields = append(fields, models.FieldError{Field: bound.param, Message: "must be a date in DD.MM.YYYY or YYYY-MM-DD format"})
+			continue
+		}
+		*bound.target = date
+	}
+	if len(fields) > 0 {
+		return filter, &services.ValidationError{Fields: fields}
+	}
+
+	return filter, nil
+}
+
 // Функция для разделения текста на куплеты
 func splitTextDoubleMargins(text string, vers int) (string, error) {
 	logrus.WithFields(logrus.Fields{
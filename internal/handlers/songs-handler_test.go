@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestNewSong(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []string
+	}{
+		{name: "created", body: `{"group":"Muse","song":"Uprising"}`, status: http.StatusCreated},
+		{name: "malformed JSON", body: `{"group":`, status: http.StatusBadRequest},
+		{name: "missing fields", body: `{}`, status: http.StatusUnprocessableEntity, fields: []string{"group", "song"}},
+		{name: "too long group", body: `{"group":"` + strings.Repeat("a", 256) + `","song":"x"}`, status: http.StatusUnprocessableEntity, fields: []string{"group"}},
+		{name: "invalid link", body: `{"group":"Muse","song":"Uprising","link":"/watch"}`, status: http.StatusUnprocessableEntity, fields: []string{"link"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			rec := serve(t, router, http.MethodPost, "/songs", "application/json", tt.body)
+			expectStatus(t, rec, tt.status)
+			if tt.fields == nil {
+				return
+			}
+			response := decode[models.ErrorResponse](t, rec)
+			var fields []string
+			for _, field := range response.Fields {
+				fields = append(fields, field.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestUpdateSongValidation(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+
+	rec := serve(t, router, http.MethodPut, songPath(song.ID), "application/json", `{"group":"Muse"}`)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"releaseDate":"16/07/2006"}`)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if fields := decode[models.ErrorResponse](t, rec).Fields; len(fields) != 1 || fields[0].Field != "releaseDate" {
+		t.Errorf("fields = %+v, want releaseDate", fields)
+	}
+}
+
+func TestSongsReleaseDateRange(t *testing.T) {
+	router := newTestRouter(t)
+	createSong(t, router, `{"group":"Muse","song":"Sunburn","releaseDate":"1999-12-06"}`)
+	createSong(t, router, `{"group":"Muse","song":"Uprising","releaseDate":"16.07.2006"}`)
+	createSong(t, router, `{"group":"Muse","song":"Madness","releaseDate":"2012-08-20"}`)
+	createSong(t, router, `{"group":"Muse","song":"Undated"}`)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "releaseDateFrom=2000-01-01", want: "Uprising,Madness"},
+		{query: "releaseDateTo=16.07.2006", want: "Sunburn,Uprising"},
+		{query: "releaseDateFrom=01.01.2000&releaseDateTo=2010-01-01", want: "Uprising"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := serve(t, router, http.MethodGet, "/songs?"+tt.query, "", "")
+			expectStatus(t, rec, http.StatusOK)
+			if got := songTitles(decode[models.SongsPage](t, rec).Songs); got != tt.want {
+				t.Errorf("songs = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	rec := serve(t, router, http.MethodGet, "/songs?releaseDateFrom=2006/07/16", "", "")
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	song := decode[models.Songs](t, serve(t, router, http.MethodGet, "/songs/2", "", ""))
+	if song.ReleaseDate != "16.07.2006" {
+		t.Errorf("releaseDate = %q, want 16.07.2006", song.ReleaseDate)
+	}
+}
+
+func TestSongsSort(t *testing.T) {
+	router := newTestRouter(t)
+	createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody","releaseDate":"1975-10-31"}`)
+	createSong(t, router, `{"group":"Muse","song":"Uprising","releaseDate":"2006-07-16"}`)
+	createSong(t, router, `{"group":"Muse","song":"Madness","releaseDate":"2012-08-20"}`)
+	createSong(t, router, `{"group":"Muse","song":"Undated"}`)
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{sort: "", want: "Bohemian Rhapsody,Uprising,Madness,Undated"},
+		{sort: "song", want: "Bohemian Rhapsody,Madness,Undated,Uprising"},
+		// Как и в PostgreSQL, при сортировке по убыванию песни без даты идут первыми
+		{sort: "group,-releaseDate", want: "Undated,Madness,Uprising,Bohemian Rhapsody"},
+		{sort: "-id", want: "Undated,Madness,Uprising,Bohemian Rhapsody"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			rec := serve(t, router, http.MethodGet, "/songs?sort="+tt.sort, "", "")
+			expectStatus(t, rec, http.StatusOK)
+			if got := songTitles(decode[models.SongsPage](t, rec).Songs); got != tt.want {
+				t.Errorf("songs = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs?sort=text", "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestSongsCursorPagination(t *testing.T) {
+	router := newTestRouter(t)
+	for _, title := range []string{"Uprising", "Madness", "Starlight", "Hysteria", "Resistance"} {
+		createSong(t, router, `{"group":"Muse","song":"`+title+`"}`)
+	}
+
+	var titles []string
+	target := "/songs?sort=song&limit=2&withTotal=true"
+	for pages := 0; target != ""; pages++ {
+		if pages == 5 {
+			t.Fatal("cursor pagination does not terminate")
+		}
+		rec := serve(t, router, http.MethodGet, target, "", "")
+		expectStatus(t, rec, http.StatusOK)
+		page := decode[models.SongsCursorPage](t, rec)
+		if pages == 0 && (page.TotalCount == nil || *page.TotalCount != 5) {
+			t.Errorf("totalCount = %v, want 5", page.TotalCount)
+		}
+		titles = append(titles, songTitles(page.Songs))
+		target = ""
+		if page.NextCursor != "" {
+			target = "/songs?limit=2&cursor=" + page.NextCursor
+		}
+	}
+	if got := strings.Join(titles, "|"); got != "Hysteria,Madness|Resistance,Starlight|Uprising" {
+		t.Errorf("pages = %s", got)
+	}
+
+	// Курсор с другой сортировкой отклоняется
+	cursor := decode[models.SongsCursorPage](t, serve(t, router, http.MethodGet, "/songs?sort=song&limit=2", "", "")).NextCursor
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs?sort=-id&cursor="+cursor, "", ""), http.StatusUnprocessableEntity)
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs?cursor=broken", "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestSearchSongs(t *testing.T) {
+	router := newTestRouter(t)
+	createSong(t, router, `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom\n\nThey will not force us\nThey will stop degrading us"}`)
+	createSong(t, router, `{"group":"Muse","song":"Resistance","text":"Love is our resistance\n\nThey'll keep us apart"}`)
+	createSong(t, router, `{"group":"Muse","song":"Madness","text":"I can't get it right\n\nThey will not force us"}`)
+
+	rec := serve(t, router, http.MethodGet, "/songs/search?q=resistance", "", "")
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[models.SearchPage](t, rec)
+	if len(page.Results) != 1 {
+		t.Fatalf("results = %+v, want one song", page.Results)
+	}
+	if result := page.Results[0]; result.Song != "Resistance" || len(result.Snippets) != 1 || result.Snippets[0].Verse != 1 ||
+		result.Snippets[0].Text != "Love is our <b>resistance</b>" {
+		t.Errorf("result = %+v", result)
+	}
+
+	page = decode[models.SearchPage](t, serve(t, router, http.MethodGet, "/songs/search?q=force+-madness", "", ""))
+	if len(page.Results) != 1 || page.Results[0].Song != "Uprising" || page.Results[0].Snippets[0].Verse != 2 {
+		t.Errorf("results = %+v, want Uprising verse 2", page.Results)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs/search", "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestSongsFuzzy(t *testing.T) {
+	router := newTestRouter(t)
+	createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody"}`)
+
+	page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?song=Bohemain+Rhapsody", "", ""))
+	if len(page.Songs) != 0 || page.DidYouMean == nil || page.DidYouMean.Song != "Bohemian Rhapsody" {
+		t.Errorf("songs = %s, didYouMean = %+v, want a Bohemian Rhapsody suggestion", songTitles(page.Songs), page.DidYouMean)
+	}
+
+	page = decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?group=Quen&song=Bohemain+Rhapsody&fuzzy=true", "", ""))
+	if songTitles(page.Songs) != "Bohemian Rhapsody" || page.Songs[0].Score == nil || *page.Songs[0].Score <= 0 {
+		t.Errorf("fuzzy songs = %+v", page.Songs)
+	}
+	// Подсказка возвращается и в нечётком режиме, пока нет точных совпадений
+	if page.DidYouMean == nil || page.DidYouMean.Group != "Queen" {
+		t.Errorf("didYouMean = %+v, want a Queen suggestion", page.DidYouMean)
+	}
+	page = decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?group=Queen&fuzzy=true", "", ""))
+	if page.DidYouMean != nil {
+		t.Errorf("didYouMean = %+v with exact hits", page.DidYouMean)
+	}
+}
+
+func TestSongArtists(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Eminem","song":"Love the Way You Lie","artists":[{"group":"Rihanna","role":"featured"},{"group":"Alex da Kid","role":"composer"}]}`)
+	createSong(t, router, `{"group":"Rihanna","song":"Umbrella"}`)
+
+	var credits []string
+	for _, artist := range song.Artists {
+		credits = append(credits, artist.Role+":"+artist.Group)
+	}
+	if got := strings.Join(credits, ","); got != "primary:Eminem,featured:Rihanna,composer:Alex da Kid" {
+		t.Errorf("artists = %s", got)
+	}
+
+	// Фильтр по группе находит песню по любому исполнителю
+	page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs?group=rihanna", "", ""))
+	if songTitles(page.Songs) != "Love the Way You Lie,Umbrella" {
+		t.Errorf("songs of Rihanna = %s", songTitles(page.Songs))
+	}
+
+	rec := serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"artists":[]}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(song.ID), "", "")).Artists; len(got) != 1 {
+		t.Errorf("artists after clearing = %+v, want only the primary group", got)
+	}
+
+	rec = serve(t, router, http.MethodPost, "/songs", "application/json", `{"group":"Muse","song":"Uprising","artists":[{"group":"Muse","role":"primary"},{"group":"","role":"singer"}]}`)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if fields := decode[models.ErrorResponse](t, rec).Fields; len(fields) != 3 {
+		t.Errorf("fields = %+v, want primary role, empty group and unknown role", fields)
+	}
+}
+
+func TestSongByIDETag(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+
+	rec := serve(t, router, http.MethodGet, songPath(song.ID), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	etag := rec.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("ETag = %q, want %q", etag, `"1"`)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID), "", "", "If-None-Match", etag), http.StatusNotModified)
+
+	// Изменение песни меняет ETag, и устаревший If-Match отклоняется
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"Paranoia is in bloom"}`, "If-Match", etag)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag after update = %q, want %q", got, `"2"`)
+	}
+	expectStatus(t, serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"again"}`, "If-Match", etag), http.StatusPreconditionFailed)
+	expectStatus(t, serve(t, router, http.MethodPut, songPath(song.ID), "application/json", `{"group":"Muse","song":"Uprising"}`, "If-Match", etag), http.StatusPreconditionFailed)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(song.ID), "", "", "If-Match", etag), http.StatusPreconditionFailed)
+	expectStatus(t, serve(t, router, http.MethodPatch, songPath(song.ID), "application/json", `{"text":"again"}`, "If-Match", "*"), http.StatusOK)
+
+	// Переименование группы тоже меняет ETag песни
+	etag = serve(t, router, http.MethodGet, songPath(song.ID), "", "").Header().Get("ETag")
+	expectStatus(t, serve(t, router, http.MethodPatch, "/groups/1", "application/json", `{"name":"MUSE"}`), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodGet, songPath(song.ID), "", "", "If-None-Match", etag), http.StatusOK)
+}
+
+func TestUpdateSongPatchFormats(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom","link":"https://example.com","releaseDate":"2006-07-16"}`)
+
+	// null в merge patch очищает поле, отсутствующее поле не меняется
+	rec := serve(t, router, http.MethodPatch, songPath(song.ID), "application/merge-patch+json", `{"link":null,"releaseDate":null}`)
+	expectStatus(t, rec, http.StatusOK)
+	got := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(song.ID), "", ""))
+	if got.Link != "" || got.ReleaseDate != "" || got.Text != "Paranoia is in bloom" {
+		t.Errorf("song after merge patch = %+v", got)
+	}
+
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json-patch+json", `[{"op":"test","path":"/song","value":"Uprising"},{"op":"replace","path":"/text","value":"They will not force us"}]`)
+	expectStatus(t, rec, http.StatusOK)
+	if got = decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(song.ID), "", "")); got.Text != "They will not force us" {
+		t.Errorf("text after JSON patch = %q", got.Text)
+	}
+
+	// Неудачная проверка test не применяет ни одной операции
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/json-patch+json", `[{"op":"replace","path":"/text","value":"lost"},{"op":"test","path":"/song","value":"Madness"}]`)
+	expectStatus(t, rec, http.StatusConflict)
+	if got = decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(song.ID), "", "")); got.Text != "They will not force us" {
+		t.Errorf("text after failed JSON patch = %q", got.Text)
+	}
+	rec = serve(t, router, http.MethodPatch, songPath(song.ID), "application/merge-patch+json", `{"song":null}`)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+}
+
+func TestNewSongConflict(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+
+	rec := serve(t, router, http.MethodPost, "/songs", "application/json", `{"group":"muse","song":" UPRISING "}`)
+	expectStatus(t, rec, http.StatusConflict)
+	if response := decode[models.ErrorResponse](t, rec); response.ExistingID != song.ID {
+		t.Errorf("existingId = %d, want %d", response.ExistingID, song.ID)
+	}
+
+	rec = serve(t, router, http.MethodPost, "/songs?onConflict=skip", "application/json", `{"group":"muse","song":"uprising","text":"ignored"}`)
+	expectStatus(t, rec, http.StatusOK)
+	if skipped := decode[models.Songs](t, rec); skipped.ID != song.ID || skipped.Text != "" {
+		t.Errorf("skipped song = %+v, want the unchanged song %d", skipped, song.ID)
+	}
+
+	rec = serve(t, router, http.MethodPost, "/songs?onConflict=update", "application/json", `{"group":"muse","song":"uprising","text":"Paranoia is in bloom"}`)
+	expectStatus(t, rec, http.StatusOK)
+	if updated := decode[models.Songs](t, rec); updated.ID != song.ID || updated.Song != "uprising" || updated.Text != "Paranoia is in bloom" {
+		t.Errorf("updated song = %+v", updated)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodPost, "/songs?onConflict=replace", "application/json", `{"group":"Muse","song":"Madness"}`), http.StatusUnprocessableEntity)
+	// Переименование в занятое название тоже отклоняется
+	madness := createSong(t, router, `{"group":"Muse","song":"Madness"}`)
+	expectStatus(t, serve(t, router, http.MethodPatch, songPath(madness.ID), "application/json", `{"song":"uprising"}`), http.StatusConflict)
+}
+
+func TestSongDuplicates(t *testing.T) {
+	router := newTestRouter(t)
+	verse := "Paranoia is in bloom, the PR transmissions will resume"
+	createSong(t, router, `{"group":"Muse","song":"Uprising","text":"`+verse+`"}`)
+	createSong(t, router, `{"group":"Muse Tribute","song":"Uprising (cover)","text":"`+verse+` again"}`)
+	createSong(t, router, `{"group":"Muse Karaoke","song":"Uprising (karaoke)","text":"`+verse+`"}`)
+	createSong(t, router, `{"group":"Queen","song":"Bohemian Rhapsody","text":"Is this the real life? Is this just fantasy?"}`)
+
+	rec := serve(t, router, http.MethodGet, "/songs/duplicates?pageSize=2", "", "")
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[models.DuplicatesPage](t, rec)
+	if len(page.Duplicates) != 2 || !page.HasMore || page.Threshold != 0.7 {
+		t.Fatalf("page = %+v, want 2 of 3 pairs", page)
+	}
+	if first := page.Duplicates[0]; first.Similarity != 1 || first.First.ID != 1 || first.Second.ID != 3 {
+		t.Errorf("most similar pair = %+v, want songs 1 and 3", first)
+	}
+
+	page = decode[models.DuplicatesPage](t, serve(t, router, http.MethodGet, "/songs/duplicates?pageSize=2&page=2", "", ""))
+	if len(page.Duplicates) != 1 || page.HasMore {
+		t.Errorf("last page = %+v, want one pair", page)
+	}
+	expectStatus(t, serve(t, router, http.MethodGet, "/songs/duplicates?threshold=0", "", ""), http.StatusUnprocessableEntity)
+}
+
+func TestEnrichSong(t *testing.T) {
+	router := newTestRouter(t)
+	song := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	if song.Enrichment == nil || song.Enrichment.Status != models.EnrichmentPending {
+		t.Errorf("new song enrichment = %+v, want pending", song.Enrichment)
+	}
+
+	// Импортированные песни не ставятся в очередь обогащения
+	rec := serve(t, router, http.MethodPost, "/songs/import", "application/x-ndjson", `{"group":"Muse","song":"Madness"}`)
+	expectStatus(t, rec, http.StatusOK)
+	id := decode[models.ImportReport](t, rec).Rows[0].ID
+	imported := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(id), "", ""))
+	if imported.Enrichment == nil || imported.Enrichment.Status != models.EnrichmentImported {
+		t.Errorf("imported song enrichment = %+v, want imported", imported.Enrichment)
+	}
+
+	rec = serve(t, router, http.MethodPost, songPath(id, "/enrich"), "", "")
+	expectStatus(t, rec, http.StatusAccepted)
+	if queued := decode[models.Songs](t, rec); queued.Enrichment == nil || queued.Enrichment.Status != models.EnrichmentPending || queued.Enrichment.Attempts != 0 {
+		t.Errorf("queued song enrichment = %+v, want pending without attempts", queued.Enrichment)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(999, "/enrich"), "", ""), http.StatusNotFound)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Ktuty/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+//	@Summary		Get all genres
+//	@Description	Get a page of genres with the number of songs of each genre, most used first
+//	@Tags			tags
+//	@Produce		json
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Param			name		query		string	false	"Genre name substring"
+//	@Success		200			{object}	models.GenresPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/genres [get]
+func (h *Handler) Genres(w http.ResponseWriter, r *http.Request) {
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	name := r.URL.Query().Get("name")
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+		"name":     name,
+	}).Info("Genres: parameters")
+
+	response, err := h.services.GetAllGenres(r.Context(), name, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении жанров")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Get all tags
+//	@Description	Get a page of tags with the number of songs of each tag, most used first
+//	@Tags			tags
+//	@Produce		json
+//	@Param			page		query		int		false	"Page number"	default(1)
+//	@Param			pageSize	query		int		false	"Page size"		default(10)
+//	@Param			name		query		string	false	"Tag name substring"
+//	@Success		200			{object}	models.TagsPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/tags [get]
+func (h *Handler) Tags(w http.ResponseWriter, r *http.Request) {
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	name := r.URL.Query().Get("name")
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+		"name":     name,
+	}).Info("Tags: parameters")
+
+	response, err := h.services.GetAllTags(r.Context(), name, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении тегов")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Attach genres to a song
+//	@Description	Attach genres to a song. Names are case-insensitive; unknown genres are created.
+//	@Tags			tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Song ID"
+//	@Param			genres	body		models.GenresRequest	true	"Genre names"
+//	@Success		200		{object}	models.Songs
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/songs/{id}/genres [post]
+func (h *Handler) NewSongGenres(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.GenresRequest
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	song, err := h.services.AddSongGenres(r.Context(), songID, request)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при добавлении жанров песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, song)
+}
+
+//	@Summary		Attach tags to a song
+//	@Description	Attach free-form tags to a song. Names are case-insensitive; unknown tags are created.
+//	@Tags			tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Song ID"
+//	@Param			tags	body		models.TagsRequest	true	"Tag names"
+//	@Success		200		{object}	models.Songs
+//	@Failure		400		{object}	models.ErrorResponse
+//	@Failure		404		{object}	models.ErrorResponse
+//	@Failure		422		{object}	models.ErrorResponse
+//	@Failure		500		{object}	models.ErrorResponse
+//	@Router			/songs/{id}/tags [post]
+func (h *Handler) NewSongTags(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.TagsRequest
+	defer r.Body.Close()
+
+	// Декодирование JSON из тела запроса
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.WithError(err).Error("Ошибка при декодировании JSON")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	song, err := h.services.AddSongTags(r.Context(), songID, request)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при добавлении тегов песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, song)
+}
+
+//	@Summary		Detach a genre from a song
+//	@Description	Detach a genre from a song by the genre name
+//	@Tags			tags
+//	@Produce		json
+//	@Param			id		path	int		true	"Song ID"
+//	@Param			genre	path	string	true	"Genre name"
+//	@Success		200
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/songs/{id}/genres/{genre} [delete]
+func (h *Handler) DeleteSongGenre(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.DeleteSongGenre(r.Context(), songID, mux.Vars(r)["genre"]); err != nil {
+		logrus.WithError(err).Error("Ошибка при удалении жанра песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Summary		Detach a tag from a song
+//	@Description	Detach a tag from a song by the tag name
+//	@Tags			tags
+//	@Produce		json
+//	@Param			id	path	int		true	"Song ID"
+//	@Param			tag	path	string	true	"Tag name"
+//	@Success		200
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/songs/{id}/tags/{tag} [delete]
+func (h *Handler) DeleteSongTag(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.DeleteSongTag(r.Context(), songID, mux.Vars(r)["tag"]); err != nil {
+		logrus.WithError(err).Error("Ошибка при удалении тега песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Функция для получения ID песни из переменных маршрута; при ошибке ответ уже записан
+func songIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при преобразовании songID в int")
+		newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	logrus.WithField("songID", songID).Info("Song request: songID")
+	return songID, true
+}
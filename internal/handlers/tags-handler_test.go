@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Функция для получения имён жанров или тегов страницы
+func labelNames(labels []models.Label) string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSongGenresAndTags(t *testing.T) {
+	router := newTestRouter(t)
+	uprising := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	madness := createSong(t, router, `{"group":"Muse","song":"Madness"}`)
+
+	rec := serve(t, router, http.MethodPost, songPath(uprising.ID, "/genres"), "application/json", `{"genres":["Alternative  Rock","electronic"]}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[models.Songs](t, rec).Genres; strings.Join(got, ",") != "alternative rock,electronic" {
+		t.Errorf("genres = %v", got)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(madness.ID, "/genres"), "application/json", `{"genres":["alternative rock"]}`), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(uprising.ID, "/tags"), "application/json", `{"tags":["Protest","live"]}`), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(madness.ID, "/tags"), "application/json", `{"tags":["live"]}`), http.StatusOK)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "genre=electronic,alternative+rock", want: "Uprising,Madness"},
+		{query: "genre=electronic,alternative+rock&genreMode=all", want: "Uprising"},
+		{query: "tag=LIVE", want: "Uprising,Madness"},
+		{query: "tag=protest,live&tagMode=all", want: "Uprising"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := serve(t, router, http.MethodGet, "/songs?"+tt.query, "", "")
+			expectStatus(t, rec, http.StatusOK)
+			if got := songTitles(decode[models.SongsPage](t, rec).Songs); got != tt.want {
+				t.Errorf("songs = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	genres := decode[models.GenresPage](t, serve(t, router, http.MethodGet, "/genres", "", ""))
+	if labelNames(genres.Genres) != "alternative rock,electronic" || genres.Genres[0].SongCount != 2 {
+		t.Errorf("genres = %+v", genres.Genres)
+	}
+
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(uprising.ID, "/tags/Protest"), "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(uprising.ID, "/genres/Electronic"), "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(uprising.ID, "/tags/unknown"), "", ""), http.StatusNotFound)
+	song := decode[models.Songs](t, serve(t, router, http.MethodGet, songPath(uprising.ID), "", ""))
+	if strings.Join(song.Genres, ",") != "alternative rock" || strings.Join(song.Tags, ",") != "live" {
+		t.Errorf("labels after delete = %v, %v", song.Genres, song.Tags)
+	}
+
+	tags := decode[models.TagsPage](t, serve(t, router, http.MethodGet, "/tags", "", ""))
+	if labelNames(tags.Tags) != "live,protest" || tags.Tags[0].SongCount != 2 || tags.Tags[1].SongCount != 0 {
+		t.Errorf("tags = %+v, want live on 2 songs and unused protest", tags.Tags)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(uprising.ID, "/tags"), "application/json", `{"tags":[" "]}`), http.StatusUnprocessableEntity)
+}
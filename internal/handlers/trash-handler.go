@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+//	@Summary		Get the trash
+//	@Description	Get a page of deleted songs with their deletion time. Songs stay in the trash until restored or purged after the retention period.
+//	@Tags			trash
+//	@Produce		json
+//	@Param			page		query		int	false	"Page number"	default(1)
+//	@Param			pageSize	query		int	false	"Page size"		default(10)
+//	@Success		200			{object}	models.SongsPage
+//	@Failure		422			{object}	models.ErrorResponse
+//	@Failure		500			{object}	models.ErrorResponse
+//	@Router			/trash [get]
+func (h *Handler) Trash(w http.ResponseWriter, r *http.Request) {
+	page := getQueryParamAsInt(r, "page", 1)
+	pageSize := getQueryParamAsInt(r, "pageSize", 10)
+	logrus.WithFields(logrus.Fields{
+		"page":     page,
+		"pageSize": pageSize,
+	}).Info("Trash: parameters")
+
+	response, err := h.services.Trash(r.Context(), page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении корзины")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//	@Summary		Restore a song
+//	@Description	Restore a deleted song from the trash
+//	@Tags			trash
+//	@Produce		json
+//	@Param			id	path		int	true	"Song ID"
+//	@Success		200	{object}	models.Songs
+//	@Failure		400	{object}	models.ErrorResponse
+//	@Failure		404	{object}	models.ErrorResponse
+//	@Failure		500	{object}	models.ErrorResponse
+//	@Router			/songs/{id}/restore [post]
+func (h *Handler) RestoreSong(w http.ResponseWriter, r *http.Request) {
+	songID, ok := songIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	song, err := h.services.Restore(r.Context(), songID)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при восстановлении песни")
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, song)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+	"github.com/Ktuty/internal/services"
+)
+
+func TestTrash(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	router := newTestRouterWithRepo(t, repo)
+	uprising := createSong(t, router, `{"group":"Muse","song":"Uprising"}`)
+	madness := createSong(t, router, `{"group":"Muse","song":"Madness"}`)
+
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(uprising.ID), "", ""), http.StatusOK)
+	expectStatus(t, serve(t, router, http.MethodDelete, songPath(madness.ID), "", ""), http.StatusOK)
+	trash := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/trash", "", ""))
+	if songTitles(trash.Songs) != "Uprising,Madness" || trash.Songs[0].DeletedAt == nil {
+		t.Errorf("trash = %+v", trash.Songs)
+	}
+	if page := decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/songs", "", "")); len(page.Songs) != 0 {
+		t.Errorf("deleted songs are listed: %s", songTitles(page.Songs))
+	}
+
+	rec := serve(t, router, http.MethodPost, songPath(uprising.ID, "/restore"), "", "")
+	expectStatus(t, rec, http.StatusOK)
+	if song := decode[models.Songs](t, rec); song.DeletedAt != nil {
+		t.Errorf("restored song deletedAt = %v", song.DeletedAt)
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(uprising.ID, "/restore"), "", ""), http.StatusNotFound)
+
+	// Название песни в корзине можно занять, и тогда восстановление отклоняется
+	createSong(t, router, `{"group":"Muse","song":"Madness"}`)
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(madness.ID, "/restore"), "", ""), http.StatusConflict)
+
+	// Песни в корзине младше срока хранения не удаляются
+	services.NewPurger(repo, time.Hour, time.Hour).Purge(context.Background())
+	if trash = decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/trash", "", "")); songTitles(trash.Songs) != "Madness" {
+		t.Errorf("trash after purge with retention = %s, want Madness", songTitles(trash.Songs))
+	}
+	services.NewPurger(repo, -time.Second, time.Hour).Purge(context.Background())
+	if trash = decode[models.SongsPage](t, serve(t, router, http.MethodGet, "/trash", "", "")); len(trash.Songs) != 0 {
+		t.Errorf("trash after purge = %s, want empty", songTitles(trash.Songs))
+	}
+	expectStatus(t, serve(t, router, http.MethodPost, songPath(madness.ID, "/restore"), "", ""), http.StatusNotFound)
+}
@@ -0,0 +1,45 @@
+package models
+
+// Album represents an album of a group with its ordered tracks.
+type Album struct {
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	GroupID     int          `json:"groupId"`
+	Group       string       `json:"group"`
+	ReleaseDate string       `json:"releaseDate"`
+	CoverLink   string       `json:"coverLink"`
+	Tracks      []AlbumTrack `json:"tracks,omitempty"`
+}
+
+// AlbumTrack is a song placed on an album under a track number.
+type AlbumTrack struct {
+	TrackNumber int    `json:"trackNumber"`
+	SongID      int    `json:"songId"`
+	Song        string `json:"song,omitempty"`
+}
+
+// AlbumTracksRequest replaces the track list of an album.
+type AlbumTracksRequest struct {
+	Tracks []AlbumTrack `json:"tracks"`
+}
+
+// AlbumFilter describes filtering of the albums list; fields are matched as case-insensitive substrings.
+type AlbumFilter struct {
+	Title string
+	Group string
+}
+
+// AlbumsPage represents a page of the albums list.
+type AlbumsPage struct {
+	Albums      []Album `json:"albums"`
+	TotalPages  int     `json:"totalPages"`
+	CurrentPage int     `json:"currentPage"`
+	PageSize    int     `json:"pageSize"`
+}
+
+// SongAlbum is the album of a song as shown in the songs list.
+type SongAlbum struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	TrackNumber int    `json:"trackNumber,omitempty"`
+}
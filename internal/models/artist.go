@@ -0,0 +1,20 @@
+package models
+
+// Roles under which a group is credited on a song.
+const (
+	ArtistRolePrimary  = "primary"
+	ArtistRoleFeatured = "featured"
+	ArtistRoleComposer = "composer"
+	ArtistRoleLyricist = "lyricist"
+)
+
+// ArtistRoles lists the roles in display order; the primary artist always comes first.
+var ArtistRoles = []string{ArtistRolePrimary, ArtistRoleFeatured, ArtistRoleComposer, ArtistRoleLyricist}
+
+// SongArtist is a group credited on a song under a role.
+// In requests the group is given by name and GroupID is ignored.
+type SongArtist struct {
+	GroupID int    `json:"groupId,omitempty"`
+	Group   string `json:"group"`
+	Role    string `json:"role"`
+}
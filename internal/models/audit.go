@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entities recorded in the audit log.
+const (
+	AuditEntitySong  = "song"
+	AuditEntityGroup = "group"
+	AuditEntityAlbum = "album"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionRevert  = "revert"
+	AuditActionMerge   = "merge"
+	AuditActionEnrich  = "enrich"
+)
+
+// AuditEntry is an append-only record of a change made through the API.
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Entity    string    `json:"entity"`
+	EntityID  int       `json:"entityId"`
+	// Before and After are the entity as returned by the API; Before is empty on create, After on delete.
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	RequestID string          `json:"requestId"`
+	ClientIP  string          `json:"clientIp"`
+}
+
+// AuditFilter restricts the audit log; zero fields do not filter.
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  int
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditPage represents a page of the audit log, newest first.
+type AuditPage struct {
+	Entries     []AuditEntry `json:"entries"`
+	TotalPages  int          `json:"totalPages"`
+	CurrentPage int          `json:"currentPage"`
+	PageSize    int          `json:"pageSize"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// Cursor is the decoded keyset pagination position: the sort used for the list
+// and the sort key values of the boundary song.
+type Cursor struct {
+	Sort        string `json:"sort"`
+	Backward    bool   `json:"backward,omitempty"`
+	ID          int    `json:"id"`
+	Song        string `json:"song,omitempty"`
+	Group       string `json:"group,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"` // YYYY-MM-DD, empty for an unknown date
+	Link        string `json:"link,omitempty"`
+}
+
+// SongsCursorPage represents a page of the songs list in cursor mode.
+type SongsCursorPage struct {
+	Songs      []Songs `json:"songs"`
+	Limit      int     `json:"limit"`
+	Sort       string  `json:"sort"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+	PrevCursor string  `json:"prevCursor,omitempty"`
+	TotalCount *int    `json:"totalCount,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+// DuplicateSong identifies one song of a near-duplicate pair.
+type DuplicateSong struct {
+	ID    int    `json:"id"`
+	Song  string `json:"song"`
+	Group string `json:"group"`
+}
+
+// SongDuplicate is a pair of songs with similar lyrics; First has the lower id.
+type SongDuplicate struct {
+	First  DuplicateSong `json:"first"`
+	Second DuplicateSong `json:"second"`
+	// Similarity is the trigram similarity of the lyrics, from 0 to 1.
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicatesPage represents a page of the near-duplicates report, most similar pairs first.
+// The total number of pairs is not counted; HasMore tells whether a next page exists.
+type DuplicatesPage struct {
+	Duplicates  []SongDuplicate `json:"duplicates"`
+	Threshold   float64         `json:"threshold"`
+	HasMore     bool            `json:"hasMore"`
+	CurrentPage int             `json:"currentPage"`
+	PageSize    int             `json:"pageSize"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Enrichment statuses of a song whose details are fetched from the external API in the background.
+const (
+	// EnrichmentPending songs wait for a worker or for the next retry.
+	EnrichmentPending = "pending"
+	// EnrichmentEnriched songs got their details from the external API.
+	EnrichmentEnriched = "enriched"
+	// EnrichmentFailed songs ran out of attempts or got details that are not valid.
+	EnrichmentFailed = "failed"
+	// EnrichmentImported songs came from a catalog import and were not sent to the external API;
+	// POST /songs/{id}/enrich queues them.
+	EnrichmentImported = "imported"
+)
+
+// SongEnrichment is the state of fetching the details of a song from the external API.
+type SongEnrichment struct {
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// Error is the last failure, kept while retrying and after the enrichment failed.
+	Error string `json:"error,omitempty"`
+	// NextAttemptAt is when a pending song is fetched next.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// EnrichmentJob is a pending song claimed by an enrichment worker.
+type EnrichmentJob struct {
+	SongID   int
+	Group    string
+	Song     string
+	Attempts int
+}
@@ -2,6 +2,15 @@ package models
 
 // ErrorResponse represents the structure of an error response.
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	// ExistingID is the id of the song that a rejected duplicate conflicts with.
+	ExistingID int `json:"existingId,omitempty"`
+}
+
+// FieldError describes a single invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
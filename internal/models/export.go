@@ -0,0 +1,11 @@
+package models
+
+// Formats of the songs catalog export.
+const (
+	// ExportFormatCSV writes a header and one row per song with the columns of the CSV import plus id.
+	ExportFormatCSV = "csv"
+	// ExportFormatNDJSON writes one song object per line (JSON Lines).
+	ExportFormatNDJSON = "ndjson"
+	// ExportFormatJSON writes a single JSON array of song objects.
+	ExportFormatJSON = "json"
+)
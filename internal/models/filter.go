@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// SongFilter describes filtering of the songs list.
+// Text fields are matched as case-insensitive substrings, zero dates disable the bound.
+// Group matches any credited artist of the song, not only the primary group.
+// With Fuzzy set, Song and Group also match names with a similar spelling (trigram similarity).
+type SongFilter struct {
+	Song            string
+	Group           string
+	Text            string
+	ReleaseDate     string
+	Link            string
+	Album           string // album title; a non-empty filter excludes songs without an album
+	Genres          LabelFilter
+	Tags            LabelFilter
+	ReleaseDateFrom time.Time
+	ReleaseDateTo   time.Time
+	Fuzzy           bool
+	GroupID         int  // primary group; 0 disables the filter
+	Deleted         bool // only songs in the trash; otherwise deleted songs are hidden
+}
+
+// HasNameFilter reports whether the filter restricts the song title or the group name.
+func (f SongFilter) HasNameFilter() bool {
+	return f.Song != "" || f.Group != ""
+}
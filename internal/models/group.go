@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Group represents a performer that songs belong to.
+type Group struct {
+	ID        int          `json:"id"`
+	Name      string       `json:"name"`
+	SongCount int          `json:"songCount"`
+	Aliases   []GroupAlias `json:"aliases"`
+}
+
+// GroupsPage represents a page of the groups list.
+type GroupsPage struct {
+	Groups      []Group `json:"groups"`
+	TotalPages  int     `json:"totalPages"`
+	CurrentPage int     `json:"currentPage"`
+	PageSize    int     `json:"pageSize"`
+}
+
+// NormalizeGroupName trims the name and collapses runs of whitespace into single spaces.
+func NormalizeGroupName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// GroupKey returns the identity of a group name: two names with the same key denote
+// the same group. Case, whitespace and diacritics are folded ("  Beyoncé " and "beyonce").
+func GroupKey(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+	return strings.ToLower(NormalizeGroupName(folded))
+}
+
+// GroupAlias is an alternative spelling of a group name.
+type GroupAlias struct {
+	ID    int    `json:"id"`
+	Alias string `json:"alias"`
+}
+
+// GroupMergeRequest lists the groups to merge into the target group.
+type GroupMergeRequest struct {
+	Sources []int `json:"sources"`
+}
+
+// GroupMerge reports the result of merging groups.
+type GroupMerge struct {
+	Group      Group `json:"group"`
+	MovedSongs int   `json:"movedSongs"`
+}
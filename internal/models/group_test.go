@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Muse", want: "muse"},
+		{name: "  Beyoncé ", want: "beyonce"},
+		{name: "Guns  N'\tRoses", want: "guns n' roses"},
+		{name: "Ёлка", want: "елка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GroupKey(tt.name); got != tt.want {
+				t.Errorf("GroupKey(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if got := NormalizeGroupName("  Guns  N'\tRoses "); got != "Guns N' Roses" {
+		t.Errorf("NormalizeGroupName = %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+// Statuses of an imported catalog row.
+const (
+	ImportInserted = "inserted"
+	ImportSkipped  = "skipped"
+	ImportFailed   = "failed"
+	// ImportNotProcessed marks a valid row that was not saved because the import stopped.
+	ImportNotProcessed = "not_processed"
+)
+
+// ImportRow reports the outcome of one row of an imported catalog.
+type ImportRow struct {
+	// Line is the line of the row in the request body, starting from 1.
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	// ID is the id of the inserted song (in a dry run, the id it would have got).
+	ID int `json:"id,omitempty"`
+	// ExistingID is the id of the song with the same group and title that caused the row to be skipped.
+	ExistingID int          `json:"existingId,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Fields     []FieldError `json:"fields,omitempty"`
+}
+
+// ImportReport summarizes an import of a song catalog.
+type ImportReport struct {
+	DryRun   bool `json:"dryRun"`
+	Inserted int  `json:"inserted"`
+	Skipped  int  `json:"skipped"`
+	Failed   int  `json:"failed"`
+	// NotProcessed counts the valid rows read before the import stopped that were not saved.
+	NotProcessed int         `json:"notProcessed,omitempty"`
+	Rows         []ImportRow `json:"rows"`
+	// Error is set when the import stopped: the rows read before it keep their status
+	// or are not_processed, the rest of the catalog is not read.
+	Error string `json:"error,omitempty"`
+}
@@ -3,4 +3,6 @@ package models
 type Params struct {
 	Group string `json:"group"`
 	Song  string `json:"song"`
+	// Artists are the additional credits of the song: featured artists, composers and lyricists.
+	Artists []SongArtist `json:"artists,omitempty"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReleaseDateLayout - формат даты выпуска, в котором её возвращает внешний API
+const ReleaseDateLayout = "02.01.2006"
+
+// releaseDateLayouts - все принимаемые форматы даты выпуска: формат внешнего API и ISO 8601
+var releaseDateLayouts = []string{ReleaseDateLayout, time.DateOnly}
+
+// ParseReleaseDate разбирает дату выпуска в любом из поддерживаемых форматов
+func ParseReleaseDate(value string) (time.Time, error) {
+	for _, layout := range releaseDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid release date %q: expected DD.MM.YYYY or YYYY-MM-DD", value)
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operations of a line in a revision text diff.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// SongSnapshot is the full state of a song's lyrics and metadata stored in a revision.
+type SongSnapshot struct {
+	Song        string `json:"song"`
+	Group       string `json:"group"`
+	Text        string `json:"text"`
+	ReleaseDate string `json:"releaseDate"`
+	Link        string `json:"link"`
+	// Artists are the non-primary credits; the primary artist is Group.
+	Artists []SongArtist `json:"artists"`
+	// Genres and Tags are nil in revisions recorded before labels were kept in snapshots;
+	// reverting to such a revision leaves the current genres, tags and album as they are.
+	Genres []string `json:"genres"`
+	Tags   []string `json:"tags"`
+	// Album is nil when the song was not on an album.
+	Album *SongAlbum `json:"album,omitempty"`
+}
+
+// NewSongSnapshot takes a snapshot of the song's lyrics and metadata.
+func NewSongSnapshot(song Songs) SongSnapshot {
+	snapshot := SongSnapshot{
+		Song:        song.Song,
+		Group:       song.Group,
+		Text:        song.Text,
+		ReleaseDate: song.ReleaseDate,
+		Link:        song.Link,
+		Artists:     []SongArtist{},
+		Genres:      append([]string{}, song.Genres...),
+		Tags:        append([]string{}, song.Tags...),
+	}
+	if song.Album != nil {
+		album := *song.Album
+		snapshot.Album = &album
+	}
+	for _, artist := range song.Artists {
+		if artist.Role != ArtistRolePrimary {
+			snapshot.Artists = append(snapshot.Artists, SongArtist{Group: artist.Group, Role: artist.Role})
+		}
+	}
+	return snapshot
+}
+
+// ToSong returns the song fields stored in the snapshot.
+func (s SongSnapshot) ToSong() Songs {
+	artists := append([]SongArtist{}, s.Artists...)
+	return Songs{
+		Song:        s.Song,
+		Group:       s.Group,
+		Text:        s.Text,
+		ReleaseDate: s.ReleaseDate,
+		Link:        s.Link,
+		Artists:     artists,
+	}
+}
+
+// FormatArtists renders the credits as "group (role)" separated by commas.
+func (s SongSnapshot) FormatArtists() string {
+	credits := make([]string, 0, len(s.Artists))
+	for _, artist := range s.Artists {
+		credits = append(credits, artist.Group+" ("+artist.Role+")")
+	}
+	return strings.Join(credits, ", ")
+}
+
+// HasLabels reports whether the snapshot keeps the genres, tags and album of the song.
+func (s SongSnapshot) HasLabels() bool {
+	return s.Genres != nil
+}
+
+// FormatAlbum renders the album as "title #track"; empty when the song was not on an album.
+func (s SongSnapshot) FormatAlbum() string {
+	if s.Album == nil {
+		return ""
+	}
+	if s.Album.TrackNumber == 0 {
+		return s.Album.Title
+	}
+	return s.Album.Title + " #" + strconv.Itoa(s.Album.TrackNumber)
+}
+
+// Revision is a stored version of a song, numbered from 1 per song.
+type Revision struct {
+	SongID    int       `json:"songId"`
+	Revision  int       `json:"revision"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+	// RevertedFrom is set when the revision was created by reverting to an earlier one.
+	RevertedFrom *int `json:"revertedFrom,omitempty"`
+	// Snapshot is omitted in the revisions list.
+	Snapshot *SongSnapshot `json:"snapshot,omitempty"`
+}
+
+// RevisionsPage represents a page of a song's revisions, newest first.
+type RevisionsPage struct {
+	Revisions   []Revision `json:"revisions"`
+	TotalPages  int        `json:"totalPages"`
+	CurrentPage int        `json:"currentPage"`
+	PageSize    int        `json:"pageSize"`
+}
+
+// RevisionDiff describes the changes between two revisions of a song.
+type RevisionDiff struct {
+	SongID int `json:"songId"`
+	From   int `json:"from"`
+	To     int `json:"to"`
+	// Fields lists the changed metadata fields other than the text.
+	Fields []FieldChange `json:"fields"`
+	// Text is the line-based diff of the lyrics, unchanged lines included.
+	Text []DiffLine `json:"text"`
+}
+
+// FieldChange is a metadata field that differs between two revisions.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DiffLine is a line of the text diff: equal, insert or delete.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Line string `json:"line"`
+}
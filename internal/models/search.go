@@ -0,0 +1,22 @@
+package models
+
+// SearchResult represents a song found by the lyrics search.
+type SearchResult struct {
+	Songs
+	Rank     float32   `json:"rank"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Snippet is a highlighted fragment of a verse that matches the search query.
+type Snippet struct {
+	Verse int    `json:"verse"`
+	Text  string `json:"text"`
+}
+
+// SearchPage represents a page of lyrics search results.
+type SearchPage struct {
+	Results     []SearchResult `json:"results"`
+	TotalPages  int            `json:"totalPages"`
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Songs struct {
 	ID          int    `json:"id"`
 	Song        string `json:"song"`
@@ -7,4 +9,39 @@ type Songs struct {
 	Text        string `json:"text"`
 	ReleaseDate string `json:"releaseDate"`
 	Link        string `json:"link"`
+	// Artists lists every credited group, the primary one (Group) first.
+	// In create and update requests it sets the non-primary credits; nil leaves them unchanged on update.
+	Artists []SongArtist `json:"artists,omitempty"`
+	// Genres and Tags are the names of the attached genres and tags, sorted by name.
+	// They are read-only here and changed through the song genres and tags endpoints.
+	Genres []string `json:"genres,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// Album is set when the song belongs to an album.
+	Album *SongAlbum `json:"album,omitempty"`
+	// DeletedAt is set for songs in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Version grows with every change of the song representation, including the group and album names
+	// and the enrichment status, attempts and error, and is sent as its ETag. Rescheduling an enrichment attempt
+	// does not change it. It is ignored in requests.
+	Version int `json:"version,omitempty"`
+	// Enrichment is the state of fetching the song details from the external API. It is ignored in requests.
+	Enrichment *SongEnrichment `json:"enrichment,omitempty"`
+	// Score is the similarity to the fuzzy song and group filters, set only in fuzzy mode.
+	Score *float32 `json:"score,omitempty"`
+}
+
+// SongKey returns the identity of a song title within its group: a group cannot have two
+// songs with the same key. It is folded the same way as GroupKey.
+func SongKey(title string) string {
+	return GroupKey(title)
 }
+
+// Policies for creating a song whose group already has a song with the same title.
+const (
+	// OnConflictError rejects the new song with a conflict pointing at the existing one.
+	OnConflictError = "error"
+	// OnConflictSkip keeps the existing song unchanged and returns it.
+	OnConflictSkip = "skip"
+	// OnConflictUpdate updates the existing song with the non-empty fields of the new one.
+	OnConflictUpdate = "update"
+)
@@ -0,0 +1,18 @@
+package models
+
+// SongsPage represents a page of the songs list in page mode.
+type SongsPage struct {
+	Songs       []Songs     `json:"songs"`
+	TotalPages  int         `json:"totalPages"`
+	CurrentPage int         `json:"currentPage"`
+	PageSize    int         `json:"pageSize"`
+	Sort        string      `json:"sort"`
+	DidYouMean  *Suggestion `json:"didYouMean,omitempty"`
+}
+
+// Suggestion holds the closest existing song title and group name
+// for song and group filters that have no exact hits.
+type Suggestion struct {
+	Song  string `json:"song,omitempty"`
+	Group string `json:"group,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "strings"
+
+// Song list sort keys accepted by the API.
+const (
+	SortByID          = "id"
+	SortBySong        = "song"
+	SortByGroup       = "group"
+	SortByReleaseDate = "releaseDate"
+	SortByLink        = "link"
+)
+
+// SongSortKeys lists the whitelisted sort keys of the songs list.
+var SongSortKeys = []string{SortByID, SortBySong, SortByGroup, SortByReleaseDate, SortByLink}
+
+// SortField describes one sort key and its direction.
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// String formats the field as in the sort query parameter: "key" or "-key".
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Key
+	}
+	return f.Key
+}
+
+// FormatSort formats the fields as a comma-separated sort query parameter.
+func FormatSort(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field.String())
+	}
+	return strings.Join(parts, ",")
+}
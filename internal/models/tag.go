@@ -0,0 +1,47 @@
+package models
+
+import "strings"
+
+// Label is a genre or a free-form tag with the number of songs it is attached to.
+type Label struct {
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+}
+
+// GenresPage represents a page of the genres list.
+type GenresPage struct {
+	Genres      []Label `json:"genres"`
+	TotalPages  int     `json:"totalPages"`
+	CurrentPage int     `json:"currentPage"`
+	PageSize    int     `json:"pageSize"`
+}
+
+// TagsPage represents a page of the tags list.
+type TagsPage struct {
+	Tags        []Label `json:"tags"`
+	TotalPages  int     `json:"totalPages"`
+	CurrentPage int     `json:"currentPage"`
+	PageSize    int     `json:"pageSize"`
+}
+
+// GenresRequest attaches genres to a song.
+type GenresRequest struct {
+	Genres []string `json:"genres"`
+}
+
+// TagsRequest attaches tags to a song.
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// LabelFilter restricts songs by genres or tags: a song matches when it has any of Names,
+// or all of them when All is set. An empty filter matches every song.
+type LabelFilter struct {
+	Names []string
+	All   bool
+}
+
+// NormalizeLabel lowercases a genre or tag name and collapses runs of whitespace into single spaces.
+func NormalizeLabel(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для получения альбомов с фильтрацией, пагинацией и возвратом общего количества страниц
+func (m *SongsMemory) GetAllAlbums(ctx context.Context, filter models.AlbumFilter, page, pageSize int) ([]models.Album, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllAlbums invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllAlbums: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	var matched []int
+	for _, id := range m.sortedAlbumIDs() {
+		album := m.albums[id]
+		if ilike(album.title, "%"+filter.Title+"%") && ilike(m.groups[album.groupID], "%"+filter.Group+"%") {
+			matched = append(matched, id)
+		}
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (len(matched) + pageSize - 1) / pageSize
+
+	albums := []models.Album{}
+	for i := offset; i < len(matched) && i < offset+pageSize; i++ {
+		albums = append(albums, m.toAlbum(matched[i], false))
+	}
+
+	return albums, totalPages, nil
+}
+
+// Метод для получения альбома по ID вместе со списком треков
+func (m *SongsMemory) GetAlbumByID(ctx context.Context, id int) (models.Album, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Album{}, fmt.Errorf("SongsMemory.GetAlbumByID: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	if _, ok := m.albums[id]; !ok {
+		logrus.WithField("id", id).Info("Album not found")
+		return models.Album{}, fmt.Errorf("album with id %d %w", id, ErrNotFound)
+	}
+
+	return m.toAlbum(id, true), nil
+}
+
+// Метод для создания нового альбома; группа находится или создаётся по имени, как для песен
+func (m *SongsMemory) CreateAlbum(ctx context.Context, album models.Album) (models.Album, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Album{}, fmt.Errorf("SongsMemory.CreateAlbum: %w", err)
+	}
+
+	releaseDate, err := parseMemoryReleaseDate(album.ReleaseDate)
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	defer m.lock(ctx)()
+
+	groupID := m.ensureGroupExists(album.Group)
+	if m.albumIDByTitle(groupID, album.Title) != 0 {
+		return models.Album{}, fmt.Errorf("error inserting album: %w: album %q already exists", ErrConflict, album.Title)
+	}
+
+	id := m.nextAlbumID
+	m.albums[id] = albumRecord{groupID: groupID, title: album.Title, releaseDate: releaseDate, coverLink: album.CoverLink}
+	m.nextAlbumID++
+
+	return m.toAlbum(id, true), nil
+}
+
+// Метод для обновления альбома по ID; пустые поля не изменяются
+func (m *SongsMemory) UpdateAlbum(ctx context.Context, id int, album models.Album) (models.Album, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Album{}, fmt.Errorf("SongsMemory.UpdateAlbum: %w", err)
+	}
+
+	releaseDate, err := parseMemoryReleaseDate(album.ReleaseDate)
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.albums[id]
+	if !ok {
+		logrus.WithField("id", id).Info("Album not found")
+		return models.Album{}, fmt.Errorf("album with id %d %w", id, ErrNotFound)
+	}
+
+	if album.Title != "" {
+		if other := m.albumIDByTitle(record.groupID, album.Title); other != 0 && other != id {
+			return models.Album{}, fmt.Errorf("error updating album with id %d: %w: album %q already exists", id, ErrConflict, album.Title)
+		}
+		record.title = album.Title
+		// Название альбома входит в представление его песен
+		for songID, song := range m.songs {
+			if song.albumID == id {
+				song.version++
+				m.songs[songID] = song
+			}
+		}
+	}
+	if album.ReleaseDate != "" {
+		record.releaseDate = releaseDate
+	}
+	if album.CoverLink != "" {
+		record.coverLink = album.CoverLink
+	}
+	m.albums[id] = record
+
+	return m.toAlbum(id, true), nil
+}
+
+// Метод для удаления альбома по ID; песни альбома остаются без альбома и номера трека
+func (m *SongsMemory) DeleteAlbum(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.DeleteAlbum: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if _, ok := m.albums[id]; !ok {
+		logrus.WithField("id", id).Info("Album not found")
+		return fmt.Errorf("album with id %d %w", id, ErrNotFound)
+	}
+
+	m.clearTracks(id)
+	delete(m.albums, id)
+
+	return nil
+}
+
+// Метод для замены списка треков альбома, как в AlbumsRepository
+func (m *SongsMemory) SetAlbumTracks(ctx context.Context, id int, tracks []models.AlbumTrack) (models.Album, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Album{}, fmt.Errorf("SongsMemory.SetAlbumTracks: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	album, ok := m.albums[id]
+	if !ok {
+		logrus.WithField("id", id).Info("Album not found")
+		return models.Album{}, fmt.Errorf("album with id %d %w", id, ErrNotFound)
+	}
+
+	// Все песни проверяются до изменений, так как вне транзакции откатить их нельзя
+	for _, track := range tracks {
+		record, ok := m.songs[track.SongID]
+		if !ok || !record.deletedAt.IsZero() {
+			logrus.WithField("songID", track.SongID).Info("Song not found")
+			return models.Album{}, fmt.Errorf("song with id %d %w", track.SongID, ErrNotFound)
+		}
+		if record.groupID != album.groupID {
+			return models.Album{}, fmt.Errorf("song with id %d belongs to another group: %w", track.SongID, ErrConflict)
+		}
+	}
+
+	m.clearTracks(id)
+	for _, track := range tracks {
+		record := m.songs[track.SongID]
+		record.albumID, record.trackNumber = id, track.TrackNumber
+		record.version++
+		m.songs[track.SongID] = record
+	}
+
+	return m.toAlbum(id, true), nil
+}
+
+// Метод для помещения песни в альбом под номером трека, как в AlbumsRepository
+func (m *SongsMemory) SetSongAlbum(ctx context.Context, songID int, album *models.SongAlbum) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.SetSongAlbum: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	if album == nil {
+		record.albumID, record.trackNumber = 0, 0
+	} else {
+		stored, ok := m.albums[album.ID]
+		if !ok {
+			logrus.WithField("id", album.ID).Info("Album not found")
+			return fmt.Errorf("album with id %d %w", album.ID, ErrNotFound)
+		}
+		if stored.groupID != record.groupID {
+			return fmt.Errorf("song with id %d belongs to another group: %w", songID, ErrConflict)
+		}
+		// Номер трека уникален в альбоме, как ограничение в таблице songs
+		for id, other := range m.songs {
+			if id != songID && other.albumID == album.ID && other.trackNumber == album.TrackNumber {
+				return fmt.Errorf("track %d of album with id %d is taken: %w", album.TrackNumber, album.ID, ErrConflict)
+			}
+		}
+		record.albumID, record.trackNumber = album.ID, album.TrackNumber
+	}
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для исключения всех песен из альбома
+func (m *SongsMemory) clearTracks(id int) {
+	for songID, record := range m.songs {
+		if record.albumID == id {
+			record.albumID, record.trackNumber = 0, 0
+			record.version++
+			m.songs[songID] = record
+		}
+	}
+}
+
+// Метод для поиска альбома группы по названию без учёта регистра; 0, если альбома нет
+func (m *SongsMemory) albumIDByTitle(groupID int, title string) int {
+	for id, album := range m.albums {
+		if album.groupID == groupID && strings.EqualFold(album.title, title) {
+			return id
+		}
+	}
+	return 0
+}
+
+// Метод для преобразования альбома хранилища в модель альбома, при withTracks - со списком треков
+func (m *SongsMemory) toAlbum(id int, withTracks bool) models.Album {
+	record := m.albums[id]
+	album := models.Album{
+		ID:          id,
+		Title:       record.title,
+		GroupID:     record.groupID,
+		Group:       m.groups[record.groupID],
+		ReleaseDate: formatMemoryReleaseDate(record.releaseDate),
+		CoverLink:   record.coverLink,
+	}
+	if !withTracks {
+		return album
+	}
+
+	album.Tracks = []models.AlbumTrack{}
+	for _, songID := range m.sortedSongIDs() {
+		song := m.songs[songID]
+		if song.albumID == id && song.deletedAt.IsZero() {
+			album.Tracks = append(album.Tracks, models.AlbumTrack{TrackNumber: song.trackNumber, SongID: songID, Song: song.song})
+		}
+	}
+	sort.SliceStable(album.Tracks, func(i, j int) bool {
+		return album.Tracks[i].TrackNumber < album.Tracks[j].TrackNumber
+	})
+
+	return album
+}
+
+// Метод для получения идентификаторов альбомов в порядке возрастания
+func (m *SongsMemory) sortedAlbumIDs() []int {
+	ids := make([]int, 0, len(m.albums))
+	for id := range m.albums {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
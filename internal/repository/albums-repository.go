@@ -0,0 +1,463 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Общая часть запросов чтения альбомов; порядок столбцов соответствует scanAlbum
+const albumsSelect = `
+	SELECT al.id, al.title, al.group_id, g."group", COALESCE(to_char(al.release_date, 'DD.MM.YYYY'), ''), al.cover_link
+	FROM albums al
+	INNER JOIN groups g ON al.group_id = g.id`
+
+// Функция для чтения строки, выбранной запросом albumsSelect
+func scanAlbum(row pgx.Row) (models.Album, error) {
+	var album models.Album
+	err := row.Scan(&album.ID, &album.Title, &album.GroupID, &album.Group, &album.ReleaseDate, &album.CoverLink)
+	return album, err
+}
+
+// Структура AlbumsRepository, которая инкапсулирует подключение к базе данных
+type AlbumsRepository struct {
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
+}
+
+// Функция для создания нового экземпляра AlbumsRepository с подключением к базе данных
+func NewAlbumsRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *AlbumsRepository {
+	return &AlbumsRepository{db: db, transactor: transactor, opts: opts}
+}
+
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *AlbumsRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для получения альбомов с фильтрацией, пагинацией и возвратом общего количества страниц
+func (r *AlbumsRepository) GetAllAlbums(ctx context.Context, filter models.AlbumFilter, page, pageSize int) ([]models.Album, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * pageSize
+	where := `al.title ILIKE $1 AND g."group" ILIKE $2`
+
+	query := albumsSelect + `
+	WHERE ` + where + `
+	ORDER BY al.id
+	LIMIT $3 OFFSET $4`
+	args := []interface{}{"%" + filter.Title + "%", "%" + filter.Group + "%", pageSize, offset}
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("AlbumsRepository query error")
+		return nil, 0, fmt.Errorf("AlbumsRepository.GetAllAlbums query error: %w", err)
+	}
+	defer rows.Close()
+
+	albums := []models.Album{}
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			logrus.WithError(err).Error("AlbumsRepository scan error")
+			return nil, 0, fmt.Errorf("AlbumsRepository.GetAllAlbums scan error: %w", err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("AlbumsRepository rows error")
+		return nil, 0, fmt.Errorf("AlbumsRepository.GetAllAlbums rows error: %w", err)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM albums al INNER JOIN groups g ON al.group_id = g.id WHERE ` + where
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": args[:2],
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, args[:2]...).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("AlbumsRepository count query error")
+		return nil, 0, fmt.Errorf("AlbumsRepository.GetAllAlbums count query error: %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return albums, totalPages, nil
+}
+
+// Метод для получения альбома по ID вместе со списком треков
+func (r *AlbumsRepository) GetAlbumByID(ctx context.Context, id int) (models.Album, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.getAlbumByID(ctx, id)
+}
+
+// Метод для чтения альбома и его треков в текущей транзакции или вне её
+func (r *AlbumsRepository) getAlbumByID(ctx context.Context, id int) (models.Album, error) {
+	query := albumsSelect + `
+	WHERE al.id = $1`
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": id,
+	}).Debug("Executing query")
+
+	album, err := scanAlbum(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.WithField("id", id).Info("Album not found")
+			return models.Album{}, fmt.Errorf("album with id %d %w", id, ErrNotFound)
+		}
+		logrus.WithError(err).Error("AlbumsRepository.GetAlbumByID query error")
+		return models.Album{}, fmt.Errorf("AlbumsRepository.GetAlbumByID query error: %w", err)
+	}
+
+	tracksQuery := `SELECT track_number, id, song FROM songs
+	WHERE album_id = $1 AND deleted_at IS NULL
+	ORDER BY track_number NULLS LAST, id`
+	logrus.WithFields(logrus.Fields{
+		"query":  tracksQuery,
+		"params": id,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, tracksQuery, id)
+	if err != nil {
+		logrus.WithError(err).Error("AlbumsRepository.GetAlbumByID tracks query error")
+		return models.Album{}, fmt.Errorf("AlbumsRepository.GetAlbumByID tracks query error: %w", err)
+	}
+	defer rows.Close()
+
+	album.Tracks = []models.AlbumTrack{}
+	for rows.Next() {
+		var track models.AlbumTrack
+		var trackNumber *int
+		if err := rows.Scan(&trackNumber, &track.SongID, &track.Song); err != nil {
+			logrus.WithError(err).Error("AlbumsRepository.GetAlbumByID tracks scan error")
+			return models.Album{}, fmt.Errorf("AlbumsRepository.GetAlbumByID tracks scan error: %w", err)
+		}
+		if trackNumber != nil {
+			track.TrackNumber = *trackNumber
+		}
+		album.Tracks = append(album.Tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("AlbumsRepository.GetAlbumByID tracks rows error")
+		return models.Album{}, fmt.Errorf("AlbumsRepository.GetAlbumByID tracks rows error: %w", err)
+	}
+
+	return album, nil
+}
+
+// Метод для создания нового альбома; группа находится или создаётся по имени, как для песен
+func (r *AlbumsRepository) CreateAlbum(ctx context.Context, album models.Album) (models.Album, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var created models.Album
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		groupID, err := ensureGroupExists(ctx, conn(ctx, r.db), album.Group)
+		if err != nil {
+			logrus.WithError(err).Error("Error ensuring group exists")
+			return err
+		}
+
+		releaseDate, err := releaseDateValue(album.ReleaseDate)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO albums (group_id, title, release_date, cover_link) VALUES ($1, $2, $3, $4) RETURNING id`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": []interface{}{groupID, album.Title, releaseDate, album.CoverLink},
+		}).Debug("Executing query")
+
+		var id int
+		if err := conn(ctx, r.db).QueryRow(ctx, query, groupID, album.Title, releaseDate, album.CoverLink).Scan(&id); err != nil {
+			logrus.WithError(err).Error("Error inserting album")
+			return fmt.Errorf("error inserting album: %w", mapPgError(err))
+		}
+
+		created, err = r.getAlbumByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	return created, nil
+}
+
+// Метод для обновления альбома по ID; пустые поля не изменяются
+func (r *AlbumsRepository) UpdateAlbum(ctx context.Context, id int, album models.Album) (models.Album, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var updated models.Album
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		args := queryArgs{id}
+		var sets []string
+		if album.Title != "" {
+			sets = append(sets, `title = `+args.add(album.Title))
+		}
+		if album.ReleaseDate != "" {
+			releaseDate, err := releaseDateValue(album.ReleaseDate)
+			if err != nil {
+				return err
+			}
+			sets = append(sets, `release_date = `+args.add(releaseDate))
+		}
+		if album.CoverLink != "" {
+			sets = append(sets, `cover_link = `+args.add(album.CoverLink))
+		}
+		if len(sets) == 0 {
+			return fmt.Errorf("invalid update album id: %v data: no fields to update", id)
+		}
+
+		query := `UPDATE albums SET ` + strings.Join(sets, ", ") + ` WHERE id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
+		if err != nil {
+			logrus.WithError(err).Error("Error updating album")
+			return fmt.Errorf("error updating album with id %d: %w", id, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("id", id).Info("Album not found")
+			return fmt.Errorf("album with id %d %w", id, ErrNotFound)
+		}
+
+		// Название альбома входит в представление его песен
+		if album.Title != "" {
+			songsQuery := `UPDATE songs SET version = version + 1 WHERE album_id = $1`
+			logrus.WithFields(logrus.Fields{
+				"query":  songsQuery,
+				"params": id,
+			}).Debug("Executing query")
+
+			if _, err := conn(ctx, r.db).Exec(ctx, songsQuery, id); err != nil {
+				logrus.WithError(err).Error("Error touching album songs")
+				return fmt.Errorf("error updating version of songs of album with id %d: %w", id, err)
+			}
+		}
+
+		updated, err = r.getAlbumByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	return updated, nil
+}
+
+// Метод для удаления альбома по ID; песни альбома остаются без альбома и номера трека
+func (r *AlbumsRepository) DeleteAlbum(ctx context.Context, id int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.clearTracks(ctx, id); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM albums WHERE id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": id,
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+		if err != nil {
+			logrus.WithError(err).Error("Error deleting album")
+			return fmt.Errorf("error deleting album with id %d: %w", id, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("id", id).Info("Album not found")
+			return fmt.Errorf("album with id %d %w", id, ErrNotFound)
+		}
+
+		return nil
+	})
+}
+
+// Метод для замены списка треков альбома. Песни должны принадлежать группе альбома;
+// песни, не вошедшие в новый список, исключаются из альбома.
+func (r *AlbumsRepository) SetAlbumTracks(ctx context.Context, id int, tracks []models.AlbumTrack) (models.Album, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var album models.Album
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Блокировка альбома сериализует параллельные изменения его треков
+		lockQuery := `SELECT group_id FROM albums WHERE id = $1 FOR UPDATE`
+		logrus.WithFields(logrus.Fields{
+			"query":  lockQuery,
+			"params": id,
+		}).Debug("Executing query")
+
+		var groupID int
+		if err := conn(ctx, r.db).QueryRow(ctx, lockQuery, id).Scan(&groupID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logrus.WithField("id", id).Info("Album not found")
+				return fmt.Errorf("album with id %d %w", id, ErrNotFound)
+			}
+			logrus.WithError(err).Error("Error locking album")
+			return fmt.Errorf("error locking album with id %d: %w", id, err)
+		}
+
+		if err := r.clearTracks(ctx, id); err != nil {
+			return err
+		}
+
+		query := `UPDATE songs SET album_id = $1, track_number = $2, version = version + 1 WHERE id = $3 AND group_id = $4 AND deleted_at IS NULL`
+		for _, track := range tracks {
+			logrus.WithFields(logrus.Fields{
+				"query":  query,
+				"params": []interface{}{id, track.TrackNumber, track.SongID, groupID},
+			}).Debug("Executing query")
+
+			tag, err := conn(ctx, r.db).Exec(ctx, query, id, track.TrackNumber, track.SongID, groupID)
+			if err != nil {
+				logrus.WithError(err).Error("Error setting album track")
+				return fmt.Errorf("error setting track %d of album with id %d: %w", track.TrackNumber, id, mapPgError(err))
+			}
+			if tag.RowsAffected() == 0 {
+				return r.trackSongError(ctx, track.SongID)
+			}
+		}
+
+		var err error
+		album, err = r.getAlbumByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	return album, nil
+}
+
+// Метод для помещения песни в альбом под номером трека; nil исключает песню из альбома.
+// Альбом должен принадлежать группе песни, номер трека не должен быть занят другой песней.
+func (r *AlbumsRepository) SetSongAlbum(ctx context.Context, songID int, album *models.SongAlbum) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if album == nil {
+		return r.clearSongAlbum(ctx, songID)
+	}
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Блокировка альбома сериализует изменение с заменой его треков
+		lockQuery := `SELECT group_id FROM albums WHERE id = $1 FOR UPDATE`
+		logrus.WithFields(logrus.Fields{
+			"query":  lockQuery,
+			"params": album.ID,
+		}).Debug("Executing query")
+
+		var groupID int
+		if err := conn(ctx, r.db).QueryRow(ctx, lockQuery, album.ID).Scan(&groupID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logrus.WithField("id", album.ID).Info("Album not found")
+				return fmt.Errorf("album with id %d %w", album.ID, ErrNotFound)
+			}
+			logrus.WithError(err).Error("Error locking album")
+			return fmt.Errorf("error locking album with id %d: %w", album.ID, err)
+		}
+
+		query := `UPDATE songs SET album_id = $1, track_number = $2, version = version + 1 WHERE id = $3 AND group_id = $4 AND deleted_at IS NULL`
+		args := []interface{}{album.ID, album.TrackNumber, songID, groupID}
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
+		if err != nil {
+			logrus.WithError(err).Error("Error setting song album")
+			return fmt.Errorf("error setting album of song with id %d: %w", songID, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			return r.trackSongError(ctx, songID)
+		}
+		return nil
+	})
+}
+
+// Метод для исключения песни из альбома; ErrNotFound, если песни нет или она в корзине
+func (r *AlbumsRepository) clearSongAlbum(ctx context.Context, songID int) error {
+	query := `UPDATE songs SET album_id = NULL, track_number = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Error clearing song album")
+		return fmt.Errorf("error clearing album of song with id %d: %w", songID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+	return nil
+}
+
+// Метод для исключения всех песен из альбома
+func (r *AlbumsRepository) clearTracks(ctx context.Context, id int) error {
+	query := `UPDATE songs SET album_id = NULL, track_number = NULL, version = version + 1 WHERE album_id = $1`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": id,
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, id); err != nil {
+		logrus.WithError(err).Error("Error clearing album tracks")
+		return fmt.Errorf("error clearing tracks of album with id %d: %w", id, err)
+	}
+	return nil
+}
+
+// Метод для определения причины, по которой песню нельзя поставить в альбом:
+// песни нет (ErrNotFound) или она принадлежит другой группе (ErrConflict)
+func (r *AlbumsRepository) trackSongError(ctx context.Context, songID int) error {
+	query := `SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	var exists int
+	err := conn(ctx, r.db).QueryRow(ctx, query, songID).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	} else if err != nil {
+		logrus.WithError(err).Error("Error querying song")
+		return fmt.Errorf("error querying song with id %d: %w", songID, err)
+	}
+	return fmt.Errorf("song with id %d belongs to another group: %w", songID, ErrConflict)
+}
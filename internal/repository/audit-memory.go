@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Метод для добавления записи в журнал аудита
+func (m *SongsMemory) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.AddAuditEntry: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	entry.ID = int64(len(m.audit) + 1)
+	entry.CreatedAt = time.Now()
+	m.audit = append(m.audit, entry)
+
+	return nil
+}
+
+// Метод для добавления пакета записей в журнал аудита
+func (m *SongsMemory) AddAuditEntries(ctx context.Context, entries []models.AuditEntry) error {
+	for _, entry := range entries {
+		if err := m.AddAuditEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Метод для получения записей журнала аудита с фильтрацией, от новых к старым, с пагинацией
+func (m *SongsMemory) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAuditEntries invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAuditEntries: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	matched := []models.AuditEntry{}
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		if auditMatches(m.audit[i], filter) {
+			matched = append(matched, m.audit[i])
+		}
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (len(matched) + pageSize - 1) / pageSize
+
+	if offset >= len(matched) {
+		return []models.AuditEntry{}, totalPages, nil
+	}
+	return matched[offset:min(offset+pageSize, len(matched))], totalPages, nil
+}
+
+// Функция для проверки записи журнала аудита на соответствие фильтру
+func auditMatches(entry models.AuditEntry, filter models.AuditFilter) bool {
+	switch {
+	case filter.Actor != "" && entry.Actor != filter.Actor,
+		filter.Action != "" && entry.Action != filter.Action,
+		filter.Entity != "" && entry.Entity != filter.Entity,
+		filter.EntityID != 0 && entry.EntityID != filter.EntityID,
+		filter.RequestID != "" && entry.RequestID != filter.RequestID,
+		!filter.From.IsZero() && entry.CreatedAt.Before(filter.From),
+		!filter.To.IsZero() && !entry.CreatedAt.Before(filter.To):
+		return false
+	}
+	return true
+}
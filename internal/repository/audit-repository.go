@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура AuditRepository, которая инкапсулирует подключение к базе данных
+type AuditRepository struct {
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
+}
+
+// Функция для создания нового экземпляра AuditRepository с подключением к базе данных
+func NewAuditRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *AuditRepository {
+	return &AuditRepository{db: db, transactor: transactor, opts: opts}
+}
+
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *AuditRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для добавления записи в журнал аудита; внутри транзакции запись сохраняется вместе с изменением
+func (r *AuditRepository) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+	INSERT INTO audit_log (actor, action, entity, entity_id, before, after, request_id, client_ip)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	args := []interface{}{entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+		[]byte(entry.Before), []byte(entry.After), entry.RequestID, entry.ClientIP}
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, args...); err != nil {
+		logrus.WithError(err).Error("Error inserting audit entry")
+		return fmt.Errorf("error inserting audit entry for %s with id %d: %w", entry.Entity, entry.EntityID, err)
+	}
+
+	return nil
+}
+
+// Метод для добавления пакета записей в журнал аудита через COPY
+func (r *AuditRepository) AddAuditEntries(ctx context.Context, entries []models.AuditEntry) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows := make([][]interface{}, len(entries))
+	for i, entry := range entries {
+		rows[i] = []interface{}{entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+			[]byte(entry.Before), []byte(entry.After), entry.RequestID, entry.ClientIP}
+	}
+	return copyRows(ctx, conn(ctx, r.db), "audit_log",
+		[]string{"actor", "action", "entity", "entity_id", "before", "after", "request_id", "client_ip"}, rows)
+}
+
+// Метод для получения записей журнала аудита с фильтрацией, от новых к старым, с пагинацией
+func (r *AuditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var args queryArgs
+	where := auditWhere(filter, &args)
+	filterArgs := len(args)
+
+	offset := (page - 1) * pageSize
+	query := `
+	SELECT id, created_at, actor, action, entity, entity_id, before, after, request_id, client_ip
+	FROM audit_log` + where + `
+	ORDER BY id DESC
+	LIMIT ` + args.add(pageSize) + ` OFFSET ` + args.add(offset)
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("AuditRepository query error")
+		return nil, 0, fmt.Errorf("AuditRepository.GetAuditEntries query error: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&entry.ID, &entry.CreatedAt, &entry.Actor, &entry.Action, &entry.Entity, &entry.EntityID,
+			&before, &after, &entry.RequestID, &entry.ClientIP); err != nil {
+			logrus.WithError(err).Error("AuditRepository scan error")
+			return nil, 0, fmt.Errorf("AuditRepository.GetAuditEntries scan error: %w", err)
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("AuditRepository rows error")
+		return nil, 0, fmt.Errorf("AuditRepository.GetAuditEntries rows error: %w", err)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM audit_log` + where
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": args[:filterArgs],
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, args[:filterArgs]...).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("AuditRepository count query error")
+		return nil, 0, fmt.Errorf("AuditRepository.GetAuditEntries count query error: %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return entries, totalPages, nil
+}
+
+// Функция для построения условия WHERE по фильтру журнала аудита; пустая строка без фильтров
+func auditWhere(filter models.AuditFilter, args *queryArgs) string {
+	var conditions []string
+	if filter.Actor != "" {
+		conditions = append(conditions, `actor = `+args.add(filter.Actor))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, `action = `+args.add(filter.Action))
+	}
+	if filter.Entity != "" {
+		conditions = append(conditions, `entity = `+args.add(filter.Entity))
+	}
+	if filter.EntityID != 0 {
+		conditions = append(conditions, `entity_id = `+args.add(filter.EntityID))
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, `request_id = `+args.add(filter.RequestID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, `created_at >= `+args.add(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, `created_at < `+args.add(filter.To))
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return `
+	WHERE ` + strings.Join(conditions, ` AND `)
+}
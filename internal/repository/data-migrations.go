@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Размер пачки строк, обновляемых одним запросом в миграциях данных
+const dataMigrationBatchSize = 1000
+
+// Миграции данных, которые приложение выполняет сразу после SQL-миграции с указанной версией.
+// Ключи имён вычисляются в Go той же нормализацией, что и при записи (models.GroupKey,
+// models.SongKey): unaccent и регулярные выражения PostgreSQL сворачивают ø, ß и пробелы
+// Unicode иначе, и ключи в базе не совпали бы с ключами поиска.
+var dataMigrations = map[uint]func(ctx context.Context, tx pgx.Tx) error{
+	5:  backfillGroupKeys,
+	14: backfillSongTitleKeys,
+}
+
+// Функция для выполнения миграции данных версии version в одной транзакции
+func runDataMigration(db *pgxpool.Pool, version uint, migrate func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error beginning data migration %d: %w", version, err)
+	}
+	defer tx.Rollback(ctx)
+
+	logrus.WithField("version", version).Info("Running data migration")
+	if err := migrate(ctx, tx); err != nil {
+		return fmt.Errorf("data migration %d: %w", version, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing data migration %d: %w", version, err)
+	}
+	return nil
+}
+
+// Функция для заполнения name_key существующих групп (миграция 000005): имена нормализуются
+// models.NormalizeGroupName, группы с совпавшим ключом объединяются в группу с наименьшим id
+func backfillGroupKeys(ctx context.Context, tx pgx.Tx) error {
+	keys := map[string]int{}
+	rows, err := tx.Query(ctx, `SELECT id, name_key FROM groups WHERE name_key IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("error selecting group keys: %w", err)
+	}
+	for rows.Next() {
+		var id int
+		var key string
+		if err := rows.Scan(&id, &key); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning group key: %w", err)
+		}
+		keys[key] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error selecting group keys: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, `SELECT id, "group" FROM groups WHERE name_key IS NULL ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("error selecting groups: %w", err)
+	}
+	var ids, mergedIDs, keepIDs []int
+	var names, nameKeys []string
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning group: %w", err)
+		}
+		key := models.GroupKey(name)
+		if keepID, ok := keys[key]; ok {
+			mergedIDs, keepIDs = append(mergedIDs, id), append(keepIDs, keepID)
+			continue
+		}
+		keys[key] = id
+		ids, names, nameKeys = append(ids, id), append(names, models.NormalizeGroupName(name)), append(nameKeys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error selecting groups: %w", err)
+	}
+
+	for start := 0; start < len(ids); start += dataMigrationBatchSize {
+		end := min(start+dataMigrationBatchSize, len(ids))
+		if _, err := tx.Exec(ctx, `
+		UPDATE groups g SET "group" = v.name, name_key = v.name_key
+		FROM unnest($1::int[], $2::text[], $3::text[]) AS v(id, name, name_key)
+		WHERE g.id = v.id`, ids[start:end], names[start:end], nameKeys[start:end]); err != nil {
+			return fmt.Errorf("error updating group keys: %w", err)
+		}
+	}
+
+	if len(mergedIDs) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"merged": mergedIDs,
+			"into":   keepIDs,
+		}).Warn("Merging groups with the same name key")
+		if _, err := tx.Exec(ctx, `
+		UPDATE songs s SET group_id = v.keep_id
+		FROM unnest($1::int[], $2::int[]) AS v(id, keep_id)
+		WHERE s.group_id = v.id`, mergedIDs, keepIDs); err != nil {
+			return fmt.Errorf("error moving songs of merged groups: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM groups WHERE id = ANY($1::int[])`, mergedIDs); err != nil {
+			return fmt.Errorf("error deleting merged groups: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE groups ALTER COLUMN name_key SET NOT NULL`); err != nil {
+		return fmt.Errorf("error setting name_key not null: %w", err)
+	}
+	return nil
+}
+
+// Функция для заполнения title_key существующих песен (миграция 000014). Песни вне корзины
+// с совпавшим ключом в одной группе не удаляются: миграция завершается ошибкой со списком
+// повторов, которые нужно переименовать или переместить в корзину вручную.
+func backfillSongTitleKeys(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `SELECT id, group_id, song, deleted_at IS NOT NULL FROM songs WHERE title_key IS NULL ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("error selecting songs: %w", err)
+	}
+	type songKey struct {
+		groupID int
+		key     string
+	}
+	seen := map[songKey][]int{}
+	titles := map[songKey]string{}
+	var ids []int
+	var titleKeys []string
+	for rows.Next() {
+		var id, groupID int
+		var title string
+		var deleted bool
+		if err := rows.Scan(&id, &groupID, &title, &deleted); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning song: %w", err)
+		}
+		key := models.SongKey(title)
+		ids, titleKeys = append(ids, id), append(titleKeys, key)
+		if !deleted {
+			k := songKey{groupID: groupID, key: key}
+			seen[k] = append(seen[k], id)
+			if _, ok := titles[k]; !ok {
+				titles[k] = title
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error selecting songs: %w", err)
+	}
+
+	// Песни, ключи которых уже заполнены, тоже участвуют в проверке повторов
+	rows, err = tx.Query(ctx, `SELECT id, group_id, title_key FROM songs WHERE title_key IS NOT NULL AND deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("error selecting song keys: %w", err)
+	}
+	for rows.Next() {
+		var k songKey
+		var id int
+		if err := rows.Scan(&id, &k.groupID, &k.key); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning song key: %w", err)
+		}
+		if _, ok := seen[k]; ok {
+			seen[k] = append(seen[k], id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error selecting song keys: %w", err)
+	}
+
+	var duplicates []string
+	for k, songIDs := range seen {
+		if len(songIDs) > 1 {
+			sort.Ints(songIDs)
+			duplicates = append(duplicates, fmt.Sprintf("group %d %q: songs %v", k.groupID, titles[k], songIDs))
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return fmt.Errorf("songs with the same title in one group, rename them or move them to the trash and restart: %s",
+			strings.Join(duplicates, "; "))
+	}
+
+	for start := 0; start < len(ids); start += dataMigrationBatchSize {
+		end := min(start+dataMigrationBatchSize, len(ids))
+		if _, err := tx.Exec(ctx, `
+		UPDATE songs s SET title_key = v.title_key
+		FROM unnest($1::int[], $2::text[]) AS v(id, title_key)
+		WHERE s.id = v.id`, ids[start:end], titleKeys[start:end]); err != nil {
+			return fmt.Errorf("error updating song title keys: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE songs ALTER COLUMN title_key SET NOT NULL`); err != nil {
+		return fmt.Errorf("error setting title_key not null: %w", err)
+	}
+	return nil
+}
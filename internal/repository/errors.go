@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки репозитория, не зависящие от конкретного хранилища
+var (
+	// ErrNotFound возвращается, когда запрошенная запись не существует
+	ErrNotFound = errors.New("not found")
+	// ErrConflict возвращается, когда изменение нарушает ограничения целостности данных
+	ErrConflict = errors.New("conflict")
+	// ErrVersionMismatch возвращается, когда запись изменилась после чтения версии, переданной клиентом
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// Структура DuplicateSongError - ошибка ErrConflict при совпадении ключа названия песни
+// с другой песней той же группы, не находящейся в корзине
+type DuplicateSongError struct {
+	ExistingID int // ID уже существующей песни
+}
+
+// Метод для получения текста ошибки
+func (e *DuplicateSongError) Error() string {
+	return fmt.Sprintf("%v: song already exists with id %d", ErrConflict, e.ExistingID)
+}
+
+// Метод для сопоставления ошибки с ErrConflict через errors.Is
+func (e *DuplicateSongError) Unwrap() error {
+	return ErrConflict
+}
+
+// Коды ошибок PostgreSQL, означающие конфликт данных
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// Функция для преобразования ошибок pgx в ошибки репозитория с сохранением исходной причины
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation, pgForeignKeyViolation:
+			return fmt.Errorf("%w: %s", ErrConflict, pgErr.Detail)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для добавления псевдонима группы. Псевдоним не может совпадать по ключу
+// с именем другой группы или с уже существующим псевдонимом.
+func (r *GroupsRepository) AddGroupAlias(ctx context.Context, groupID int, alias string) (models.Group, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	alias = models.NormalizeGroupName(alias)
+	key := models.GroupKey(alias)
+
+	var group models.Group
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := lockGroupKey(ctx, conn(ctx, r.db), key); err != nil {
+			return err
+		}
+
+		// Группа блокируется, чтобы её не удалили и не объединили с другой до добавления псевдонима
+		current, err := r.lockGroups(ctx, []int{groupID})
+		if err != nil {
+			return err
+		}
+
+		var ownerID int
+		ownerQuery := `SELECT id FROM groups WHERE name_key = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  ownerQuery,
+			"params": key,
+		}).Debug("Executing query")
+
+		err = conn(ctx, r.db).QueryRow(ctx, ownerQuery, key).Scan(&ownerID)
+		switch {
+		case err == nil && ownerID == groupID:
+			return fmt.Errorf("alias %q matches the name of the group %q: %w", alias, current[groupID], ErrConflict)
+		case err == nil:
+			return fmt.Errorf("alias %q is the name of group with id %d: %w", alias, ownerID, ErrConflict)
+		case !errors.Is(err, pgx.ErrNoRows):
+			logrus.WithError(err).Error("Error querying group")
+			return fmt.Errorf("error querying group: %w", err)
+		}
+
+		query := `INSERT INTO group_aliases (group_id, alias, name_key) VALUES ($1, $2, $3)`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": []interface{}{groupID, alias, key},
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, query, groupID, alias, key); err != nil {
+			logrus.WithError(err).Error("Error inserting group alias")
+			return fmt.Errorf("error inserting group alias: %w", mapPgError(err))
+		}
+
+		group, err = r.getGroupByID(ctx, groupID)
+		return err
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return group, nil
+}
+
+// Метод для удаления псевдонима группы по ID
+func (r *GroupsRepository) DeleteGroupAlias(ctx context.Context, groupID, aliasID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM group_aliases WHERE id = $1 AND group_id = $2`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{aliasID, groupID},
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, aliasID, groupID)
+	if err != nil {
+		logrus.WithError(err).Error("Error deleting group alias")
+		return fmt.Errorf("error deleting group alias with id %d: %w", aliasID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("aliasID", aliasID).Info("Group alias not found")
+		return fmt.Errorf("alias with id %d of group with id %d %w", aliasID, groupID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// Метод для объединения групп: все песни, альбомы и псевдонимы исходных групп переносятся в целевую,
+// имена исходных групп становятся её псевдонимами, а сами исходные группы удаляются.
+func (r *GroupsRepository) MergeGroups(ctx context.Context, targetID int, sourceIDs []int) (models.GroupMerge, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var merge models.GroupMerge
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.lockGroups(ctx, append([]int{targetID}, sourceIDs...)); err != nil {
+			return err
+		}
+
+		// Песни других групп, где исходные группы указаны исполнителями, получат имя целевой группы
+		if err := touchCreditedSongs(ctx, conn(ctx, r.db), sourceIDs); err != nil {
+			return err
+		}
+
+		songsQuery := `UPDATE songs SET group_id = $1, version = version + 1 WHERE group_id = ANY($2)`
+		logrus.WithFields(logrus.Fields{
+			"query":  songsQuery,
+			"params": []interface{}{targetID, sourceIDs},
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, songsQuery, targetID, sourceIDs)
+		if err != nil {
+			logrus.WithError(err).Error("Error moving songs")
+			return fmt.Errorf("error moving songs to group with id %d: %w", targetID, mapPgError(err))
+		}
+		merge.MovedSongs = int(tag.RowsAffected())
+
+		// Альбомы исходных групп переходят к целевой группе вместе с песнями
+		albumsQuery := `UPDATE albums SET group_id = $1 WHERE group_id = ANY($2)`
+		logrus.WithFields(logrus.Fields{
+			"query":  albumsQuery,
+			"params": []interface{}{targetID, sourceIDs},
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, albumsQuery, targetID, sourceIDs); err != nil {
+			logrus.WithError(err).Error("Error moving albums")
+			return fmt.Errorf("error moving albums to group with id %d: %w", targetID, mapPgError(err))
+		}
+
+		// Исполнители песен: основной исполнитель уже сменился вместе с songs.group_id,
+		// остальные роли копируются целевой группе, а строки исходных групп удаляются вместе с ними
+		artistsQuery := `UPDATE song_artists SET group_id = $1 WHERE group_id = ANY($2) AND role = 'primary'`
+		creditsQuery := `INSERT INTO song_artists (song_id, group_id, role)
+		SELECT song_id, $1, role FROM song_artists WHERE group_id = ANY($2) AND role <> 'primary'
+		ON CONFLICT DO NOTHING`
+		for _, query := range []string{artistsQuery, creditsQuery} {
+			logrus.WithFields(logrus.Fields{
+				"query":  query,
+				"params": []interface{}{targetID, sourceIDs},
+			}).Debug("Executing query")
+
+			if _, err := conn(ctx, r.db).Exec(ctx, query, targetID, sourceIDs); err != nil {
+				logrus.WithError(err).Error("Error moving song artists")
+				return fmt.Errorf("error moving song artists to group with id %d: %w", targetID, mapPgError(err))
+			}
+		}
+
+		// Псевдонимы исходных групп и их имена переходят к целевой группе
+		aliasesQuery := `UPDATE group_aliases SET group_id = $1 WHERE group_id = ANY($2)`
+		logrus.WithFields(logrus.Fields{
+			"query":  aliasesQuery,
+			"params": []interface{}{targetID, sourceIDs},
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, aliasesQuery, targetID, sourceIDs); err != nil {
+			logrus.WithError(err).Error("Error moving group aliases")
+			return fmt.Errorf("error moving aliases to group with id %d: %w", targetID, mapPgError(err))
+		}
+
+		namesQuery := `INSERT INTO group_aliases (group_id, alias, name_key)
+		SELECT $1, "group", name_key FROM groups WHERE id = ANY($2) ORDER BY id`
+		logrus.WithFields(logrus.Fields{
+			"query":  namesQuery,
+			"params": []interface{}{targetID, sourceIDs},
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, namesQuery, targetID, sourceIDs); err != nil {
+			logrus.WithError(err).Error("Error inserting group aliases")
+			return fmt.Errorf("error inserting aliases for group with id %d: %w", targetID, mapPgError(err))
+		}
+
+		deleteQuery := `DELETE FROM groups WHERE id = ANY($1)`
+		logrus.WithFields(logrus.Fields{
+			"query":  deleteQuery,
+			"params": sourceIDs,
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, deleteQuery, sourceIDs); err != nil {
+			logrus.WithError(err).Error("Error deleting merged groups")
+			return fmt.Errorf("error deleting merged groups: %w", mapPgError(err))
+		}
+
+		merge.Group, err = r.getGroupByID(ctx, targetID)
+		return err
+	})
+	if err != nil {
+		return models.GroupMerge{}, err
+	}
+
+	return merge, nil
+}
+
+// Метод для блокировки групп FOR UPDATE в порядке возрастания id (без взаимных блокировок
+// между параллельными объединениями). Возвращает имена групп, отсутствие любой из них - ErrNotFound.
+func (r *GroupsRepository) lockGroups(ctx context.Context, ids []int) (map[int]string, error) {
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+
+	query := `SELECT id, "group" FROM groups WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": sorted,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, sorted)
+	if err != nil {
+		logrus.WithError(err).Error("Error locking groups")
+		return nil, fmt.Errorf("error locking groups: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int]string, len(sorted))
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			logrus.WithError(err).Error("Error scanning group")
+			return nil, fmt.Errorf("error scanning group: %w", err)
+		}
+		names[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error locking groups")
+		return nil, fmt.Errorf("error locking groups: %w", err)
+	}
+
+	for _, id := range sorted {
+		if _, ok := names[id]; !ok {
+			logrus.WithField("id", id).Info("Group not found")
+			return nil, fmt.Errorf("group with id %d %w", id, ErrNotFound)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для получения групп с фильтрацией по подстроке имени, пагинацией и возвратом общего количества страниц
+func (m *SongsMemory) GetAllGroups(ctx context.Context, name string, page, pageSize int) ([]models.Group, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllGroups invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllGroups: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	var matched []int
+	for _, id := range m.sortedGroupIDs() {
+		if ilike(m.groups[id], "%"+name+"%") {
+			matched = append(matched, id)
+		}
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (len(matched) + pageSize - 1) / pageSize
+
+	groups := []models.Group{}
+	for i := offset; i < len(matched) && i < offset+pageSize; i++ {
+		groups = append(groups, m.toGroup(matched[i]))
+	}
+
+	return groups, totalPages, nil
+}
+
+// Метод для получения группы по ID
+func (m *SongsMemory) GetGroupByID(ctx context.Context, id int) (models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Group{}, fmt.Errorf("SongsMemory.GetGroupByID: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	if _, ok := m.groups[id]; !ok {
+		logrus.WithField("id", id).Info("Group not found")
+		return models.Group{}, fmt.Errorf("group with id %d %w", id, ErrNotFound)
+	}
+
+	return m.toGroup(id), nil
+}
+
+// Метод для создания новой группы; группа или псевдоним с тем же ключом имени приводят к ErrConflict
+func (m *SongsMemory) CreateGroup(ctx context.Context, name string) (models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Group{}, fmt.Errorf("SongsMemory.CreateGroup: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if owner := m.groupIDByAlias(models.GroupKey(name)); owner != 0 {
+		return models.Group{}, fmt.Errorf("name %q is an alias of group with id %d: %w", models.NormalizeGroupName(name), owner, ErrConflict)
+	}
+
+	if existing := m.groupIDByKey(models.GroupKey(name)); existing != 0 {
+		return models.Group{}, fmt.Errorf("error inserting group: %w: group %q already exists with id %d", ErrConflict, m.groups[existing], existing)
+	}
+
+	id := m.ensureGroupExists(name)
+	return m.toGroup(id), nil
+}
+
+// Метод для переименования группы вместе со всеми её песнями; псевдоним с новым именем удаляется
+func (m *SongsMemory) RenameGroup(ctx context.Context, id int, name string) (models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Group{}, fmt.Errorf("SongsMemory.RenameGroup: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if _, ok := m.groups[id]; !ok {
+		logrus.WithField("id", id).Info("Group not found")
+		return models.Group{}, fmt.Errorf("group with id %d %w", id, ErrNotFound)
+	}
+	key := models.GroupKey(name)
+	if owner := m.groupIDByAlias(key); owner != 0 && owner != id {
+		return models.Group{}, fmt.Errorf("name %q is an alias of group with id %d: %w", models.NormalizeGroupName(name), owner, ErrConflict)
+	}
+	if existing := m.groupIDByKey(key); existing != 0 && existing != id {
+		return models.Group{}, fmt.Errorf("error renaming group with id %d: %w: group %q already exists with id %d", id, ErrConflict, m.groups[existing], existing)
+	}
+
+	m.groups[id] = models.NormalizeGroupName(name)
+	// Имя группы входит в представление её песен и песен, где она указана исполнителем
+	for songID, record := range m.songs {
+		if record.groupID == id || slices.ContainsFunc(record.credits, func(credit artistCredit) bool { return credit.groupID == id }) {
+			record.version++
+			m.songs[songID] = record
+		}
+	}
+	if aliasID := m.aliasIDByKey(key); aliasID != 0 {
+		delete(m.aliases, aliasID)
+	}
+	return m.toGroup(id), nil
+}
+
+// Метод для удаления группы по ID, с её песнями при cascade
+func (m *SongsMemory) DeleteGroup(ctx context.Context, id int, cascade bool) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.DeleteGroup: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if _, ok := m.groups[id]; !ok {
+		logrus.WithField("id", id).Info("Group not found")
+		return fmt.Errorf("group with id %d %w", id, ErrNotFound)
+	}
+
+	albums := 0
+	for _, album := range m.albums {
+		if album.groupID == id {
+			albums++
+		}
+	}
+	// Как и в GroupsRepository, учитываются и песни в корзине
+	songs := 0
+	for _, record := range m.songs {
+		if record.groupID == id {
+			songs++
+		}
+	}
+	if (songs > 0 || albums > 0) && !cascade {
+		return fmt.Errorf("group with id %d has %d songs and %d albums: %w", id, songs, albums, ErrConflict)
+	}
+
+	delete(m.groups, id)
+	for songID, record := range m.songs {
+		if record.groupID == id {
+			delete(m.songs, songID)
+			delete(m.revisions, songID)
+		}
+	}
+	for aliasID, alias := range m.aliases {
+		if alias.groupID == id {
+			delete(m.aliases, aliasID)
+		}
+	}
+	for albumID, album := range m.albums {
+		if album.groupID == id {
+			delete(m.albums, albumID)
+		}
+	}
+	m.deleteCredits(id)
+
+	return nil
+}
+
+// Метод для добавления псевдонима группы, как в GroupsRepository
+func (m *SongsMemory) AddGroupAlias(ctx context.Context, groupID int, alias string) (models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Group{}, fmt.Errorf("SongsMemory.AddGroupAlias: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	alias = models.NormalizeGroupName(alias)
+	key := models.GroupKey(alias)
+
+	if _, ok := m.groups[groupID]; !ok {
+		logrus.WithField("id", groupID).Info("Group not found")
+		return models.Group{}, fmt.Errorf("group with id %d %w", groupID, ErrNotFound)
+	}
+	if owner := m.groupIDByKey(key); owner == groupID {
+		return models.Group{}, fmt.Errorf("alias %q matches the name of the group %q: %w", alias, m.groups[groupID], ErrConflict)
+	} else if owner != 0 {
+		return models.Group{}, fmt.Errorf("alias %q is the name of group with id %d: %w", alias, owner, ErrConflict)
+	}
+	if existing := m.aliasIDByKey(key); existing != 0 {
+		return models.Group{}, fmt.Errorf("error inserting group alias: %w: alias %q already exists for group with id %d", ErrConflict, m.aliases[existing].alias, m.aliases[existing].groupID)
+	}
+
+	m.aliases[m.nextAliasID] = aliasRecord{groupID: groupID, alias: alias}
+	m.nextAliasID++
+
+	return m.toGroup(groupID), nil
+}
+
+// Метод для удаления псевдонима группы по ID
+func (m *SongsMemory) DeleteGroupAlias(ctx context.Context, groupID, aliasID int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.DeleteGroupAlias: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if alias, ok := m.aliases[aliasID]; !ok || alias.groupID != groupID {
+		logrus.WithField("aliasID", aliasID).Info("Group alias not found")
+		return fmt.Errorf("alias with id %d of group with id %d %w", aliasID, groupID, ErrNotFound)
+	}
+
+	delete(m.aliases, aliasID)
+	return nil
+}
+
+// Метод для объединения исходных групп с целевой, как в GroupsRepository
+func (m *SongsMemory) MergeGroups(ctx context.Context, targetID int, sourceIDs []int) (models.GroupMerge, error) {
+	if err := ctx.Err(); err != nil {
+		return models.GroupMerge{}, fmt.Errorf("SongsMemory.MergeGroups: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	ids := append([]int{targetID}, sourceIDs...)
+	sort.Ints(ids)
+	for _, id := range ids {
+		if _, ok := m.groups[id]; !ok {
+			logrus.WithField("id", id).Info("Group not found")
+			return models.GroupMerge{}, fmt.Errorf("group with id %d %w", id, ErrNotFound)
+		}
+	}
+
+	sources := make(map[int]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		sources[id] = true
+	}
+
+	// Альбомы исходных групп переходят к целевой группе, названия не должны повторяться
+	titles := make(map[string]bool)
+	for _, album := range m.albums {
+		if album.groupID != targetID && !sources[album.groupID] {
+			continue
+		}
+		title := strings.ToLower(album.title)
+		if titles[title] {
+			return models.GroupMerge{}, fmt.Errorf("error moving albums to group with id %d: %w: album %q already exists", targetID, ErrConflict, album.title)
+		}
+		titles[title] = true
+	}
+	// Песни исходных групп вне корзины переходят к целевой группе, как в уникальном индексе songs
+	songTitles := make(map[string]bool)
+	for _, record := range m.songs {
+		if !record.deletedAt.IsZero() || (record.groupID != targetID && !sources[record.groupID]) {
+			continue
+		}
+		key := models.SongKey(record.song)
+		if songTitles[key] {
+			return models.GroupMerge{}, fmt.Errorf("error moving songs to group with id %d: %w: song %q already exists", targetID, ErrConflict, record.song)
+		}
+		songTitles[key] = true
+	}
+	for id, album := range m.albums {
+		if sources[album.groupID] {
+			album.groupID = targetID
+			m.albums[id] = album
+		}
+	}
+
+	m.moveCredits(sources, targetID)
+	var merge models.GroupMerge
+	for id, record := range m.songs {
+		if sources[record.groupID] {
+			record.groupID = targetID
+			record.version++
+			m.songs[id] = record
+			merge.MovedSongs++
+		}
+	}
+	for id, alias := range m.aliases {
+		if sources[alias.groupID] {
+			alias.groupID = targetID
+			m.aliases[id] = alias
+		}
+	}
+	for _, id := range ids {
+		if !sources[id] {
+			continue
+		}
+		m.aliases[m.nextAliasID] = aliasRecord{groupID: targetID, alias: m.groups[id]}
+		m.nextAliasID++
+		delete(m.groups, id)
+	}
+
+	merge.Group = m.toGroup(targetID)
+	return merge, nil
+}
+
+// Метод для преобразования группы хранилища в модель группы
+func (m *SongsMemory) toGroup(id int) models.Group {
+	aliasIDs := make([]int, 0)
+	for aliasID, alias := range m.aliases {
+		if alias.groupID == id {
+			aliasIDs = append(aliasIDs, aliasID)
+		}
+	}
+	sort.Ints(aliasIDs)
+
+	aliases := make([]models.GroupAlias, 0, len(aliasIDs))
+	for _, aliasID := range aliasIDs {
+		aliases = append(aliases, models.GroupAlias{ID: aliasID, Alias: m.aliases[aliasID].alias})
+	}
+
+	return models.Group{ID: id, Name: m.groups[id], SongCount: m.groupSongCount(id), Aliases: aliases}
+}
+
+// Метод для подсчёта песен группы без песен в корзине
+func (m *SongsMemory) groupSongCount(id int) int {
+	count := 0
+	for _, record := range m.songs {
+		if record.groupID == id && record.deletedAt.IsZero() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Общая часть запросов чтения групп; порядок столбцов соответствует scanGroup
+const groupsSelect = `
+	SELECT g.id, g."group", (SELECT COUNT(*) FROM songs s WHERE s.group_id = g.id AND s.deleted_at IS NULL) AS song_count,
+		COALESCE((SELECT json_agg(json_build_object('id', a.id, 'alias', a.alias) ORDER BY a.id)
+			FROM group_aliases a WHERE a.group_id = g.id), '[]') AS aliases
+	FROM groups g`
+
+// Функция для чтения строки, выбранной запросом groupsSelect
+func scanGroup(row pgx.Row) (models.Group, error) {
+	var group models.Group
+	err := row.Scan(&group.ID, &group.Name, &group.SongCount, &group.Aliases)
+	return group, err
+}
+
+// Функция для сериализации изменений имён групп и псевдонимов с одинаковым ключом до конца транзакции.
+// Уникальные индексы действуют в пределах одной таблицы, а ключ не должен повторяться ни среди групп,
+// ни среди псевдонимов.
+func lockGroupKey(ctx context.Context, q querier, key string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext($1))`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": key,
+	}).Debug("Executing query")
+
+	if _, err := q.Exec(ctx, query, key); err != nil {
+		logrus.WithError(err).Error("Error locking group name")
+		return fmt.Errorf("error locking group name: %w", err)
+	}
+	return nil
+}
+
+// Функция для обеспечения существования группы: группа ищется по ключу имени (models.GroupKey)
+// среди псевдонимов и имён групп, а при отсутствии создаётся. Вставка с ON CONFLICT не даёт
+// параллельным запросам создать одну группу дважды и блокирует найденную строку группы
+// до конца транзакции.
+func ensureGroupExists(ctx context.Context, q querier, groupName string) (int, error) {
+	if groupName == "" {
+		return 0, nil
+	}
+
+	name := models.NormalizeGroupName(groupName)
+	key := models.GroupKey(groupName)
+
+	if err := lockGroupKey(ctx, q, key); err != nil {
+		return 0, err
+	}
+	if groupID, err := groupIDByAlias(ctx, q, key); err != nil || groupID != 0 {
+		return groupID, err
+	}
+
+	query := `INSERT INTO groups ("group", name_key) VALUES ($1, $2)
+	ON CONFLICT (name_key) DO UPDATE SET name_key = EXCLUDED.name_key
+	RETURNING id`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{name, key},
+	}).Debug("Executing query")
+
+	var groupID int
+	if err := q.QueryRow(ctx, query, name, key).Scan(&groupID); err != nil {
+		logrus.WithError(err).Error("Error ensuring group")
+		return 0, fmt.Errorf("error ensuring group: %w", mapPgError(err))
+	}
+	return groupID, nil
+}
+
+// Функция для получения ID группы, у которой есть псевдоним с заданным ключом; 0, если такого нет.
+// Строка группы блокируется FOR SHARE, чтобы её не удалили до конца транзакции.
+func groupIDByAlias(ctx context.Context, q querier, key string) (int, error) {
+	query := `SELECT g.id FROM group_aliases a
+	INNER JOIN groups g ON a.group_id = g.id
+	WHERE a.name_key = $1
+	FOR SHARE OF g`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": key,
+	}).Debug("Executing query")
+
+	var groupID int
+	err := q.QueryRow(ctx, query, key).Scan(&groupID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		logrus.WithError(err).Error("Error querying group alias")
+		return 0, fmt.Errorf("error querying group alias: %w", err)
+	}
+	return groupID, nil
+}
+
+// Структура GroupsRepository, которая инкапсулирует подключение к базе данных
+type GroupsRepository struct {
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
+}
+
+// Функция для создания нового экземпляра GroupsRepository с подключением к базе данных
+func NewGroupsRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *GroupsRepository {
+	return &GroupsRepository{db: db, transactor: transactor, opts: opts}
+}
+
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *GroupsRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для получения групп с фильтрацией по подстроке имени, пагинацией и возвратом общего количества страниц
+func (r *GroupsRepository) GetAllGroups(ctx context.Context, name string, page, pageSize int) ([]models.Group, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * pageSize
+
+	query := groupsSelect + `
+	WHERE g."group" ILIKE $1
+	ORDER BY g.id
+	LIMIT $2 OFFSET $3`
+	args := []interface{}{"%" + name + "%", pageSize, offset}
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("GroupsRepository query error")
+		return nil, 0, fmt.Errorf("GroupsRepository.GetAllGroups query error: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			logrus.WithError(err).Error("GroupsRepository scan error")
+			return nil, 0, fmt.Errorf("GroupsRepository.GetAllGroups scan error: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("GroupsRepository rows error")
+		return nil, 0, fmt.Errorf("GroupsRepository.GetAllGroups rows error: %w", err)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM groups g WHERE g."group" ILIKE $1`
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": args[0],
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, args[0]).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("GroupsRepository count query error")
+		return nil, 0, fmt.Errorf("GroupsRepository.GetAllGroups count query error: %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return groups, totalPages, nil
+}
+
+// Метод для получения группы по ID
+func (r *GroupsRepository) GetGroupByID(ctx context.Context, id int) (models.Group, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.getGroupByID(ctx, id)
+}
+
+// Метод для чтения группы по ID в текущей транзакции или вне её
+func (r *GroupsRepository) getGroupByID(ctx context.Context, id int) (models.Group, error) {
+	query := groupsSelect + `
+	WHERE g.id = $1`
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": id,
+	}).Debug("Executing query")
+
+	group, err := scanGroup(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.WithField("id", id).Info("Group not found")
+			return models.Group{}, fmt.Errorf("group with id %d %w", id, ErrNotFound)
+		}
+		logrus.WithError(err).Error("GroupsRepository.GetGroupByID query error")
+		return models.Group{}, fmt.Errorf("GroupsRepository.GetGroupByID query error: %w", err)
+	}
+
+	return group, nil
+}
+
+// Метод для создания новой группы; группа или псевдоним с тем же ключом имени приводят к ErrConflict
+func (r *GroupsRepository) CreateGroup(ctx context.Context, name string) (models.Group, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	name = models.NormalizeGroupName(name)
+	key := models.GroupKey(name)
+
+	var group models.Group
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := lockGroupKey(ctx, conn(ctx, r.db), key); err != nil {
+			return err
+		}
+		if owner, err := groupIDByAlias(ctx, conn(ctx, r.db), key); err != nil {
+			return err
+		} else if owner != 0 {
+			return fmt.Errorf("name %q is an alias of group with id %d: %w", name, owner, ErrConflict)
+		}
+
+		query := `INSERT INTO groups ("group", name_key) VALUES ($1, $2) RETURNING id`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": []interface{}{name, key},
+		}).Debug("Executing query")
+
+		group = models.Group{Name: name, Aliases: []models.GroupAlias{}}
+		if err := conn(ctx, r.db).QueryRow(ctx, query, name, key).Scan(&group.ID); err != nil {
+			logrus.WithError(err).Error("Error inserting group")
+			return fmt.Errorf("error inserting group: %w", mapPgError(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return group, nil
+}
+
+// Метод для переименования группы. Песни ссылаются на группу по id,
+// поэтому новое имя применяется ко всем песням группы одним обновлением.
+// Если новое имя было псевдонимом этой же группы, псевдоним удаляется.
+func (r *GroupsRepository) RenameGroup(ctx context.Context, id int, name string) (models.Group, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	name = models.NormalizeGroupName(name)
+	key := models.GroupKey(name)
+
+	var group models.Group
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := lockGroupKey(ctx, conn(ctx, r.db), key); err != nil {
+			return err
+		}
+		if owner, err := groupIDByAlias(ctx, conn(ctx, r.db), key); err != nil {
+			return err
+		} else if owner != 0 && owner != id {
+			return fmt.Errorf("name %q is an alias of group with id %d: %w", name, owner, ErrConflict)
+		}
+
+		query := `UPDATE groups SET "group" = $2, name_key = $3 WHERE id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": []interface{}{id, name, key},
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, id, name, key)
+		if err != nil {
+			logrus.WithError(err).Error("Error renaming group")
+			return fmt.Errorf("error renaming group with id %d: %w", id, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("id", id).Info("Group not found")
+			return fmt.Errorf("group with id %d %w", id, ErrNotFound)
+		}
+
+		// Имя группы входит в представление её песен и песен, где она указана исполнителем,
+		// поэтому их версии (ETag) меняются вместе с именем
+		songsQuery := `UPDATE songs SET version = version + 1 WHERE group_id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  songsQuery,
+			"params": id,
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, songsQuery, id); err != nil {
+			logrus.WithError(err).Error("Error touching group songs")
+			return fmt.Errorf("error updating version of songs of group with id %d: %w", id, err)
+		}
+		if err := touchCreditedSongs(ctx, conn(ctx, r.db), []int{id}); err != nil {
+			return err
+		}
+
+		aliasQuery := `DELETE FROM group_aliases WHERE group_id = $1 AND name_key = $2`
+		logrus.WithFields(logrus.Fields{
+			"query":  aliasQuery,
+			"params": []interface{}{id, key},
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, aliasQuery, id, key); err != nil {
+			logrus.WithError(err).Error("Error deleting group alias")
+			return fmt.Errorf("error deleting group alias: %w", err)
+		}
+
+		group, err = r.getGroupByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return group, nil
+}
+
+// Метод для удаления группы по ID. Группа с песнями или альбомами удаляется только при cascade
+// (вместе с ними, как ON DELETE CASCADE в схеме), иначе возвращается ErrConflict.
+func (r *GroupsRepository) DeleteGroup(ctx context.Context, id int, cascade bool) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Блокировка группы не даёт параллельно добавить в неё песню до удаления
+		lockQuery := `SELECT id FROM groups WHERE id = $1 FOR UPDATE`
+		logrus.WithFields(logrus.Fields{
+			"query":  lockQuery,
+			"params": id,
+		}).Debug("Executing query")
+
+		var lockedID int
+		if err := conn(ctx, r.db).QueryRow(ctx, lockQuery, id).Scan(&lockedID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logrus.WithField("id", id).Info("Group not found")
+				return fmt.Errorf("group with id %d %w", id, ErrNotFound)
+			}
+			logrus.WithError(err).Error("Error locking group")
+			return fmt.Errorf("error locking group with id %d: %w", id, err)
+		}
+
+		if !cascade {
+			countQuery := `SELECT (SELECT COUNT(*) FROM songs WHERE group_id = $1), (SELECT COUNT(*) FROM albums WHERE group_id = $1)`
+			logrus.WithFields(logrus.Fields{
+				"query":  countQuery,
+				"params": id,
+			}).Debug("Executing query")
+
+			var songs, albums int
+			if err := conn(ctx, r.db).QueryRow(ctx, countQuery, id).Scan(&songs, &albums); err != nil {
+				logrus.WithError(err).Error("Error counting songs for group")
+				return fmt.Errorf("error counting songs for group with id %d: %w", id, err)
+			}
+			if songs > 0 || albums > 0 {
+				return fmt.Errorf("group with id %d has %d songs and %d albums: %w", id, songs, albums, ErrConflict)
+			}
+		}
+
+		// Песни других групп теряют эту группу среди исполнителей
+		if err := touchCreditedSongs(ctx, conn(ctx, r.db), []int{id}); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM groups WHERE id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": id,
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, query, id); err != nil {
+			logrus.WithError(err).Error("Error deleting group")
+			return fmt.Errorf("error deleting group with id %d: %w", id, mapPgError(err))
+		}
+
+		return nil
+	})
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -22,6 +24,8 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// Конфигурация полнотекстового поиска (regconfig); пустая оставляет сохранённую в базе, изначально russian
+	SearchLanguage string
 }
 
 // NewPostgres создает новое подключение к базе данных PostgreSQL и выполняет миграции
@@ -47,6 +51,11 @@ func NewPostgres(cfg Config) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("migration execution error: %w", err)
 	}
 	logrus.Printf("migration is created")
+
+	// Вектор поиска перестраивается, если конфигурация поиска изменилась
+	if err := applySearchLanguage(dbPool, cfg.SearchLanguage); err != nil {
+		return nil, fmt.Errorf("search language error: %w", err)
+	}
 	return dbPool, nil
 }
 
@@ -82,8 +91,37 @@ func runMigrations(dbPool *pgxpool.Pool) error {
 	if m == nil {
 		return fmt.Errorf("мигратор не инициализирован")
 	}
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("ошибка при выполнении миграций: %w", err)
+	// Миграции выполняются по одной, чтобы после нужных версий выполнить миграции данных
+	for {
+		previous := database.NilVersion
+		if version, _, err := m.Version(); err == nil {
+			previous = int(version)
+		} else if !errors.Is(err, migrate.ErrNilVersion) {
+			return fmt.Errorf("ошибка при получении версии миграций: %w", err)
+		}
+
+		if err := m.Steps(1); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("ошибка при выполнении миграций: %w", err)
+		}
+
+		version, _, err := m.Version()
+		if err != nil {
+			return fmt.Errorf("ошибка при получении версии миграций: %w", err)
+		}
+		migrateData, ok := dataMigrations[version]
+		if !ok {
+			continue
+		}
+		if err := runDataMigration(dbPool, version, migrateData); err != nil {
+			// Версия возвращается назад, чтобы при следующем запуске повторяемая SQL-миграция
+			// выполнилась снова вместе с миграцией данных
+			if forceErr := m.Force(previous); forceErr != nil {
+				logrus.WithError(forceErr).Error("Ошибка при возврате версии миграций")
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -1,32 +1,183 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/Ktuty/internal/models"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 // Интерфейс Songs, определяющий методы для работы с песнями
 type Songs interface {
-	// Метод для получения всех песен с фильтрацией, пагинацией и возвратом общего количества страниц
-	GetAllSongs(filter models.Songs, page, pageSize int) ([]models.Songs, int, error)
+	// Метод для получения всех песен с фильтрацией, сортировкой, пагинацией и возвратом общего количества страниц
+	GetAllSongs(ctx context.Context, filter models.SongFilter, sort []models.SortField, page, pageSize int) ([]models.Songs, int, error)
+	// Метод для получения страницы песен по курсору с признаком наличия следующих строк
+	GetSongsByCursor(ctx context.Context, filter models.SongFilter, sort []models.SortField, cursor *models.Cursor, limit int) ([]models.Songs, bool, error)
+	// Метод для получения количества песен, подходящих под фильтр
+	CountSongs(ctx context.Context, filter models.SongFilter) (int, error)
+	// Метод для потоковой выгрузки всех песен, подходящих под фильтр, в порядке сортировки
+	ExportSongs(ctx context.Context, filter models.SongFilter, sort []models.SortField, fn func(song models.Songs) error) error
+	// Метод для полнотекстового поиска по текстам песен с ранжированием и фрагментами куплетов
+	SearchSongs(ctx context.Context, query string, page, pageSize int) ([]models.SearchResult, int, error)
+	// Метод для подбора ближайших по написанию названия песни и имени группы для фильтра
+	SuggestSongs(ctx context.Context, filter models.SongFilter) (*models.Suggestion, error)
+	// Метод для поиска пар песен с похожими текстами, от самых похожих, с пагинацией
+	FindDuplicateSongs(ctx context.Context, threshold float64, page, pageSize int) ([]models.SongDuplicate, bool, error)
 	// Метод для получения песни по ID
-	GetSongByID(id int) (models.Songs, error)
-	// Метод для создания новой песни
-	PostSong(song models.Songs) error
+	GetSongByID(ctx context.Context, id int) (models.Songs, error)
+	// Метод для создания новой песни, возвращает ID созданной песни;
+	// песня с тем же названием в группе приводит к *DuplicateSongError
+	PostSong(ctx context.Context, song models.Songs) (int, error)
 	// Метод для обновления песни по ID
-	UpdateSong(songID int, song models.Songs) error
-	// Метод для удаления песни по ID
-	DeleteSong(songID int) error
+	UpdateSong(ctx context.Context, songID int, song models.Songs) error
+	// Метод для вставки пакета проверенных песен; песни с повторяющимся в группе названием пропускаются
+	ImportSongs(ctx context.Context, songs []models.Songs) ([]models.ImportRow, error)
+	// Метод для замены всех полей и исполнителей песни по ID, включая пустые значения
+	ReplaceSong(ctx context.Context, songID int, song models.Songs) error
+	// Метод для проверки версии песни с блокировкой её до конца транзакции
+	CheckSongVersion(ctx context.Context, songID, version int) error
+	// Метод для перемещения песни в корзину по ID
+	DeleteSong(ctx context.Context, songID int) error
+	// Метод для восстановления песни из корзины по ID
+	RestoreSong(ctx context.Context, songID int) error
+	// Метод для окончательного удаления песен, перемещённых в корзину раньше before
+	PurgeDeletedSongs(ctx context.Context, before time.Time) (int, error)
+	// Метод для постановки песни в очередь обогащения сведениями внешнего API
+	QueueSongEnrichment(ctx context.Context, songID int) error
+	// Метод для захвата до limit песен, ожидающих обогащения, на время lease
+	ClaimSongEnrichments(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error)
+	// Метод для сохранения состояния обогащения песни
+	SetSongEnrichment(ctx context.Context, songID int, enrichment models.SongEnrichment) error
+}
+
+// Интерфейс Groups, определяющий методы для работы с группами
+type Groups interface {
+	// Метод для получения групп с фильтрацией по подстроке имени, пагинацией и возвратом общего количества страниц
+	GetAllGroups(ctx context.Context, name string, page, pageSize int) ([]models.Group, int, error)
+	// Метод для получения группы по ID
+	GetGroupByID(ctx context.Context, id int) (models.Group, error)
+	// Метод для создания новой группы
+	CreateGroup(ctx context.Context, name string) (models.Group, error)
+	// Метод для переименования группы вместе со всеми её песнями
+	RenameGroup(ctx context.Context, id int, name string) (models.Group, error)
+	// Метод для удаления группы по ID, с её песнями при cascade
+	DeleteGroup(ctx context.Context, id int, cascade bool) error
+	// Метод для добавления псевдонима группы
+	AddGroupAlias(ctx context.Context, groupID int, alias string) (models.Group, error)
+	// Метод для удаления псевдонима группы по ID
+	DeleteGroupAlias(ctx context.Context, groupID, aliasID int) error
+	// Метод для объединения исходных групп с целевой с переносом песен и псевдонимов
+	MergeGroups(ctx context.Context, targetID int, sourceIDs []int) (models.GroupMerge, error)
+}
+
+// Интерфейс Albums, определяющий методы для работы с альбомами
+type Albums interface {
+	// Метод для получения альбомов с фильтрацией, пагинацией и возвратом общего количества страниц
+	GetAllAlbums(ctx context.Context, filter models.AlbumFilter, page, pageSize int) ([]models.Album, int, error)
+	// Метод для получения альбома по ID вместе со списком треков
+	GetAlbumByID(ctx context.Context, id int) (models.Album, error)
+	// Метод для создания нового альбома
+	CreateAlbum(ctx context.Context, album models.Album) (models.Album, error)
+	// Метод для обновления альбома по ID
+	UpdateAlbum(ctx context.Context, id int, album models.Album) (models.Album, error)
+	// Метод для удаления альбома по ID
+	DeleteAlbum(ctx context.Context, id int) error
+	// Метод для замены списка треков альбома
+	SetAlbumTracks(ctx context.Context, id int, tracks []models.AlbumTrack) (models.Album, error)
+	// Метод для помещения песни в альбом под номером трека; nil исключает песню из альбома
+	SetSongAlbum(ctx context.Context, songID int, album *models.SongAlbum) error
 }
 
-// Структура Repository, реализующая интерфейс Songs
+// Интерфейс Tags, определяющий методы для работы с жанрами и тегами песен
+type Tags interface {
+	// Метод для получения жанров с количеством песен, фильтрацией по подстроке имени и пагинацией
+	GetAllGenres(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error)
+	// Метод для добавления песне жанров; отсутствующие жанры создаются
+	AddSongGenres(ctx context.Context, songID int, names []string) error
+	// Метод для замены всех жанров песни; отсутствующие жанры создаются
+	SetSongGenres(ctx context.Context, songID int, names []string) error
+	// Метод для удаления жанра у песни
+	DeleteSongGenre(ctx context.Context, songID int, name string) error
+	// Метод для получения тегов с количеством песен, фильтрацией по подстроке имени и пагинацией
+	GetAllTags(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error)
+	// Метод для добавления песне тегов; отсутствующие теги создаются
+	AddSongTags(ctx context.Context, songID int, names []string) error
+	// Метод для замены всех тегов песни; отсутствующие теги создаются
+	SetSongTags(ctx context.Context, songID int, names []string) error
+	// Метод для удаления тега у песни
+	DeleteSongTag(ctx context.Context, songID int, name string) error
+}
+
+// Интерфейс Revisions, определяющий методы для работы с историей изменений песен
+type Revisions interface {
+	// Метод для сохранения новой ревизии песни со следующим по порядку номером
+	AddSongRevision(ctx context.Context, revision models.Revision) (models.Revision, error)
+	// Метод для сохранения текущего состояния новых песен как их первых ревизий
+	AddInitialSongRevisions(ctx context.Context, songIDs []int, author string) error
+	// Метод для получения ревизий песни без снимков, от новых к старым, с пагинацией
+	GetSongRevisions(ctx context.Context, songID, page, pageSize int) ([]models.Revision, int, error)
+	// Метод для получения ревизии песни по номеру вместе со снимком
+	GetSongRevision(ctx context.Context, songID, revision int) (models.Revision, error)
+}
+
+// Интерфейс Audit, определяющий методы для работы с журналом аудита изменений
+type Audit interface {
+	// Метод для добавления записи в журнал аудита
+	AddAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	// Метод для добавления пакета записей в журнал аудита
+	AddAuditEntries(ctx context.Context, entries []models.AuditEntry) error
+	// Метод для получения записей журнала аудита с фильтрацией, от новых к старым, с пагинацией
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, int, error)
+}
+
+// Структура Repository, реализующая интерфейсы Songs, Groups, Albums, Tags, Revisions, Audit и Transactor
 type Repository struct {
 	Songs
+	Groups
+	Albums
+	Tags
+	Revisions
+	Audit
+	Transactor
+}
+
+// Структура Options с настройками репозитория PostgreSQL
+type Options struct {
+	// Ограничение времени выполнения запросов в рамках одного вызова (0 - без ограничения)
+	QueryTimeout time.Duration
+	// Порог схожести триграмм (0..1) для нечёткого поиска и сопоставления групп, по умолчанию 0.4
+	FuzzyThreshold float64
 }
 
 // Функция для создания нового экземпляра Repository с подключением к базе данных
-func NewRepository(db *pgxpool.Pool) *Repository {
+func NewRepository(db *pgxpool.Pool, opts Options) *Repository {
+	if opts.FuzzyThreshold <= 0 {
+		opts.FuzzyThreshold = defaultFuzzyThreshold
+	}
+
+	transactor := NewPgTransactor(db)
+	return &Repository{
+		Songs:      NewSongsRepository(db, transactor, opts),     // Инициализация репозитория песен с подключением к базе данных
+		Groups:     NewGroupsRepository(db, transactor, opts),    // Инициализация репозитория групп с подключением к базе данных
+		Albums:     NewAlbumsRepository(db, transactor, opts),    // Инициализация репозитория альбомов с подключением к базе данных
+		Tags:       NewTagsRepository(db, transactor, opts),      // Инициализация репозитория жанров и тегов с подключением к базе данных
+		Revisions:  NewRevisionsRepository(db, transactor, opts), // Инициализация репозитория ревизий песен с подключением к базе данных
+		Audit:      NewAuditRepository(db, transactor, opts),     // Инициализация журнала аудита с подключением к базе данных
+		Transactor: transactor,                                   // Транзакции поверх того же пула соединений
+	}
+}
+
+// Функция для создания нового экземпляра Repository, хранящего данные в памяти
+func NewMemoryRepository() *Repository {
+	songs := NewSongsMemory()
 	return &Repository{
-		Songs: NewSongsRepository(db), // Инициализация репозитория песен с подключением к базе данных
+		Songs:      songs, // Инициализация репозитория песен без подключения к базе данных
+		Groups:     songs, // Группы хранятся вместе с песнями
+		Albums:     songs, // Альбомы хранятся вместе с песнями
+		Tags:       songs, // Жанры и теги хранятся вместе с песнями
+		Revisions:  songs, // Ревизии хранятся вместе с песнями
+		Audit:      songs, // Журнал аудита хранится вместе с песнями
+		Transactor: songs, // Транзакции над тем же хранилищем в памяти
 	}
 }
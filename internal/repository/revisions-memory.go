@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для сохранения новой ревизии песни со следующим по порядку номером
+func (m *SongsMemory) AddSongRevision(ctx context.Context, revision models.Revision) (models.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Revision{}, fmt.Errorf("SongsMemory.AddSongRevision: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[revision.SongID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", revision.SongID).Info("Song not found")
+		return models.Revision{}, fmt.Errorf("song with id %d %w", revision.SongID, ErrNotFound)
+	}
+
+	revision.Revision = len(m.revisions[revision.SongID]) + 1
+	revision.CreatedAt = time.Now()
+	if revision.Snapshot != nil {
+		snapshot := *revision.Snapshot
+		snapshot.Artists = append([]models.SongArtist{}, snapshot.Artists...)
+		revision.Snapshot = &snapshot
+	}
+	m.revisions[revision.SongID] = append(m.revisions[revision.SongID], revision)
+
+	return revision, nil
+}
+
+// Метод для сохранения текущего состояния новых песен как их первых ревизий
+func (m *SongsMemory) AddInitialSongRevisions(ctx context.Context, songIDs []int, author string) error {
+	for _, songID := range songIDs {
+		song, err := m.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		snapshot := models.NewSongSnapshot(song)
+		if _, err := m.AddSongRevision(ctx, models.Revision{SongID: songID, Author: author, Snapshot: &snapshot}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Метод для получения ревизий песни без снимков, от новых к старым, с пагинацией
+func (m *SongsMemory) GetSongRevisions(ctx context.Context, songID, page, pageSize int) ([]models.Revision, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.GetSongRevisions invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetSongRevisions: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	stored := m.revisions[songID]
+
+	// Вычисление общего количества страниц
+	totalPages := (len(stored) + pageSize - 1) / pageSize
+
+	revisions := []models.Revision{}
+	for i := len(stored) - 1 - offset; i >= 0 && len(revisions) < pageSize; i-- {
+		revision := stored[i]
+		revision.Snapshot = nil
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, totalPages, nil
+}
+
+// Метод для получения ревизии песни по номеру вместе со снимком
+func (m *SongsMemory) GetSongRevision(ctx context.Context, songID, revision int) (models.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Revision{}, fmt.Errorf("SongsMemory.GetSongRevision: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	stored := m.revisions[songID]
+	if revision < 1 || revision > len(stored) {
+		logrus.WithFields(logrus.Fields{"songID": songID, "revision": revision}).Info("Song revision not found")
+		return models.Revision{}, fmt.Errorf("revision %d of song with id %d %w", revision, songID, ErrNotFound)
+	}
+
+	result := stored[revision-1]
+	if result.Snapshot != nil {
+		snapshot := *result.Snapshot
+		snapshot.Artists = append([]models.SongArtist{}, snapshot.Artists...)
+		result.Snapshot = &snapshot
+	}
+
+	return result, nil
+}
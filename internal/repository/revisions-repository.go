@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура RevisionsRepository, которая инкапсулирует подключение к базе данных
+type RevisionsRepository struct {
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
+}
+
+// Функция для создания нового экземпляра RevisionsRepository с подключением к базе данных
+func NewRevisionsRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *RevisionsRepository {
+	return &RevisionsRepository{db: db, transactor: transactor, opts: opts}
+}
+
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *RevisionsRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для сохранения новой ревизии песни со следующим по порядку номером.
+// Песня блокируется, чтобы параллельные изменения не получили одинаковый номер.
+func (r *RevisionsRepository) AddSongRevision(ctx context.Context, revision models.Revision) (models.Revision, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := lockSong(ctx, conn(ctx, r.db), revision.SongID); err != nil {
+			return err
+		}
+
+		query := `
+		INSERT INTO song_revisions (song_id, revision, author, reverted_from, snapshot)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4 FROM song_revisions WHERE song_id = $1
+		RETURNING revision, created_at`
+		args := []interface{}{revision.SongID, revision.Author, revision.RevertedFrom, revision.Snapshot}
+
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
+
+		err := conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(&revision.Revision, &revision.CreatedAt)
+		if err != nil {
+			logrus.WithError(err).Error("Error inserting song revision")
+			return fmt.Errorf("error inserting revision of song with id %d: %w", revision.SongID, mapPgError(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	return revision, nil
+}
+
+// Метод для сохранения текущего состояния новых песен как их первых ревизий одним запросом;
+// снимок строится так же, как models.NewSongSnapshot
+func (r *RevisionsRepository) AddInitialSongRevisions(ctx context.Context, songIDs []int, author string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+	INSERT INTO song_revisions (song_id, revision, author, snapshot)
+	SELECT s.id, 1, $2, jsonb_build_object(
+		'song', s.song,
+		'group', g."group",
+		'text', s.text,
+		'releaseDate', COALESCE(to_char(s.release_date, 'DD.MM.YYYY'), ''),
+		'link', s.link,
+		'artists', COALESCE((SELECT jsonb_agg(jsonb_build_object('group', ag."group", 'role', sa.role)
+			ORDER BY array_position(ARRAY['featured', 'composer', 'lyricist'], sa.role::text), ag."group")
+			FROM song_artists sa INNER JOIN groups ag ON sa.group_id = ag.id
+			WHERE sa.song_id = s.id AND sa.role <> 'primary'), '[]'::jsonb),
+		'genres', to_jsonb(` + genreKind.songColumn() + `),
+		'tags', to_jsonb(` + tagKind.songColumn() + `),
+		'album', (SELECT jsonb_build_object('id', a.id, 'title', a.title, 'trackNumber', s.track_number)
+			FROM albums a WHERE a.id = s.album_id))
+	FROM songs s
+	INNER JOIN groups g ON s.group_id = g.id
+	WHERE s.id = ANY($1)`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{len(songIDs), author},
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, songIDs, author); err != nil {
+		logrus.WithError(err).Error("Error inserting initial song revisions")
+		return fmt.Errorf("error inserting initial revisions of %d songs: %w", len(songIDs), mapPgError(err))
+	}
+
+	return nil
+}
+
+// Метод для получения ревизий песни без снимков, от новых к старым, с пагинацией
+func (r *RevisionsRepository) GetSongRevisions(ctx context.Context, songID, page, pageSize int) ([]models.Revision, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * pageSize
+	query := `
+	SELECT song_id, revision, author, created_at, reverted_from
+	FROM song_revisions
+	WHERE song_id = $1
+	ORDER BY revision DESC
+	LIMIT $2 OFFSET $3`
+	args := []interface{}{songID, pageSize, offset}
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("RevisionsRepository query error")
+		return nil, 0, fmt.Errorf("RevisionsRepository.GetSongRevisions query error: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+	for rows.Next() {
+		var revision models.Revision
+		if err := rows.Scan(&revision.SongID, &revision.Revision, &revision.Author, &revision.CreatedAt, &revision.RevertedFrom); err != nil {
+			logrus.WithError(err).Error("RevisionsRepository scan error")
+			return nil, 0, fmt.Errorf("RevisionsRepository.GetSongRevisions scan error: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("RevisionsRepository rows error")
+		return nil, 0, fmt.Errorf("RevisionsRepository.GetSongRevisions rows error: %w", err)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM song_revisions WHERE song_id = $1`
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": songID,
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, songID).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("RevisionsRepository count query error")
+		return nil, 0, fmt.Errorf("RevisionsRepository.GetSongRevisions count query error: %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return revisions, totalPages, nil
+}
+
+// Метод для получения ревизии песни по номеру вместе со снимком
+func (r *RevisionsRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.Revision, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+	SELECT song_id, revision, author, created_at, reverted_from, snapshot
+	FROM song_revisions
+	WHERE song_id = $1 AND revision = $2`
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{songID, revision},
+	}).Debug("Executing query")
+
+	var result models.Revision
+	err := conn(ctx, r.db).QueryRow(ctx, query, songID, revision).Scan(&result.SongID, &result.Revision, &result.Author,
+		&result.CreatedAt, &result.RevertedFrom, &result.Snapshot)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.WithFields(logrus.Fields{"songID": songID, "revision": revision}).Info("Song revision not found")
+			return models.Revision{}, fmt.Errorf("revision %d of song with id %d %w", revision, songID, ErrNotFound)
+		}
+		logrus.WithError(err).Error("RevisionsRepository.GetSongRevision query error")
+		return models.Revision{}, fmt.Errorf("RevisionsRepository.GetSongRevision query error: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Функция для записи основного исполнителя песни; songs.group_id и строка с ролью primary
+// в song_artists всегда указывают на одну группу
+func setPrimaryArtist(ctx context.Context, q querier, songID, groupID int) error {
+	query := `INSERT INTO song_artists (song_id, group_id, role) VALUES ($1, $2, 'primary')
+	ON CONFLICT (song_id) WHERE role = 'primary' DO UPDATE SET group_id = EXCLUDED.group_id`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{songID, groupID},
+	}).Debug("Executing query")
+
+	if _, err := q.Exec(ctx, query, songID, groupID); err != nil {
+		logrus.WithError(err).Error("Error setting primary artist")
+		return fmt.Errorf("error setting primary artist of song with id %d: %w", songID, mapPgError(err))
+	}
+	return nil
+}
+
+// Функция для замены дополнительных исполнителей песни. Группы находятся или создаются по имени,
+// как основная группа песни.
+func setSongCredits(ctx context.Context, q querier, songID int, artists []models.SongArtist) error {
+	query := `DELETE FROM song_artists WHERE song_id = $1 AND role <> 'primary'`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	if _, err := q.Exec(ctx, query, songID); err != nil {
+		logrus.WithError(err).Error("Error clearing song credits")
+		return fmt.Errorf("error clearing credits of song with id %d: %w", songID, err)
+	}
+
+	query = `INSERT INTO song_artists (song_id, group_id, role) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	for _, artist := range artists {
+		groupID, err := ensureGroupExists(ctx, q, artist.Group)
+		if err != nil {
+			logrus.WithError(err).Error("Error ensuring group exists")
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": []interface{}{songID, groupID, artist.Role},
+		}).Debug("Executing query")
+
+		if _, err := q.Exec(ctx, query, songID, groupID, artist.Role); err != nil {
+			logrus.WithError(err).Error("Error inserting song credit")
+			return fmt.Errorf("error crediting %q as %s on song with id %d: %w", artist.Group, artist.Role, songID, mapPgError(err))
+		}
+	}
+	return nil
+}
+
+// Функция для увеличения версии песни при изменении связанных с ней данных; строка песни
+// блокируется до конца транзакции. ErrNotFound, если песни нет или она в корзине
+func touchSong(ctx context.Context, q querier, songID int) error {
+	query := `UPDATE songs SET version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	tag, err := q.Exec(ctx, query, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Error touching song")
+		return fmt.Errorf("error updating version of song with id %d: %w", songID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+	return nil
+}
+
+// Функция для блокировки строки песни до конца транзакции; ErrNotFound, если песни нет или она в корзине
+func lockSong(ctx context.Context, q querier, songID int) error {
+	query := `SELECT id FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	var id int
+	if err := q.QueryRow(ctx, query, songID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.WithField("songID", songID).Info("Song not found")
+			return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+		}
+		logrus.WithError(err).Error("Error locking song")
+		return fmt.Errorf("error locking song with id %d: %w", songID, err)
+	}
+	return nil
+}
+
+// Функция для увеличения версии песен, у которых группы groupIDs указаны дополнительными исполнителями,
+// а основная группа другая: имя группы входит в представление этих песен
+func touchCreditedSongs(ctx context.Context, q querier, groupIDs []int) error {
+	query := `
+	UPDATE songs SET version = version + 1
+	WHERE NOT group_id = ANY($1) AND id IN (SELECT song_id FROM song_artists WHERE group_id = ANY($1))`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": groupIDs,
+	}).Debug("Executing query")
+
+	if _, err := q.Exec(ctx, query, groupIDs); err != nil {
+		logrus.WithError(err).Error("Error touching credited songs")
+		return fmt.Errorf("error updating version of songs credited to groups %v: %w", groupIDs, err)
+	}
+	return nil
+}
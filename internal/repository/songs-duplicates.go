@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Функция для проверки, что в группе нет другой песни вне корзины с тем же ключом названия.
+// Нулевые groupID и пустой title берутся у песни exceptID. Возвращает *DuplicateSongError при совпадении.
+func checkDuplicateSong(ctx context.Context, q querier, groupID int, title string, exceptID int) error {
+	var titleKey string
+	if title != "" {
+		titleKey = models.SongKey(title)
+	}
+
+	query := `
+	SELECT d.id FROM songs d
+	LEFT JOIN songs s ON s.id = $3
+	WHERE d.group_id = COALESCE(NULLIF($1::int, 0), s.group_id)
+		AND d.title_key = COALESCE(NULLIF($2::text, ''), s.title_key)
+		AND d.id <> $3 AND d.deleted_at IS NULL
+	LIMIT 1`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{groupID, titleKey, exceptID},
+	}).Debug("Executing query")
+
+	var existingID int
+	err := q.QueryRow(ctx, query, groupID, titleKey, exceptID).Scan(&existingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		logrus.WithError(err).Error("Error checking duplicate song")
+		return fmt.Errorf("error checking duplicate song: %w", err)
+	}
+
+	logrus.WithField("existingID", existingID).Info("Duplicate song")
+	return &DuplicateSongError{ExistingID: existingID}
+}
+
+// Метод для поиска пар песен вне корзины с похожими текстами: схожесть триграмм не ниже threshold.
+// Пары упорядочены от самых похожих, с пагинацией; общее количество пар не считается,
+// вместо него возвращается признак следующей страницы.
+func (r *SongsRepository) FindDuplicateSongs(ctx context.Context, threshold float64, page, pageSize int) ([]models.SongDuplicate, bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// Для каждой песни похожие тексты с большим id ищутся оператором % по индексу
+	// idx_songs_text_trgm, поэтому сравниваются только пары, отобранные индексом
+	offset := (page - 1) * pageSize
+	query := `
+	WITH pairs AS (
+		SELECT a.id AS first_id, b.id AS second_id, similarity(a.text, b.text) AS score
+		FROM songs a
+		JOIN songs b ON b.text % a.text AND b.id > a.id AND b.deleted_at IS NULL AND b.text <> ''
+		WHERE a.deleted_at IS NULL AND a.text <> ''
+	)
+	SELECT p.first_id, a.song, ga."group", p.second_id, b.song, gb."group", p.score
+	FROM pairs p
+	JOIN songs a ON a.id = p.first_id
+	JOIN groups ga ON ga.id = a.group_id
+	JOIN songs b ON b.id = p.second_id
+	JOIN groups gb ON gb.id = b.group_id
+	ORDER BY p.score DESC, p.first_id, p.second_id
+	LIMIT $1 OFFSET $2`
+	// Лишняя пара показывает, есть ли следующая страница
+	args := []interface{}{pageSize + 1, offset}
+
+	duplicates := []models.SongDuplicate{}
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.setSimilarityThreshold(ctx, threshold); err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
+
+		rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+		if err != nil {
+			logrus.WithError(err).Error("SongsRepository duplicates query error")
+			return fmt.Errorf("SongsRepository.FindDuplicateSongs query error: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var duplicate models.SongDuplicate
+			var score float32
+			if err := rows.Scan(&duplicate.First.ID, &duplicate.First.Song, &duplicate.First.Group,
+				&duplicate.Second.ID, &duplicate.Second.Song, &duplicate.Second.Group, &score); err != nil {
+				logrus.WithError(err).Error("SongsRepository duplicates scan error")
+				return fmt.Errorf("SongsRepository.FindDuplicateSongs scan error: %w", err)
+			}
+			duplicate.Similarity = float64(score)
+			duplicates = append(duplicates, duplicate)
+		}
+		if err := rows.Err(); err != nil {
+			logrus.WithError(err).Error("SongsRepository duplicates rows error")
+			return fmt.Errorf("SongsRepository.FindDuplicateSongs rows error: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(duplicates) > pageSize {
+		return duplicates[:pageSize], true, nil
+	}
+	return duplicates, false, nil
+}
+
+// Метод для установки порога pg_trgm.similarity_threshold оператора % в текущей транзакции
+func (r *SongsRepository) setSimilarityThreshold(ctx context.Context, threshold float64) error {
+	value := strconv.FormatFloat(threshold, 'f', -1, 64)
+
+	query := `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": value,
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, value); err != nil {
+		logrus.WithError(err).Error("Error setting similarity threshold")
+		return fmt.Errorf("error setting similarity threshold: %w", err)
+	}
+	return nil
+}
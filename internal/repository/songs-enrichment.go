@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для постановки песни вне корзины в очередь обогащения: счётчик попыток и ошибка сбрасываются,
+// первая попытка выполняется сразу. Статус, счётчик попыток и ошибка обогащения входят в представление
+// песни, поэтому при их изменении увеличивается версия песни; перенос срока следующей попытки версию
+// не меняет, чтобы работа обработчика не отклоняла условные запросы клиентов.
+func (r *SongsRepository) QueueSongEnrichment(ctx context.Context, songID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+	UPDATE songs SET enrichment_status = 'pending', enrichment_attempts = 0, enrichment_error = NULL,
+		enrichment_next_attempt_at = now(), enrichment_updated_at = now(), version = version + 1
+	WHERE id = $1 AND deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Error queueing song enrichment")
+		return fmt.Errorf("error queueing enrichment of song %d: %w", songID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+	return nil
+}
+
+// Метод для захвата до limit песен вне корзины, ожидающих обогащения, срок попытки которых наступил.
+// Следующая попытка захваченных песен откладывается на lease, поэтому песни, обработка которых
+// прервалась, будут захвачены снова; занятые другими транзакциями строки пропускаются.
+func (r *SongsRepository) ClaimSongEnrichments(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+	UPDATE songs s SET enrichment_next_attempt_at = now() + $2 * interval '1 millisecond'
+	FROM groups g
+	WHERE s.group_id = g.id AND s.id IN (
+		SELECT id FROM songs
+		WHERE enrichment_status = 'pending' AND deleted_at IS NULL AND enrichment_next_attempt_at <= now()
+		ORDER BY enrichment_next_attempt_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED)
+	RETURNING s.id, g."group", s.song, s.enrichment_attempts`
+	args := []interface{}{limit, lease.Milliseconds()}
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Error claiming song enrichments")
+		return nil, fmt.Errorf("error claiming song enrichments: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.EnrichmentJob
+	for rows.Next() {
+		var job models.EnrichmentJob
+		if err := rows.Scan(&job.SongID, &job.Group, &job.Song, &job.Attempts); err != nil {
+			logrus.WithError(err).Error("Error scanning enrichment job")
+			return nil, fmt.Errorf("error scanning enrichment job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error iterating enrichment jobs")
+		return nil, fmt.Errorf("error iterating enrichment jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Метод для сохранения состояния обогащения песни вне корзины. Время изменения состояния устанавливается
+// базой; оно и версия песни меняются, только если изменились статус, счётчик попыток или ошибка.
+func (r *SongsRepository) SetSongEnrichment(ctx context.Context, songID int, enrichment models.SongEnrichment) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// Выражения SET видят значения строки до изменения
+	changed := `(enrichment_status IS DISTINCT FROM $2 OR enrichment_attempts IS DISTINCT FROM $3
+		OR enrichment_error IS DISTINCT FROM NULLIF($4, ''))`
+	query := `
+	UPDATE songs SET enrichment_status = $2, enrichment_attempts = $3, enrichment_error = NULLIF($4, ''),
+		enrichment_next_attempt_at = $5,
+		enrichment_updated_at = CASE WHEN ` + changed + ` THEN now() ELSE enrichment_updated_at END,
+		version = CASE WHEN ` + changed + ` THEN version + 1 ELSE version END
+	WHERE id = $1 AND deleted_at IS NULL`
+	args := []interface{}{songID, enrichment.Status, enrichment.Attempts, enrichment.Error, enrichment.NextAttemptAt}
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Error setting song enrichment")
+		return fmt.Errorf("error setting enrichment of song %d: %w", songID, mapPgError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество песен, которое выгрузка читает из курсора за один запрос
+const exportFetchSize = 500
+
+// Метод для выгрузки всех песен, подходящих под фильтр, в порядке сортировки. Песни читаются
+// из серверного курсора порциями по exportFetchSize и передаются в fn по одной, поэтому память
+// не зависит от размера библиотеки. Ошибка fn прерывает выгрузку. Ограничение времени запроса
+// действует на каждую порцию, а не на всю выгрузку.
+func (r *SongsRepository) ExportSongs(ctx context.Context, filter models.SongFilter, sort []models.SortField, fn func(song models.Songs) error) error {
+	orderBy, err := songsOrderBy(sort, false)
+	if err != nil {
+		return fmt.Errorf("SongsRepository.ExportSongs: %w", err)
+	}
+	if filter.Fuzzy && filter.HasNameFilter() {
+		orderBy = `score DESC, ` + orderBy
+	}
+
+	var args queryArgs
+	query := `
+	DECLARE songs_export NO SCROLL CURSOR FOR` + songsSelect(filter, &args) + `
+	WHERE ` + songsWhere(filter, &args) + `
+	ORDER BY ` + orderBy
+
+	// Курсор существует только внутри транзакции
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return r.withFuzzyThreshold(ctx, filter.Fuzzy, func(ctx context.Context) error {
+			if err := r.execExport(ctx, query, args); err != nil {
+				return err
+			}
+			for {
+				songs, err := r.fetchExportSongs(ctx)
+				if err != nil {
+					return err
+				}
+				for _, song := range songs {
+					if err := fn(song); err != nil {
+						return err
+					}
+				}
+				if len(songs) < exportFetchSize {
+					break
+				}
+			}
+			return r.execExport(ctx, `CLOSE songs_export`, nil)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("SongsRepository.ExportSongs %w", err)
+	}
+	return nil
+}
+
+// Метод для открытия или закрытия курсора выгрузки
+func (r *SongsRepository) execExport(ctx context.Context, query string, args queryArgs) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, args...); err != nil {
+		logrus.WithError(err).Error("Error executing export cursor query")
+		return fmt.Errorf("export cursor error: %w", err)
+	}
+	return nil
+}
+
+// Метод для чтения следующей порции песен из курсора выгрузки. Порция читается целиком до передачи
+// песен в fn, чтобы медленный получатель не держал открытым запрос с ограничением времени.
+func (r *SongsRepository) fetchExportSongs(ctx context.Context) ([]models.Songs, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	songs, err := r.querySongs(ctx, `FETCH FORWARD `+strconv.Itoa(exportFetchSize)+` FROM songs_export`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("export fetch error: %w", err)
+	}
+	return songs, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Порог схожести триграмм по умолчанию для нечёткого поиска.
+// Ниже значения pg_trgm (0.6), чтобы находить опечатки в коротких именах ("Muze" -> "Muse").
+const defaultFuzzyThreshold = 0.4
+
+// Метод для выполнения fn с порогом pg_trgm.word_similarity_threshold из настроек репозитория.
+// Порог устанавливается только на время транзакции, вне режима fuzzy fn вызывается как есть.
+func (r *SongsRepository) withFuzzyThreshold(ctx context.Context, fuzzy bool, fn func(ctx context.Context) error) error {
+	if !fuzzy {
+		return fn(ctx)
+	}
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.setFuzzyThreshold(ctx); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+}
+
+// Метод для установки порога схожести триграмм в текущей транзакции
+func (r *SongsRepository) setFuzzyThreshold(ctx context.Context) error {
+	threshold := strconv.FormatFloat(r.opts.FuzzyThreshold, 'f', -1, 64)
+
+	query := `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": threshold,
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, threshold); err != nil {
+		logrus.WithError(err).Error("Error setting similarity threshold")
+		return fmt.Errorf("error setting similarity threshold: %w", err)
+	}
+	return nil
+}
+
+// Метод для подбора ближайших по написанию названия песни и имени группы
+// для фильтров song и group. Возвращает nil, если похожих имён нет.
+func (r *SongsRepository) SuggestSongs(ctx context.Context, filter models.SongFilter) (*models.Suggestion, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var suggestion models.Suggestion
+	err := r.withFuzzyThreshold(ctx, true, func(ctx context.Context) error {
+		var err error
+		if filter.Song != "" {
+			suggestion.Song, err = r.mostSimilar(ctx, `SELECT song FROM songs WHERE $1 <% song AND deleted_at IS NULL
+			ORDER BY word_similarity($1, song) DESC, similarity($1, song) DESC, id LIMIT 1`, filter.Song)
+			if err != nil {
+				return err
+			}
+		}
+		if filter.Group != "" {
+			suggestion.Group, err = r.mostSimilar(ctx, `SELECT "group" FROM groups WHERE $1 <% "group"
+			ORDER BY word_similarity($1, "group") DESC, similarity($1, "group") DESC, id LIMIT 1`, filter.Group)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SongsRepository.SuggestSongs %w", err)
+	}
+
+	if suggestion == (models.Suggestion{}) {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// Метод для выполнения запроса ближайшего имени; пустая строка, если похожих имён нет
+func (r *SongsRepository) mostSimilar(ctx context.Context, query, value string) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": value,
+	}).Debug("Executing query")
+
+	var name string
+	err := conn(ctx, r.db).QueryRow(ctx, query, value).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		logrus.WithError(err).Error("Error querying similar name")
+		return "", fmt.Errorf("error querying similar name: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура songIdentity - группа и ключ названия, по которым песни не повторяются
+type songIdentity struct {
+	groupID  int
+	titleKey string
+}
+
+// Метод для вставки пакета проверенных песен через COPY. Песня, название которой совпадает с песней
+// группы вне корзины или с песней раньше в пакете, пропускается. Вставленные песни получают статус
+// обогащения imported и не ставятся в очередь. Возвращает результат по каждой песне в порядке пакета
+// (без номеров строк).
+func (r *SongsRepository) ImportSongs(ctx context.Context, songs []models.Songs) ([]models.ImportRow, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows := make([]models.ImportRow, len(songs))
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		q := conn(ctx, r.db)
+
+		// Убедиться, что группы существуют или создать их, по одному запросу на группу
+		groupIDs := make(map[string]int)
+		identities := make([]songIdentity, len(songs))
+		for i, song := range songs {
+			key := models.GroupKey(song.Group)
+			groupID, ok := groupIDs[key]
+			if !ok {
+				var err error
+				if groupID, err = ensureGroupExists(ctx, q, song.Group); err != nil {
+					logrus.WithError(err).Error("Error ensuring group exists")
+					return err
+				}
+				groupIDs[key] = groupID
+			}
+			identities[i] = songIdentity{groupID: groupID, titleKey: models.SongKey(song.Song)}
+		}
+
+		existing, err := existingSongIDs(ctx, q, identities)
+		if err != nil {
+			return err
+		}
+
+		// Повторы внутри пакета ссылаются на первую песню с тем же названием
+		var pending []int
+		firstPending := make(map[songIdentity]int)
+		duplicateOf := make(map[int]int)
+		for i, identity := range identities {
+			if id, ok := existing[identity]; ok {
+				rows[i] = models.ImportRow{Status: models.ImportSkipped, ExistingID: id}
+				continue
+			}
+			if first, ok := firstPending[identity]; ok {
+				duplicateOf[i] = first
+				continue
+			}
+			firstPending[identity] = i
+			pending = append(pending, i)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ids, err := nextSongIDs(ctx, q, len(pending))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		songRows := make([][]interface{}, 0, len(pending))
+		artistRows := make([][]interface{}, 0, len(pending))
+		for n, i := range pending {
+			song := songs[i]
+			releaseDate, err := releaseDateValue(song.ReleaseDate)
+			if err != nil {
+				return err
+			}
+			var date interface{}
+			if releaseDate != nil {
+				date = *releaseDate
+			}
+
+			songRows = append(songRows, []interface{}{ids[n], identities[i].groupID, song.Song, identities[i].titleKey, song.Text, date, song.Link,
+				models.EnrichmentImported, now})
+			artistRows = append(artistRows, []interface{}{ids[n], identities[i].groupID, models.ArtistRolePrimary})
+			rows[i] = models.ImportRow{Status: models.ImportInserted, ID: ids[n]}
+		}
+		for i, first := range duplicateOf {
+			rows[i] = models.ImportRow{Status: models.ImportSkipped, ExistingID: rows[first].ID}
+		}
+
+		if err := copyRows(ctx, q, "songs", []string{"id", "group_id", "song", "title_key", "text", "release_date", "link",
+			"enrichment_status", "enrichment_updated_at"}, songRows); err != nil {
+			return err
+		}
+		if err := copyRows(ctx, q, "song_artists", []string{"song_id", "group_id", "role"}, artistRows); err != nil {
+			return err
+		}
+
+		// Дополнительные исполнители есть у немногих песен каталога и добавляются по одной песне
+		for _, i := range pending {
+			if len(songs[i].Artists) == 0 {
+				continue
+			}
+			if err := setSongCredits(ctx, q, rows[i].ID, songs[i].Artists); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// Функция для получения ID песен вне корзины с заданными группами и ключами названий
+func existingSongIDs(ctx context.Context, q querier, identities []songIdentity) (map[songIdentity]int, error) {
+	groupIDs := make([]int, len(identities))
+	titleKeys := make([]string, len(identities))
+	for i, identity := range identities {
+		groupIDs[i], titleKeys[i] = identity.groupID, identity.titleKey
+	}
+
+	query := `
+	SELECT s.id, s.group_id, s.title_key FROM songs s
+	INNER JOIN unnest($1::int[], $2::text[]) AS i(group_id, title_key)
+		ON s.group_id = i.group_id AND s.title_key = i.title_key
+	WHERE s.deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": len(identities),
+	}).Debug("Executing query")
+
+	rows, err := q.Query(ctx, query, groupIDs, titleKeys)
+	if err != nil {
+		logrus.WithError(err).Error("Error querying existing songs")
+		return nil, fmt.Errorf("error querying existing songs: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[songIdentity]int)
+	for rows.Next() {
+		var id int
+		var identity songIdentity
+		if err := rows.Scan(&id, &identity.groupID, &identity.titleKey); err != nil {
+			logrus.WithError(err).Error("Error scanning existing song")
+			return nil, fmt.Errorf("error scanning existing song: %w", err)
+		}
+		existing[identity] = id
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error iterating existing songs")
+		return nil, fmt.Errorf("error iterating existing songs: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Функция для выделения count новых ID песен из последовательности таблицы songs
+func nextSongIDs(ctx context.Context, q querier, count int) ([]int, error) {
+	query := `SELECT nextval(pg_get_serial_sequence('songs', 'id')) FROM generate_series(1, $1)`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": count,
+	}).Debug("Executing query")
+
+	rows, err := q.Query(ctx, query, count)
+	if err != nil {
+		logrus.WithError(err).Error("Error allocating song ids")
+		return nil, fmt.Errorf("error allocating song ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, count)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			logrus.WithError(err).Error("Error scanning song id")
+			return nil, fmt.Errorf("error scanning song id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error iterating song ids")
+		return nil, fmt.Errorf("error iterating song ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Функция для вставки строк в таблицу через COPY
+func copyRows(ctx context.Context, q querier, table string, columns []string, rows [][]interface{}) error {
+	logrus.WithFields(logrus.Fields{
+		"table":   table,
+		"columns": columns,
+		"rows":    len(rows),
+	}).Debug("Executing copy")
+
+	if _, err := q.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows)); err != nil {
+		logrus.WithError(err).Error("Error copying rows")
+		return fmt.Errorf("error copying rows into %s: %w", table, mapPgError(err))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Структура artistCredit, описывающая строку таблицы song_artists в памяти.
+// Основной исполнитель хранится в songRecord.groupID и в credits не попадает.
+type artistCredit struct {
+	groupID int
+	role    string
+}
+
+// Метод для преобразования исполнителей из запроса в записи хранилища; группы находятся
+// или создаются по имени, повторы отбрасываются, как ON CONFLICT DO NOTHING в SongsRepository
+func (m *SongsMemory) songCredits(artists []models.SongArtist) []artistCredit {
+	credits := make([]artistCredit, 0, len(artists))
+	for _, artist := range artists {
+		credit := artistCredit{groupID: m.ensureGroupExists(artist.Group), role: artist.Role}
+		if !slices.Contains(credits, credit) {
+			credits = append(credits, credit)
+		}
+	}
+	return credits
+}
+
+// Метод для получения исполнителей песни: основной первым, далее по ролям и именам, как songArtistsColumn
+func (m *SongsMemory) songArtists(record songRecord) []models.SongArtist {
+	artists := []models.SongArtist{{GroupID: record.groupID, Group: m.groups[record.groupID], Role: models.ArtistRolePrimary}}
+	for _, credit := range record.credits {
+		artists = append(artists, models.SongArtist{GroupID: credit.groupID, Group: m.groups[credit.groupID], Role: credit.role})
+	}
+	sort.SliceStable(artists[1:], func(i, j int) bool {
+		a, b := artists[1+i], artists[1+j]
+		if a.Role != b.Role {
+			return slices.Index(models.ArtistRoles, a.Role) < slices.Index(models.ArtistRoles, b.Role)
+		}
+		return a.Group < b.Group
+	})
+	return artists
+}
+
+// Метод для переноса дополнительных ролей исходных групп к целевой группе при объединении;
+// вызывается до переноса песен исходных групп
+func (m *SongsMemory) moveCredits(sources map[int]bool, targetID int) {
+	for id, record := range m.songs {
+		credits := make([]artistCredit, 0, len(record.credits))
+		changed := false
+		for _, credit := range record.credits {
+			if sources[credit.groupID] {
+				credit.groupID, changed = targetID, true
+			}
+			if !slices.Contains(credits, credit) {
+				credits = append(credits, credit)
+			}
+		}
+		if !changed {
+			continue
+		}
+		record.credits = credits
+		// Версия песен исходных групп увеличивается при их переносе
+		if !sources[record.groupID] {
+			record.version++
+		}
+		m.songs[id] = record
+	}
+}
+
+// Метод для удаления всех дополнительных ролей группы, как ON DELETE CASCADE в song_artists
+func (m *SongsMemory) deleteCredits(groupID int) {
+	for id, record := range m.songs {
+		credits := make([]artistCredit, 0, len(record.credits))
+		for _, credit := range record.credits {
+			if credit.groupID != groupID {
+				credits = append(credits, credit)
+			}
+		}
+		if len(credits) == len(record.credits) {
+			continue
+		}
+		record.credits = credits
+		record.version++
+		m.songs[id] = record
+	}
+}
+
+// Функция для проверки фильтра по группе: совпадение с любым исполнителем песни
+func artistsMatch(artists []models.SongArtist, filter string, fuzzy bool) bool {
+	if filter == "" {
+		return true
+	}
+	for _, artist := range artists {
+		if fuzzyMatches(artist.Group, filter, fuzzy) {
+			return true
+		}
+	}
+	return false
+}
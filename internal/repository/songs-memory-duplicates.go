@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для проверки, что в группе groupID нет другой песни вне корзины с тем же ключом названия,
+// как checkDuplicateSong. Возвращает *DuplicateSongError при совпадении.
+func (m *SongsMemory) checkDuplicateSong(groupID int, title string, exceptID int) error {
+	key := models.SongKey(title)
+	for id, record := range m.songs {
+		if id != exceptID && record.groupID == groupID && record.deletedAt.IsZero() && models.SongKey(record.song) == key {
+			logrus.WithField("existingID", id).Info("Duplicate song")
+			return &DuplicateSongError{ExistingID: id}
+		}
+	}
+	return nil
+}
+
+// Метод для поиска пар песен вне корзины с похожими текстами: схожесть триграмм не ниже threshold.
+// Пары упорядочены от самых похожих, с пагинацией и признаком следующей страницы.
+func (m *SongsMemory) FindDuplicateSongs(ctx context.Context, threshold float64, page, pageSize int) ([]models.SongDuplicate, bool, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, false, fmt.Errorf("SongsMemory.FindDuplicateSongs invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("SongsMemory.FindDuplicateSongs: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	var ids []int
+	for id, record := range m.songs {
+		if record.deletedAt.IsZero() && record.text != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	duplicates := []models.SongDuplicate{}
+	for i, firstID := range ids {
+		first := m.songs[firstID]
+		for _, secondID := range ids[i+1:] {
+			second := m.songs[secondID]
+			score := similarity(first.text, second.text)
+			if score < threshold {
+				continue
+			}
+			duplicates = append(duplicates, models.SongDuplicate{
+				First:      models.DuplicateSong{ID: firstID, Song: first.song, Group: m.groups[first.groupID]},
+				Second:     models.DuplicateSong{ID: secondID, Song: second.song, Group: m.groups[second.groupID]},
+				Similarity: score,
+			})
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+
+	if offset >= len(duplicates) {
+		return []models.SongDuplicate{}, false, nil
+	}
+	end := min(offset+pageSize, len(duplicates))
+	return duplicates[offset:end], end < len(duplicates), nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Метод для постановки песни вне корзины в очередь обогащения, как в SongsRepository
+func (m *SongsMemory) QueueSongEnrichment(ctx context.Context, songID int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.QueueSongEnrichment: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	now := time.Now()
+	record.enrichment = models.SongEnrichment{Status: models.EnrichmentPending, NextAttemptAt: &now, UpdatedAt: now}
+	record.version++
+	m.songs[songID] = record
+	return nil
+}
+
+// Метод для захвата до limit песен, ожидающих обогащения, как в SongsRepository
+func (m *SongsMemory) ClaimSongEnrichments(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("SongsMemory.ClaimSongEnrichments: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	now := time.Now()
+	var due []songRecord
+	for _, record := range m.songs {
+		enrichment := record.enrichment
+		if enrichment.Status == models.EnrichmentPending && record.deletedAt.IsZero() &&
+			enrichment.NextAttemptAt != nil && !enrichment.NextAttemptAt.After(now) {
+			due = append(due, record)
+		}
+	}
+	slices.SortFunc(due, func(a, b songRecord) int {
+		if c := a.enrichment.NextAttemptAt.Compare(*b.enrichment.NextAttemptAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	jobs := make([]models.EnrichmentJob, 0, min(limit, len(due)))
+	for _, record := range due[:min(limit, len(due))] {
+		next := now.Add(lease)
+		record.enrichment.NextAttemptAt = &next
+		m.songs[record.id] = record
+		jobs = append(jobs, models.EnrichmentJob{
+			SongID:   record.id,
+			Group:    m.groups[record.groupID],
+			Song:     record.song,
+			Attempts: record.enrichment.Attempts,
+		})
+	}
+	return jobs, nil
+}
+
+// Метод для сохранения состояния обогащения песни, как в SongsRepository
+func (m *SongsMemory) SetSongEnrichment(ctx context.Context, songID int, enrichment models.SongEnrichment) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.SetSongEnrichment: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	previous := record.enrichment
+	enrichment.UpdatedAt = previous.UpdatedAt
+	if enrichment.Status != previous.Status || enrichment.Attempts != previous.Attempts || enrichment.Error != previous.Error {
+		enrichment.UpdatedAt = time.Now()
+		record.version++
+	}
+	record.enrichment = enrichment
+	m.songs[songID] = record
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для выгрузки всех песен, подходящих под фильтр, в порядке сортировки, как в SongsRepository.
+// Песни отбираются под блокировкой, а передаются в fn уже после её снятия.
+func (m *SongsMemory) ExportSongs(ctx context.Context, filter models.SongFilter, sortFields []models.SortField, fn func(song models.Songs) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.ExportSongs: %w", err)
+	}
+
+	songs, err := m.exportSongs(ctx, filter, sortFields)
+	if err != nil {
+		return fmt.Errorf("SongsMemory.ExportSongs: %w", err)
+	}
+
+	for _, song := range songs {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("SongsMemory.ExportSongs: %w", err)
+		}
+		if err := fn(song); err != nil {
+			return fmt.Errorf("SongsMemory.ExportSongs %w", err)
+		}
+	}
+	return nil
+}
+
+// Метод для отбора выгружаемых песен под блокировкой чтения
+func (m *SongsMemory) exportSongs(ctx context.Context, filter models.SongFilter, sortFields []models.SortField) ([]models.Songs, error) {
+	defer m.rlock(ctx)()
+
+	logrus.WithField("filter", filter).Debug("SongsMemory.ExportSongs: filtering songs")
+
+	matched, err := m.sortedSongs(filter, sortFields)
+	if err != nil {
+		return nil, err
+	}
+	songs := m.toModels(matched)
+	scoreSongs(songs, filter)
+
+	// В режиме fuzzy песни упорядочены сначала по убыванию схожести
+	if filter.Fuzzy && filter.HasNameFilter() {
+		sort.SliceStable(songs, func(i, j int) bool {
+			return *songs[i].Score > *songs[j].Score
+		})
+	}
+	return songs, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Метод для подбора ближайших по написанию названия песни и имени группы, как в SongsRepository
+func (m *SongsMemory) SuggestSongs(ctx context.Context, filter models.SongFilter) (*models.Suggestion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("SongsMemory.SuggestSongs: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	var suggestion models.Suggestion
+	if filter.Song != "" {
+		titles := make([]string, 0, len(m.songs))
+		for _, id := range m.sortedSongIDs() {
+			if record := m.songs[id]; record.deletedAt.IsZero() {
+				titles = append(titles, record.song)
+			}
+		}
+		suggestion.Song = mostSimilar(filter.Song, titles)
+	}
+	if filter.Group != "" {
+		names := make([]string, 0, len(m.groups))
+		for _, id := range m.sortedGroupIDs() {
+			names = append(names, m.groups[id])
+		}
+		suggestion.Group = mostSimilar(filter.Group, names)
+	}
+
+	if suggestion == (models.Suggestion{}) {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// Функция для проверки условия по названию, как fuzzyMatch в SongsRepository
+func fuzzyMatches(value, filter string, fuzzy bool) bool {
+	if ilike(value, "%"+filter+"%") {
+		return true
+	}
+	return fuzzy && filter != "" && wordSimilarity(filter, value) >= defaultFuzzyThreshold
+}
+
+// Функция для вычисления схожести песни с нечёткими фильтрами, как songsScore в SongsRepository
+func fuzzyScore(song models.Songs, filter models.SongFilter) *float32 {
+	if !filter.Fuzzy || !filter.HasNameFilter() {
+		return nil
+	}
+
+	var sum float64
+	var count int
+	if filter.Song != "" {
+		sum += wordSimilarity(filter.Song, song.Song)
+		count++
+	}
+	if filter.Group != "" {
+		// Схожесть с группой - наибольшая среди всех исполнителей песни
+		var best float64
+		for _, artist := range song.Artists {
+			best = max(best, wordSimilarity(filter.Group, artist.Group))
+		}
+		sum += best
+		count++
+	}
+
+	score := float32(sum / float64(count))
+	return &score
+}
+
+// Функция для заполнения схожести песен с нечёткими фильтрами
+func scoreSongs(songs []models.Songs, filter models.SongFilter) {
+	for i := range songs {
+		songs[i].Score = fuzzyScore(songs[i], filter)
+	}
+}
+
+// Функция для выбора значения, наиболее похожего на запрос, среди значений со схожестью не ниже порога.
+// При равной схожести выбирается первое значение, как ORDER BY ..., id.
+func mostSimilar(query string, values []string) string {
+	type candidate struct {
+		value            string
+		word, similarity float64
+	}
+
+	var candidates []candidate
+	for _, value := range values {
+		word := wordSimilarity(query, value)
+		if word < defaultFuzzyThreshold {
+			continue
+		}
+		candidates = append(candidates, candidate{value: value, word: word, similarity: similarity(query, value)})
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].word != candidates[j].word {
+			return candidates[i].word > candidates[j].word
+		}
+		return candidates[i].similarity > candidates[j].similarity
+	})
+	return candidates[0].value
+}
+
+// Функция для вычисления схожести строк по триграммам, как similarity в pg_trgm
+func similarity(a, b string) float64 {
+	return jaccard(trigramSet(trigrams(a)), trigramSet(trigrams(b)))
+}
+
+// Функция для вычисления наибольшей схожести триграмм первой строки с непрерывным
+// отрезком упорядоченных триграмм второй строки, как word_similarity в pg_trgm
+func wordSimilarity(a, b string) float64 {
+	query := trigramSet(trigrams(a))
+	target := trigrams(b)
+
+	var best float64
+	for start := range target {
+		extent := make(map[string]struct{})
+		for _, trigram := range target[start:] {
+			extent[trigram] = struct{}{}
+			if value := jaccard(query, extent); value > best {
+				best = value
+			}
+		}
+	}
+	return best
+}
+
+// Функция для получения триграмм строки: слова в нижнем регистре дополняются
+// двумя пробелами в начале и одним в конце, как в pg_trgm
+func trigrams(value string) []string {
+	var result []string
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result = append(result, string(padded[i:i+3]))
+		}
+	}
+	return result
+}
+
+// Функция для получения множества триграмм
+func trigramSet(trigrams []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(trigrams))
+	for _, trigram := range trigrams {
+		set[trigram] = struct{}{}
+	}
+	return set
+}
+
+// Функция для вычисления отношения пересечения множеств к их объединению
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
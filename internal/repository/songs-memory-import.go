@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Метод для вставки пакета проверенных песен, как SongsRepository.ImportSongs: песня с названием,
+// которое уже есть в группе, пропускается, вставленные песни получают статус обогащения imported.
+// Возвращает результат по каждой песне в порядке пакета.
+func (m *SongsMemory) ImportSongs(ctx context.Context, songs []models.Songs) ([]models.ImportRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("SongsMemory.ImportSongs: %w", err)
+	}
+
+	rows := make([]models.ImportRow, len(songs))
+	err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, song := range songs {
+			songID, err := m.PostSong(ctx, song)
+			var duplicate *DuplicateSongError
+			if errors.As(err, &duplicate) {
+				rows[i] = models.ImportRow{Status: models.ImportSkipped, ExistingID: duplicate.ExistingID}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			record := m.songs[songID]
+			record.enrichment = models.SongEnrichment{Status: models.EnrichmentImported, UpdatedAt: time.Now()}
+			m.songs[songID] = record
+			rows[i] = models.ImportRow{Status: models.ImportInserted, ID: songID}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Структура searchTerms, содержащая разобранный поисковый запрос
+type searchTerms struct {
+	include []string
+	exclude []string
+}
+
+// Метод для полнотекстового поиска по текстам песен в памяти.
+// Приближает websearch_to_tsquery без стемминга: слово запроса совпадает со словом текста,
+// если является его началом; "-слово" исключает песни с этим словом.
+func (m *SongsMemory) SearchSongs(ctx context.Context, query string, page, pageSize int) ([]models.SearchResult, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.SearchSongs invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.SearchSongs: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	terms := parseSearchTerms(query)
+	if len(terms.include) == 0 {
+		return []models.SearchResult{}, 0, nil
+	}
+
+	var results []models.SearchResult
+	for _, id := range m.sortedSongIDs() {
+		record := m.songs[id]
+		if !record.deletedAt.IsZero() {
+			continue
+		}
+		titleWords, textWords := searchWords(record.song), searchWords(record.text)
+		allWords := append(append([]string(nil), titleWords...), textWords...)
+		if !terms.match(allWords) {
+			continue
+		}
+
+		// Вес совпадений в названии выше, чем в тексте, как setweight A/B в search_vector
+		rank := 1.0*float32(countMatches(titleWords, terms.include)) + 0.4*float32(countMatches(textWords, terms.include))
+		rank /= float32(len(allWords))
+
+		result := models.SearchResult{Songs: m.toModel(record), Rank: rank, Snippets: []models.Snippet{}}
+		for i, verse := range strings.Split(record.text, "\n\n") {
+			if terms.match(searchWords(verse)) {
+				result.Snippets = append(result.Snippets, models.Snippet{Verse: i + 1, Text: highlight(verse, terms.include)})
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	// Вычисление общего количества страниц
+	totalPages := (len(results) + pageSize - 1) / pageSize
+
+	if offset >= len(results) {
+		return []models.SearchResult{}, totalPages, nil
+	}
+	end := offset + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[offset:end], totalPages, nil
+}
+
+// Функция для разбора поискового запроса на обязательные и исключённые слова
+func parseSearchTerms(query string) searchTerms {
+	var terms searchTerms
+	for _, field := range strings.Fields(query) {
+		exclude := strings.HasPrefix(field, "-")
+		for _, word := range searchWords(field) {
+			if word == "or" {
+				continue
+			}
+			if exclude {
+				terms.exclude = append(terms.exclude, word)
+			} else {
+				terms.include = append(terms.include, word)
+			}
+		}
+	}
+	return terms
+}
+
+// Метод для проверки, что слова содержат все обязательные и ни одного исключённого слова запроса
+func (t searchTerms) match(words []string) bool {
+	for _, term := range t.include {
+		if countMatches(words, []string{term}) == 0 {
+			return false
+		}
+	}
+	return countMatches(words, t.exclude) == 0
+}
+
+// Функция для подсчёта слов, начинающихся с одного из слов запроса
+func countMatches(words, terms []string) int {
+	count := 0
+	for _, word := range words {
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Функция для разбиения текста на слова в нижнем регистре
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Функция для выделения совпавших слов тегами <b></b>, как ts_headline
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		if countMatches([]string{strings.ToLower(word.String())}, terms) > 0 {
+			b.WriteString("<b>" + word.String() + "</b>")
+		} else {
+			b.WriteString(word.String())
+		}
+		word.Reset()
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+
+	return b.String()
+}
@@ -0,0 +1,863 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура songRecord, описывающая строку таблицы songs в памяти
+type songRecord struct {
+	id          int
+	groupID     int
+	song        string
+	text        string
+	releaseDate time.Time // нулевое значение соответствует NULL
+	link        string
+	albumID     int // 0 соответствует NULL
+	trackNumber int // 0 соответствует NULL
+	credits     []artistCredit
+	genres      []int
+	tags        []int
+	deletedAt   time.Time // нулевое значение соответствует NULL
+	version     int       // увеличивается при каждом изменении песни
+	enrichment  models.SongEnrichment
+}
+
+// Структура albumRecord, описывающая строку таблицы albums в памяти
+type albumRecord struct {
+	groupID     int
+	title       string
+	releaseDate time.Time // нулевое значение соответствует NULL
+	coverLink   string
+}
+
+// Структура aliasRecord, описывающая строку таблицы group_aliases в памяти
+type aliasRecord struct {
+	groupID int
+	alias   string
+}
+
+// Структура SongsMemory, реализующая интерфейсы Songs, Groups и Transactor без базы данных
+type SongsMemory struct {
+	mu          sync.RWMutex
+	songs       map[int]songRecord
+	groups      map[int]string
+	aliases     map[int]aliasRecord
+	albums      map[int]albumRecord
+	genres      memoryLabels
+	tags        memoryLabels
+	revisions   map[int][]models.Revision // ревизии по ID песни в порядке номеров
+	audit       []models.AuditEntry       // журнал аудита в порядке записи
+	nextSongID  int
+	nextGroupID int
+	nextAliasID int
+	nextAlbumID int
+}
+
+// Функция для создания нового экземпляра SongsMemory с пустым хранилищем
+func NewSongsMemory() *SongsMemory {
+	return &SongsMemory{
+		songs:       make(map[int]songRecord),
+		groups:      make(map[int]string),
+		aliases:     make(map[int]aliasRecord),
+		albums:      make(map[int]albumRecord),
+		genres:      newMemoryLabels(),
+		tags:        newMemoryLabels(),
+		revisions:   make(map[int][]models.Revision),
+		nextSongID:  1,
+		nextGroupID: 1,
+		nextAliasID: 1,
+		nextAlbumID: 1,
+	}
+}
+
+// Ключ контекста, отмечающий вызовы внутри транзакции хранилища в памяти
+type memoryTxKey struct{}
+
+// Метод для выполнения fn в транзакции: хранилище блокируется на всё время выполнения,
+// а при ошибке восстанавливается его состояние до начала транзакции
+func (m *SongsMemory) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Снимок состояния для отката
+	songs := make(map[int]songRecord, len(m.songs))
+	for id, record := range m.songs {
+		songs[id] = record
+	}
+	groups := make(map[int]string, len(m.groups))
+	for id, group := range m.groups {
+		groups[id] = group
+	}
+	aliases := make(map[int]aliasRecord, len(m.aliases))
+	for id, alias := range m.aliases {
+		aliases[id] = alias
+	}
+	albums := make(map[int]albumRecord, len(m.albums))
+	for id, album := range m.albums {
+		albums[id] = album
+	}
+	genres, tags := m.genres.clone(), m.tags.clone()
+	// Ревизии и журнал аудита только дописываются, поэтому достаточно сохранить срезы прежней длины
+	revisions := make(map[int][]models.Revision, len(m.revisions))
+	for id, songRevisions := range m.revisions {
+		revisions[id] = songRevisions
+	}
+	audit := m.audit
+	nextSongID, nextGroupID, nextAliasID, nextAlbumID := m.nextSongID, m.nextGroupID, m.nextAliasID, m.nextAlbumID
+
+	// Снимок восстанавливается при ошибке fn и при панике внутри fn
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		m.songs, m.groups, m.aliases, m.albums = songs, groups, aliases, albums
+		m.genres, m.tags, m.revisions, m.audit = genres, tags, revisions, audit
+		m.nextSongID, m.nextGroupID, m.nextAliasID, m.nextAlbumID = nextSongID, nextGroupID, nextAliasID, nextAlbumID
+	}()
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, m)); err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+// Метод для проверки, выполняется ли вызов внутри транзакции этого хранилища
+func (m *SongsMemory) inTransaction(ctx context.Context) bool {
+	tx, ok := ctx.Value(memoryTxKey{}).(*SongsMemory)
+	return ok && tx == m
+}
+
+// Метод для захвата блокировки на запись; внутри транзакции блокировка уже удерживается
+func (m *SongsMemory) lock(ctx context.Context) func() {
+	if m.inTransaction(ctx) {
+		return func() {}
+	}
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+// Метод для захвата блокировки на чтение; внутри транзакции блокировка уже удерживается
+func (m *SongsMemory) rlock(ctx context.Context) func() {
+	if m.inTransaction(ctx) {
+		return func() {}
+	}
+	m.mu.RLock()
+	return m.mu.RUnlock
+}
+
+// Метод для получения всех песен с фильтрацией, сортировкой, пагинацией и возвратом общего количества страниц
+func (m *SongsMemory) GetAllSongs(ctx context.Context, filter models.SongFilter, sortFields []models.SortField, page, pageSize int) ([]models.Songs, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllSongs invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllSongs: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	logrus.WithField("filter", filter).Debug("SongsMemory.GetAllSongs: filtering songs")
+
+	matched, err := m.sortedSongs(filter, sortFields)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.GetAllSongs: %w", err)
+	}
+	songs := m.toModels(matched)
+	scoreSongs(songs, filter)
+
+	// В режиме fuzzy песни упорядочены сначала по убыванию схожести
+	if filter.Fuzzy && filter.HasNameFilter() {
+		sort.SliceStable(songs, func(i, j int) bool {
+			return *songs[i].Score > *songs[j].Score
+		})
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (len(songs) + pageSize - 1) / pageSize
+
+	if offset >= len(songs) {
+		return []models.Songs{}, totalPages, nil
+	}
+	end := offset + pageSize
+	if end > len(songs) {
+		end = len(songs)
+	}
+
+	return songs[offset:end], totalPages, nil
+}
+
+// Метод для получения страницы песен по курсору (keyset-пагинация), как в SongsRepository
+func (m *SongsMemory) GetSongsByCursor(ctx context.Context, filter models.SongFilter, sortFields []models.SortField, cursor *models.Cursor, limit int) ([]models.Songs, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("SongsMemory.GetSongsByCursor: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	songs, hasMore, err := m.songsByCursor(filter, sortFields, cursor, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("SongsMemory.GetSongsByCursor: %w", err)
+	}
+	scoreSongs(songs, filter)
+
+	return songs, hasMore, nil
+}
+
+// Метод для выбора страницы песен по позиции курсора
+func (m *SongsMemory) songsByCursor(filter models.SongFilter, sortFields []models.SortField, cursor *models.Cursor, limit int) ([]models.Songs, bool, error) {
+	matched, err := m.sortedSongs(filter, sortFields)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cursor == nil {
+		if len(matched) > limit {
+			return m.toModels(matched[:limit]), true, nil
+		}
+		return m.toModels(matched), false, nil
+	}
+
+	position, err := cursorSortRow(*cursor)
+	if err != nil {
+		return nil, false, err
+	}
+	compare, _ := songsCompare(sortFields)
+
+	// Индекс первой строки строго после позиции курсора
+	after := sort.Search(len(matched), func(i int) bool {
+		return compare(m.sortRow(matched[i]), position) > 0
+	})
+
+	if cursor.Backward {
+		// Строки строго перед курсором заканчиваются перед первой строкой, не меньшей позиции
+		before := sort.Search(len(matched), func(i int) bool {
+			return compare(m.sortRow(matched[i]), position) >= 0
+		})
+		start := before - limit
+		if start < 0 {
+			start = 0
+		}
+		return m.toModels(matched[start:before]), start > 0, nil
+	}
+
+	end := after + limit
+	if end >= len(matched) {
+		return m.toModels(matched[after:]), false, nil
+	}
+	return m.toModels(matched[after:end]), true, nil
+}
+
+// Метод для получения количества песен, подходящих под фильтр
+func (m *SongsMemory) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("SongsMemory.CountSongs: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	return len(m.filterSongs(filter)), nil
+}
+
+// Метод для отбора песен по фильтру в порядке возрастания id
+func (m *SongsMemory) filterSongs(filter models.SongFilter) []songRecord {
+	var matched []songRecord
+	for _, id := range m.sortedSongIDs() {
+		record := m.songs[id]
+		// Удалённые песни отбираются только фильтром корзины
+		if filter.Deleted == record.deletedAt.IsZero() {
+			continue
+		}
+		song := m.toModel(record)
+		if !fuzzyMatches(song.Song, filter.Song, filter.Fuzzy) ||
+			!artistsMatch(song.Artists, filter.Group, filter.Fuzzy) ||
+			!ilike(song.Text, "%"+filter.Text+"%") ||
+			!ilike(song.ReleaseDate, "%"+filter.ReleaseDate+"%") ||
+			!ilike(song.Link, "%"+filter.Link+"%") {
+			continue
+		}
+		if filter.GroupID != 0 && record.groupID != filter.GroupID {
+			continue
+		}
+		if !labelsMatch(song.Genres, filter.Genres) || !labelsMatch(song.Tags, filter.Tags) {
+			continue
+		}
+		if filter.Album != "" && (song.Album == nil || !ilike(song.Album.Title, "%"+filter.Album+"%")) {
+			continue
+		}
+		// Как и в SQL, сравнение с NULL-датой не проходит ни одну из границ
+		if !filter.ReleaseDateFrom.IsZero() && (record.releaseDate.IsZero() || record.releaseDate.Before(filter.ReleaseDateFrom)) {
+			continue
+		}
+		if !filter.ReleaseDateTo.IsZero() && (record.releaseDate.IsZero() || record.releaseDate.After(filter.ReleaseDateTo)) {
+			continue
+		}
+		matched = append(matched, record)
+	}
+	return matched
+}
+
+// Метод для отбора песен по фильтру в порядке сортировки
+func (m *SongsMemory) sortedSongs(filter models.SongFilter, sortFields []models.SortField) ([]songRecord, error) {
+	compare, err := songsCompare(sortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := m.filterSongs(filter)
+	sort.SliceStable(matched, func(i, j int) bool {
+		return compare(m.sortRow(matched[i]), m.sortRow(matched[j])) < 0
+	})
+
+	return matched, nil
+}
+
+// Метод для получения песни по ID
+func (m *SongsMemory) GetSongByID(ctx context.Context, id int) (models.Songs, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Songs{}, fmt.Errorf("SongsMemory.GetSongByID: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	record, ok := m.songs[id]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("id", id).Info("Song not found")
+		return models.Songs{}, fmt.Errorf("song with id %d %w", id, ErrNotFound)
+	}
+
+	return m.toModel(record), nil
+}
+
+// Метод для создания новой песни, возвращает ID созданной песни; песня сразу ставится в очередь обогащения
+func (m *SongsMemory) PostSong(ctx context.Context, song models.Songs) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("SongsMemory.PostSong: %w", err)
+	}
+
+	releaseDate, err := parseMemoryReleaseDate(song.ReleaseDate)
+	if err != nil {
+		return 0, err
+	}
+
+	defer m.lock(ctx)()
+
+	// Убедиться, что группа существует или создать её
+	groupID := m.ensureGroupExists(song.Group)
+
+	// Песня с тем же названием в группе уже существует
+	if err := m.checkDuplicateSong(groupID, song.Song, 0); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	songID := m.nextSongID
+	m.songs[songID] = songRecord{
+		id:          songID,
+		groupID:     groupID,
+		song:        song.Song,
+		text:        song.Text,
+		releaseDate: releaseDate,
+		link:        song.Link,
+		credits:     m.songCredits(song.Artists),
+		version:     1,
+		enrichment:  models.SongEnrichment{Status: models.EnrichmentPending, NextAttemptAt: &now, UpdatedAt: now},
+	}
+	m.nextSongID++
+
+	return songID, nil
+}
+
+// Метод для обновления песни по ID
+func (m *SongsMemory) UpdateSong(ctx context.Context, songID int, song models.Songs) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.UpdateSong: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	if song.Song == "" && song.Group == "" && song.Text == "" && song.ReleaseDate == "" && song.Link == "" && song.Artists == nil {
+		return fmt.Errorf("invalid update id: %v data: no fields to update", songID)
+	}
+
+	releaseDate, err := parseMemoryReleaseDate(song.ReleaseDate)
+	if err != nil {
+		return err
+	}
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	// Убедиться, что группа существует или создать её
+	groupID := m.ensureGroupExists(song.Group)
+
+	// Новое название или группа не должны совпасть с другой песней группы
+	title, targetGroupID := record.song, record.groupID
+	if song.Song != "" {
+		title = song.Song
+	}
+	if groupID != 0 {
+		targetGroupID = groupID
+	}
+	if err := m.checkDuplicateSong(targetGroupID, title, songID); err != nil {
+		return err
+	}
+
+	if song.Song != "" {
+		record.song = song.Song
+	}
+	if groupID != 0 {
+		// При переходе в другую группу песня исключается из альбома прежней группы
+		if groupID != record.groupID {
+			record.albumID, record.trackNumber = 0, 0
+		}
+		record.groupID = groupID
+	}
+	if song.Text != "" {
+		record.text = song.Text
+	}
+	if song.ReleaseDate != "" {
+		record.releaseDate = releaseDate
+	}
+	if song.Link != "" {
+		record.link = song.Link
+	}
+	if song.Artists != nil {
+		record.credits = m.songCredits(song.Artists)
+	}
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для замены всех полей и исполнителей песни по ID: пустые значения очищают поля,
+// nil вместо списка исполнителей удаляет все дополнительные исполнители
+func (m *SongsMemory) ReplaceSong(ctx context.Context, songID int, song models.Songs) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.ReplaceSong: %w", err)
+	}
+
+	releaseDate, err := parseMemoryReleaseDate(song.ReleaseDate)
+	if err != nil {
+		return err
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	// Убедиться, что группа существует или создать её
+	groupID := m.ensureGroupExists(song.Group)
+
+	if err := m.checkDuplicateSong(groupID, song.Song, songID); err != nil {
+		return err
+	}
+
+	// При переходе в другую группу песня исключается из альбома прежней группы
+	if groupID != record.groupID {
+		record.albumID, record.trackNumber = 0, 0
+	}
+	record.groupID = groupID
+	record.song = song.Song
+	record.text = song.Text
+	record.releaseDate = releaseDate
+	record.link = song.Link
+	record.credits = m.songCredits(song.Artists)
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для проверки, что песня не изменилась с версии version.
+// ErrNotFound, если песни нет, ErrVersionMismatch, если версия другая.
+func (m *SongsMemory) CheckSongVersion(ctx context.Context, songID, version int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.CheckSongVersion: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+	if record.version != version {
+		logrus.WithFields(logrus.Fields{
+			"songID":   songID,
+			"expected": version,
+			"current":  record.version,
+		}).Info("Song version mismatch")
+		return fmt.Errorf("song with id %d has version %d, not %d: %w", songID, record.version, version, ErrVersionMismatch)
+	}
+
+	return nil
+}
+
+// Метод для перемещения песни в корзину по ID; группа остаётся и после удаления её последней песни
+func (m *SongsMemory) DeleteSong(ctx context.Context, songID int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.DeleteSong: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	record.deletedAt = time.Now()
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для восстановления песни из корзины по ID
+func (m *SongsMemory) RestoreSong(ctx context.Context, songID int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.RestoreSong: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found in trash")
+		return fmt.Errorf("song with id %d in trash %w", songID, ErrNotFound)
+	}
+
+	// За время нахождения в корзине в группе могла появиться песня с тем же названием
+	if err := m.checkDuplicateSong(record.groupID, record.song, songID); err != nil {
+		return err
+	}
+
+	record.deletedAt = time.Time{}
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для окончательного удаления песен, перемещённых в корзину раньше before
+func (m *SongsMemory) PurgeDeletedSongs(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("SongsMemory.PurgeDeletedSongs: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	purged := 0
+	for id, record := range m.songs {
+		if !record.deletedAt.IsZero() && record.deletedAt.Before(before) {
+			delete(m.songs, id)
+			delete(m.revisions, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// Метод для обеспечения существования группы с тем же ключом имени или псевдонима, как в SongsRepository
+func (m *SongsMemory) ensureGroupExists(groupName string) int {
+	if groupName == "" {
+		return 0
+	}
+
+	key := models.GroupKey(groupName)
+	if id := m.groupIDByAlias(key); id != 0 {
+		return id
+	}
+	if id := m.groupIDByKey(key); id != 0 {
+		return id
+	}
+
+	// Группа не существует, создать её
+	groupID := m.nextGroupID
+	m.groups[groupID] = models.NormalizeGroupName(groupName)
+	m.nextGroupID++
+
+	return groupID
+}
+
+// Метод для поиска группы по ключу имени; 0, если группы нет
+func (m *SongsMemory) groupIDByKey(key string) int {
+	for id, name := range m.groups {
+		if models.GroupKey(name) == key {
+			return id
+		}
+	}
+	return 0
+}
+
+// Метод для поиска группы по ключу её псевдонима; 0, если такого псевдонима нет
+func (m *SongsMemory) groupIDByAlias(key string) int {
+	if id := m.aliasIDByKey(key); id != 0 {
+		return m.aliases[id].groupID
+	}
+	return 0
+}
+
+// Метод для поиска псевдонима по ключу; 0, если псевдонима нет
+func (m *SongsMemory) aliasIDByKey(key string) int {
+	for id, alias := range m.aliases {
+		if models.GroupKey(alias.alias) == key {
+			return id
+		}
+	}
+	return 0
+}
+
+// Метод для преобразования записи хранилища в модель песни
+func (m *SongsMemory) toModel(record songRecord) models.Songs {
+	var deletedAt *time.Time
+	if !record.deletedAt.IsZero() {
+		deletedAt = &record.deletedAt
+	}
+	enrichment := record.enrichment
+
+	return models.Songs{
+		ID:          record.id,
+		Song:        record.song,
+		Group:       m.groups[record.groupID],
+		Text:        record.text,
+		ReleaseDate: formatMemoryReleaseDate(record.releaseDate),
+		Link:        record.link,
+		Artists:     m.songArtists(record),
+		Genres:      m.songLabels(genreKind, record),
+		Tags:        m.songLabels(tagKind, record),
+		Album:       m.songAlbum(record),
+		DeletedAt:   deletedAt,
+		Version:     record.version,
+		Enrichment:  &enrichment,
+	}
+}
+
+// Метод для получения альбома песни; nil, если песня не входит в альбом
+func (m *SongsMemory) songAlbum(record songRecord) *models.SongAlbum {
+	album, ok := m.albums[record.albumID]
+	if !ok {
+		return nil
+	}
+	return &models.SongAlbum{ID: record.albumID, Title: album.title, TrackNumber: record.trackNumber}
+}
+
+// Метод для преобразования списка записей в модели песен
+func (m *SongsMemory) toModels(records []songRecord) []models.Songs {
+	songs := make([]models.Songs, 0, len(records))
+	for _, record := range records {
+		songs = append(songs, m.toModel(record))
+	}
+	return songs
+}
+
+// Метод для получения идентификаторов песен в порядке возрастания
+func (m *SongsMemory) sortedSongIDs() []int {
+	ids := make([]int, 0, len(m.songs))
+	for id := range m.songs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Метод для получения идентификаторов групп в порядке возрастания
+func (m *SongsMemory) sortedGroupIDs() []int {
+	ids := make([]int, 0, len(m.groups))
+	for id := range m.groups {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Структура sortRow, содержащая значения ключей сортировки одной песни или курсора
+type sortRow struct {
+	id          int
+	song        string
+	group       string
+	releaseDate time.Time // нулевое значение соответствует NULL
+	link        string
+}
+
+// Метод для получения значений ключей сортировки записи
+func (m *SongsMemory) sortRow(record songRecord) sortRow {
+	return sortRow{
+		id:          record.id,
+		song:        record.song,
+		group:       m.groups[record.groupID],
+		releaseDate: record.releaseDate,
+		link:        record.link,
+	}
+}
+
+// Функция для получения значений ключей сортировки из курсора
+func cursorSortRow(cursor models.Cursor) (sortRow, error) {
+	row := sortRow{id: cursor.ID, song: cursor.Song, group: cursor.Group, link: cursor.Link}
+	if cursor.ReleaseDate != "" {
+		date, err := time.Parse(time.DateOnly, cursor.ReleaseDate)
+		if err != nil {
+			return sortRow{}, fmt.Errorf("invalid cursor release date: %w", err)
+		}
+		row.releaseDate = date
+	}
+	return row, nil
+}
+
+// Функция для построения сравнения по списку ключей сортировки, как ORDER BY в SongsRepository.
+// При равенстве всех ключей строки сравниваются по id.
+func songsCompare(sortFields []models.SortField) (func(a, b sortRow) int, error) {
+	compares := make([]func(a, b sortRow) int, 0, len(sortFields))
+	for _, field := range sortFields {
+		compare, ok := songCompares[field.Key]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort key %q", field.Key)
+		}
+		if field.Desc {
+			asc := compare
+			compare = func(a, b sortRow) int { return -asc(a, b) }
+		}
+		compares = append(compares, compare)
+	}
+
+	return func(a, b sortRow) int {
+		for _, compare := range compares {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.id, b.id)
+	}, nil
+}
+
+// Функции сравнения для ключей models.SongSortKeys.
+// NULL-дата считается больше любой другой, как в PostgreSQL.
+var songCompares = map[string]func(a, b sortRow) int{
+	models.SortByID: func(a, b sortRow) int {
+		return cmp.Compare(a.id, b.id)
+	},
+	models.SortBySong: func(a, b sortRow) int {
+		return strings.Compare(a.song, b.song)
+	},
+	models.SortByGroup: func(a, b sortRow) int {
+		return strings.Compare(a.group, b.group)
+	},
+	models.SortByReleaseDate: func(a, b sortRow) int {
+		switch {
+		case a.releaseDate.IsZero() && b.releaseDate.IsZero():
+			return 0
+		case a.releaseDate.IsZero():
+			return 1
+		case b.releaseDate.IsZero():
+			return -1
+		}
+		return a.releaseDate.Compare(b.releaseDate)
+	},
+	models.SortByLink: func(a, b sortRow) int {
+		return strings.Compare(a.link, b.link)
+	},
+}
+
+// Функция для разбора даты выпуска; пустая строка соответствует NULL
+func parseMemoryReleaseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return models.ParseReleaseDate(value)
+}
+
+// Функция для вывода даты выпуска в формате внешнего API, как to_char в SongsRepository
+func formatMemoryReleaseDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(models.ReleaseDateLayout)
+}
+
+// Функция для сравнения строки с шаблоном по правилам ILIKE:
+// % - любая последовательность символов, _ - один символ, \ - экранирование
+func ilike(value, pattern string) bool {
+	return likeMatch([]rune(strings.ToLower(value)), []rune(strings.ToLower(pattern)))
+}
+
+// Элемент шаблона LIKE: руна, которая должна совпасть буквально, или подстановочный знак % либо _
+type likeToken struct {
+	r        rune
+	wildcard bool
+}
+
+// Функция для разбора шаблона LIKE на элементы с учётом экранирования \
+func parseLikePattern(pattern []rune) []likeToken {
+	tokens := make([]likeToken, 0, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			tokens = append(tokens, likeToken{r: pattern[i]})
+		case pattern[i] == '%' || pattern[i] == '_':
+			tokens = append(tokens, likeToken{r: pattern[i], wildcard: true})
+		default:
+			tokens = append(tokens, likeToken{r: pattern[i]})
+		}
+	}
+	return tokens
+}
+
+// Функция для сопоставления рун строки с рунами шаблона LIKE. Сопоставление жадное с возвратом
+// только к последнему %, поэтому занимает не больше O(len(value)*len(pattern)) при любом шаблоне.
+func likeMatch(value, pattern []rune) bool {
+	tokens := parseLikePattern(pattern)
+
+	v, t := 0, 0
+	star, starValue := -1, 0
+	for v < len(value) {
+		switch {
+		case t < len(tokens) && tokens[t].wildcard && tokens[t].r == '%':
+			// Сначала % совпадает с пустой строкой; при несовпадении дальше он захватывает ещё одну руну
+			star, starValue = t, v
+			t++
+		case t < len(tokens) && (tokens[t].wildcard || tokens[t].r == value[v]):
+			v++
+			t++
+		case star >= 0:
+			starValue++
+			v, t = starValue, star+1
+		default:
+			return false
+		}
+	}
+
+	for t < len(tokens) && tokens[t].wildcard && tokens[t].r == '%' {
+		t++
+	}
+	return t == len(tokens)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestILike(t *testing.T) {
+	tests := []struct {
+		value   string
+		pattern string
+		want    bool
+	}{
+		{value: "Uprising", pattern: "uprising", want: true},
+		{value: "Uprising", pattern: "%RISING", want: true},
+		{value: "Uprising", pattern: "up%", want: true},
+		{value: "Uprising", pattern: "%pri%", want: true},
+		{value: "Uprising", pattern: "u_rising", want: true},
+		{value: "Uprising", pattern: "u_ising", want: false},
+		{value: "Uprising", pattern: "%", want: true},
+		{value: "", pattern: "%", want: true},
+		{value: "", pattern: "_", want: false},
+		{value: "Uprising", pattern: "", want: false},
+		{value: "Uprising", pattern: "%x%", want: false},
+		{value: "abcabd", pattern: "%abd", want: true},
+		{value: "Жизнь", pattern: "ж_зн%", want: true},
+		{value: "100%", pattern: `100\%`, want: true},
+		{value: "1000", pattern: `100\%`, want: false},
+		{value: "a_b", pattern: `a\_b`, want: true},
+		{value: "axb", pattern: `a\_b`, want: false},
+		{value: `a\b`, pattern: `a\\b`, want: true},
+		{value: `a\`, pattern: `a\`, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := ilike(tt.value, tt.pattern); got != tt.want {
+			t.Errorf("ilike(%q, %q) = %t, want %t", tt.value, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestILikePathologicalPattern(t *testing.T) {
+	// Шаблон с множеством % на несовпадающей строке требовал экспоненциального перебора
+	value := strings.Repeat("a", 5000)
+	pattern := strings.Repeat("%a", 50) + "%b"
+
+	start := time.Now()
+	if ilike(value, pattern) {
+		t.Fatal("pattern unexpectedly matched")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("matching took %s", elapsed)
+	}
+}
+
+func TestSongsMemoryCancelledContext(t *testing.T) {
+	m := NewSongsMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := m.PostSong(ctx, models.Songs{Group: "Muse", Song: "Uprising"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("PostSong error = %v, want context.Canceled", err)
+	}
+	if _, err := m.GetSongByID(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetSongByID error = %v, want context.Canceled", err)
+	}
+	if _, _, err := m.GetAllSongs(ctx, models.SongFilter{}, nil, 1, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAllSongs error = %v, want context.Canceled", err)
+	}
+	if len(m.songs) != 0 {
+		t.Errorf("songs stored with a cancelled context: %d", len(m.songs))
+	}
+}
+
+func TestSongsMemoryTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	m := NewSongsMemory()
+	if _, err := m.PostSong(ctx, models.Songs{Group: "Muse", Song: "Uprising"}); err != nil {
+		t.Fatal(err)
+	}
+
+	errAbort := errors.New("abort")
+	err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := m.PostSong(ctx, models.Songs{Group: "Queen", Song: "Bohemian Rhapsody"}); err != nil {
+			return err
+		}
+		if err := m.UpdateSong(ctx, 1, models.Songs{Song: "Starlight"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTransaction error = %v, want %v", err, errAbort)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic inside the transaction was not propagated")
+			}
+		}()
+		_ = m.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := m.PostSong(ctx, models.Songs{Group: "Queen", Song: "Bohemian Rhapsody"}); err != nil {
+				return err
+			}
+			panic("abort")
+		})
+	}()
+
+	songs, _, err := m.GetAllSongs(ctx, models.SongFilter{}, nil, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 1 || songs[0].Song != "Uprising" || songs[0].Version != 1 {
+		t.Errorf("songs after rollback = %+v, want only the original Uprising", songs)
+	}
+	if len(m.groups) != 1 {
+		t.Errorf("groups after rollback = %v, want only Muse", m.groups)
+	}
+
+	// Идентификаторы после отката выдаются заново
+	id, err := m.PostSong(ctx, models.Songs{Group: "Queen", Song: "Bohemian Rhapsody"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 2 {
+		t.Errorf("song id after rollback = %d, want 2", id)
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	// Значения совпадают с similarity и word_similarity из pg_trgm
+	tests := []struct {
+		a, b                string
+		similarity, wordSim float64
+	}{
+		{a: "word", b: "words", similarity: 4.0 / 7, wordSim: 0.8},
+		{a: "word", b: "two words", similarity: 4.0 / 11, wordSim: 0.8},
+		{a: "Muse", b: "muse", similarity: 1, wordSim: 1},
+		{a: "abc", b: "xyz", similarity: 0, wordSim: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := similarity(tt.a, tt.b); math.Abs(got-tt.similarity) > 1e-9 {
+				t.Errorf("similarity = %v, want %v", got, tt.similarity)
+			}
+			if got := wordSimilarity(tt.a, tt.b); math.Abs(got-tt.wordSim) > 1e-9 {
+				t.Errorf("wordSimilarity = %v, want %v", got, tt.wordSim)
+			}
+		})
+	}
+}
+
+func TestSongsMemoryEnrichmentVersion(t *testing.T) {
+	ctx := context.Background()
+	m := NewSongsMemory()
+	id, err := m.PostSong(ctx, models.Songs{Group: "Muse", Song: "Uprising"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := func() int {
+		t.Helper()
+		song, err := m.GetSongByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return song.Version
+	}
+
+	if err := m.QueueSongEnrichment(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	queued := version()
+
+	// Захват песни обработчиком не меняет её версию
+	jobs, err := m.ClaimSongEnrichments(ctx, 10, time.Minute)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("ClaimSongEnrichments = %+v, %v, want one job", jobs, err)
+	}
+	if got := version(); got != queued {
+		t.Errorf("version after claim = %d, want %d", got, queued)
+	}
+
+	// Перенос попытки без изменения статуса, попыток и ошибки не меняет версию
+	next := time.Now().Add(time.Hour)
+	if err := m.SetSongEnrichment(ctx, id, models.SongEnrichment{Status: models.EnrichmentPending, NextAttemptAt: &next}); err != nil {
+		t.Fatal(err)
+	}
+	if got := version(); got != queued {
+		t.Errorf("version after rescheduling = %d, want %d", got, queued)
+	}
+	if err := m.SetSongEnrichment(ctx, id, models.SongEnrichment{Status: models.EnrichmentFailed, Attempts: 1, Error: "timeout"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := version(); got != queued+1 {
+		t.Errorf("version after failure = %d, want %d", got, queued+1)
+	}
+
+	if err := m.DeleteSong(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetSongEnrichment(ctx, id, models.SongEnrichment{Status: models.EnrichmentEnriched}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetSongEnrichment of a deleted song error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+// Выражение для вывода даты выпуска в формате внешнего API (пустая строка для NULL)
+const releaseDateText = `COALESCE(to_char(s.release_date, 'DD.MM.YYYY'), '')`
+
+// Источник строк запросов чтения песен с псевдонимами s, g и a
+const songsFrom = `
+	FROM songs s
+	INNER JOIN groups g ON s.group_id = g.id
+	LEFT JOIN albums a ON s.album_id = a.id`
+
+// Столбцы альбома песни; порядок соответствует songAlbum
+const songAlbumColumns = `a.id, a.title, s.track_number`
+
+// Столбцы состояния обогащения песни сведениями внешнего API
+const songEnrichmentColumns = `s.enrichment_status, s.enrichment_attempts, COALESCE(s.enrichment_error, ''),
+	s.enrichment_next_attempt_at, s.enrichment_updated_at`
+
+// Столбец исполнителей песни в виде JSON-массива: основной исполнитель первым, далее по ролям и именам
+const songArtistsColumn = `COALESCE((SELECT json_agg(json_build_object('groupId', sa.group_id, 'group', ag."group", 'role', sa.role)
+		ORDER BY array_position(ARRAY['primary', 'featured', 'composer', 'lyricist'], sa.role::text), ag."group")
+		FROM song_artists sa INNER JOIN groups ag ON sa.group_id = ag.id WHERE sa.song_id = s.id), '[]')`
+
+// Функция для построения общей части запросов чтения песен; порядок столбцов соответствует scanSong.
+// Столбец score содержит схожесть с нечёткими фильтрами или NULL вне режима fuzzy.
+func songsSelect(filter models.SongFilter, args *queryArgs) string {
+	return `
+	SELECT s.id, s.song, g."group", s.text, ` + releaseDateText + `, s.link, ` + songAlbumColumns + `, ` +
+		songArtistsColumn + `, ` + genreKind.songColumn() + `, ` + tagKind.songColumn() + `, s.deleted_at, s.version, ` +
+		songEnrichmentColumns + `, ` + songsScore(filter, args) + ` AS score` + songsFrom
+}
+
+// Функция для чтения строки, выбранной запросом songsSelect
+func scanSong(row pgx.Row) (models.Songs, error) {
+	var song models.Songs
+	var albumID, trackNumber *int
+	var albumTitle *string
+	var enrichment models.SongEnrichment
+	err := row.Scan(&song.ID, &song.Song, &song.Group, &song.Text, &song.ReleaseDate, &song.Link,
+		&albumID, &albumTitle, &trackNumber, &song.Artists, &song.Genres, &song.Tags, &song.DeletedAt, &song.Version,
+		&enrichment.Status, &enrichment.Attempts, &enrichment.Error, &enrichment.NextAttemptAt, &enrichment.UpdatedAt, &song.Score)
+	song.Album = songAlbum(albumID, albumTitle, trackNumber)
+	song.Enrichment = &enrichment
+	return song, err
+}
+
+// Функция для сборки альбома песни из столбцов songAlbumColumns; nil, если песня не входит в альбом
+func songAlbum(id *int, title *string, trackNumber *int) *models.SongAlbum {
+	if id == nil || title == nil {
+		return nil
+	}
+	album := &models.SongAlbum{ID: *id, Title: *title}
+	if trackNumber != nil {
+		album.TrackNumber = *trackNumber
+	}
+	return album
+}
+
+// Функция для построения выражения схожести песни с нечёткими фильтрами song и group:
+// среднее word_similarity по заданным фильтрам
+func songsScore(filter models.SongFilter, args *queryArgs) string {
+	if !filter.Fuzzy || !filter.HasNameFilter() {
+		return `NULL::real`
+	}
+
+	var parts []string
+	if filter.Song != "" {
+		parts = append(parts, `word_similarity(`+args.add(filter.Song)+`, s.song)`)
+	}
+	if filter.Group != "" {
+		// Схожесть с группой - наибольшая среди всех исполнителей песни
+		parts = append(parts, `(SELECT max(word_similarity(`+args.add(filter.Group)+`, ag."group"))
+			FROM song_artists sa INNER JOIN groups ag ON sa.group_id = ag.id WHERE sa.song_id = s.id)`)
+	}
+
+	return `(` + strings.Join(parts, ` + `) + `) / ` + strconv.Itoa(len(parts))
+}
+
+// Выражения сортировки для ключей models.SongSortKeys.
+// NULL-дата приравнивается к 'infinity', что совпадает с порядком NULL в PostgreSQL по умолчанию.
+var songSortColumns = map[string]string{
+	models.SortByID:          `s.id`,
+	models.SortBySong:        `s.song`,
+	models.SortByGroup:       `g."group"`,
+	models.SortByReleaseDate: `COALESCE(s.release_date, 'infinity'::date)`,
+	models.SortByLink:        `s.link`,
+}
+
+// Структура queryArgs, накапливающая позиционные параметры SQL-запроса
+type queryArgs []interface{}
+
+// Метод для добавления параметра и получения его плейсхолдера ($1, $2, ...)
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// Функция для построения условия WHERE по фильтру песен для запросов с псевдонимами s, g и a.
+// Удалённые песни отбираются только фильтром корзины.
+func songsWhere(filter models.SongFilter, args *queryArgs) string {
+	deleted := `s.deleted_at IS NULL`
+	if filter.Deleted {
+		deleted = `s.deleted_at IS NOT NULL`
+	}
+
+	conditions := []string{
+		deleted,
+		fuzzyMatch(`s.song`, filter.Song, filter.Fuzzy, args),
+		`s.text ILIKE ` + args.add("%"+filter.Text+"%"),
+		releaseDateText + ` ILIKE ` + args.add("%"+filter.ReleaseDate+"%"),
+		`s.link ILIKE ` + args.add("%"+filter.Link+"%"),
+	}
+
+	// Фильтр по группе совпадает с любым исполнителем песни, а не только с основным
+	if filter.Group != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM song_artists sa INNER JOIN groups ag ON sa.group_id = ag.id
+			WHERE sa.song_id = s.id AND `+fuzzyMatch(`ag."group"`, filter.Group, filter.Fuzzy, args)+`)`)
+	}
+	if len(filter.Genres.Names) > 0 {
+		conditions = append(conditions, genreKind.songCondition(filter.Genres, args))
+	}
+	if len(filter.Tags.Names) > 0 {
+		conditions = append(conditions, tagKind.songCondition(filter.Tags, args))
+	}
+	if filter.Album != "" {
+		conditions = append(conditions, `a.title ILIKE `+args.add("%"+filter.Album+"%"))
+	}
+
+	if filter.GroupID != 0 {
+		conditions = append(conditions, `s.group_id = `+args.add(filter.GroupID))
+	}
+	if !filter.ReleaseDateFrom.IsZero() {
+		conditions = append(conditions, `s.release_date >= `+args.add(filter.ReleaseDateFrom))
+	}
+	if !filter.ReleaseDateTo.IsZero() {
+		conditions = append(conditions, `s.release_date <= `+args.add(filter.ReleaseDateTo))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+// Функция для построения условия по названию: подстрока ILIKE, а в режиме fuzzy также
+// схожесть триграмм не ниже порога pg_trgm.word_similarity_threshold (оператор <%)
+func fuzzyMatch(column, value string, fuzzy bool, args *queryArgs) string {
+	condition := column + ` ILIKE ` + args.add("%"+value+"%")
+	if !fuzzy || value == "" {
+		return condition
+	}
+	return `(` + condition + ` OR ` + args.add(value) + ` <% ` + column + `)`
+}
+
+// Функция для построения выражения ORDER BY по списку ключей сортировки.
+// При reverse все направления меняются на противоположные (обход назад от курсора).
+func songsOrderBy(sort []models.SortField, reverse bool) (string, error) {
+	if len(sort) == 0 {
+		sort = []models.SortField{{Key: models.SortByID}}
+	}
+
+	parts := make([]string, 0, len(sort))
+	for _, field := range sort {
+		column, ok := songSortColumns[field.Key]
+		if !ok {
+			return "", fmt.Errorf("unknown sort key %q", field.Key)
+		}
+		if field.Desc != reverse {
+			column += ` DESC`
+		}
+		parts = append(parts, column)
+	}
+
+	return strings.Join(parts, ", "), nil
+}
+
+// Функция для построения условия keyset-пагинации: строки строго после позиции курсора
+// в порядке сортировки (или строго перед ней для обратного курсора), в виде
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func songsKeyset(sort []models.SortField, cursor models.Cursor, args *queryArgs) (string, error) {
+	var disjuncts, equals []string
+	for _, field := range sort {
+		column, ok := songSortColumns[field.Key]
+		if !ok {
+			return "", fmt.Errorf("unknown sort key %q", field.Key)
+		}
+
+		value := args.add(cursorValue(field.Key, cursor))
+		if field.Key == models.SortByReleaseDate {
+			value += `::date`
+		}
+
+		operator := `>`
+		if field.Desc != cursor.Backward {
+			operator = `<`
+		}
+
+		disjuncts = append(disjuncts, strings.Join(append(equals, column+` `+operator+` `+value), ` AND `))
+		equals = append(equals, column+` = `+value)
+
+		// Ключи после уникального id не влияют на порядок
+		if field.Key == models.SortByID {
+			break
+		}
+	}
+
+	return `(` + strings.Join(disjuncts, `) OR (`) + `)`, nil
+}
+
+// Функция для получения значения ключа сортировки из курсора
+func cursorValue(key string, cursor models.Cursor) interface{} {
+	switch key {
+	case models.SortBySong:
+		return cursor.Song
+	case models.SortByGroup:
+		return cursor.Group
+	case models.SortByReleaseDate:
+		if cursor.ReleaseDate == "" {
+			return "infinity"
+		}
+		return cursor.ReleaseDate
+	case models.SortByLink:
+		return cursor.Link
+	default:
+		return cursor.ID
+	}
+}
+
+// Функция для преобразования даты выпуска из формата API в значение столбца DATE (NULL для пустой даты)
+func releaseDateValue(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := models.ParseReleaseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
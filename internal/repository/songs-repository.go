@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/Ktuty/internal/models"
 	"github.com/jackc/pgx/v4"
@@ -13,84 +13,201 @@ import (
 
 // Структура SongsRepository, которая инкапсулирует подключение к базе данных
 type SongsRepository struct {
-	db *pgxpool.Pool
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
 }
 
 // Функция для создания нового экземпляра SongsRepository с подключением к базе данных
-func NewSongsRepository(db *pgxpool.Pool) *SongsRepository {
-	return &SongsRepository{db: db}
+func NewSongsRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *SongsRepository {
+	return &SongsRepository{db: db, transactor: transactor, opts: opts}
 }
 
-// Метод для получения всех песен с фильтрацией, пагинацией и возвратом общего количества страниц
-func (r *SongsRepository) GetAllSongs(filter models.Songs, page, pageSize int) ([]models.Songs, int, error) {
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *SongsRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для получения всех песен с фильтрацией, сортировкой, пагинацией и возвратом общего количества страниц.
+// В режиме fuzzy песни упорядочены сначала по убыванию схожести, затем по заданной сортировке.
+func (r *SongsRepository) GetAllSongs(ctx context.Context, filter models.SongFilter, sort []models.SortField, page, pageSize int) ([]models.Songs, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	offset := (page - 1) * pageSize
 
-	query := `
-	SELECT s.id, s.song, g."group", s.text, s.release_date, s.link
-	FROM songs s
-	INNER JOIN groups g ON s.group_id = g.id
-	WHERE s.song ILIKE $1 AND g."group" ILIKE $2 AND s.text ILIKE $3 AND s.release_date ILIKE $4 AND s.link ILIKE $5
-	LIMIT $6 OFFSET $7`
+	orderBy, err := songsOrderBy(sort, false)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs: %w", err)
+	}
+	if filter.Fuzzy && filter.HasNameFilter() {
+		orderBy = `score DESC, ` + orderBy
+	}
+
+	var args queryArgs
+	query := songsSelect(filter, &args) + `
+	WHERE ` + songsWhere(filter, &args) + `
+	ORDER BY ` + orderBy + `
+	LIMIT ` + args.add(pageSize) + ` OFFSET ` + args.add(offset)
+
+	var songs []models.Songs
+	var totalRecords int
+	err = r.withFuzzyThreshold(ctx, filter.Fuzzy, func(ctx context.Context) error {
+		var err error
+		if songs, err = r.querySongs(ctx, query, args); err != nil {
+			return err
+		}
+		totalRecords, err = r.countSongs(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return songs, totalPages, nil
+}
+
+// Метод для получения страницы песен по курсору (keyset-пагинация).
+// Без курсора возвращается первая страница, для обратного курсора - строки перед ним.
+// Песни всегда возвращаются в порядке сортировки, второй результат сообщает о наличии
+// ещё хотя бы одной строки в направлении обхода.
+func (r *SongsRepository) GetSongsByCursor(ctx context.Context, filter models.SongFilter, sort []models.SortField, cursor *models.Cursor, limit int) ([]models.Songs, bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	backward := cursor != nil && cursor.Backward
+
+	orderBy, err := songsOrderBy(sort, backward)
+	if err != nil {
+		return nil, false, fmt.Errorf("SongsRepository.GetSongsByCursor: %w", err)
+	}
+
+	var args queryArgs
+	selectQuery := songsSelect(filter, &args)
+	where := songsWhere(filter, &args)
+	if cursor != nil {
+		keyset, err := songsKeyset(sort, *cursor, &args)
+		if err != nil {
+			return nil, false, fmt.Errorf("SongsRepository.GetSongsByCursor: %w", err)
+		}
+		where += ` AND (` + keyset + `)`
+	}
+
+	// Запрашивается на одну строку больше, чтобы узнать, есть ли следующая страница
+	query := selectQuery + `
+	WHERE ` + where + `
+	ORDER BY ` + orderBy + `
+	LIMIT ` + args.add(limit+1)
+
+	var songs []models.Songs
+	err = r.withFuzzyThreshold(ctx, filter.Fuzzy, func(ctx context.Context) error {
+		var err error
+		songs, err = r.querySongs(ctx, query, args)
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("SongsRepository.GetSongsByCursor %w", err)
+	}
+
+	hasMore := len(songs) > limit
+	if hasMore {
+		songs = songs[:limit]
+	}
+	if backward {
+		for i, j := 0, len(songs)-1; i < j; i, j = i+1, j-1 {
+			songs[i], songs[j] = songs[j], songs[i]
+		}
+	}
+
+	return songs, hasMore, nil
+}
+
+// Метод для получения количества песен, подходящих под фильтр
+func (r *SongsRepository) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.withFuzzyThreshold(ctx, filter.Fuzzy, func(ctx context.Context) error {
+		var err error
+		count, err = r.countSongs(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("SongsRepository.CountSongs %w", err)
+	}
+	return count, nil
+}
 
+// Метод для выполнения запроса списка песен и чтения всех строк
+func (r *SongsRepository) querySongs(ctx context.Context, query string, args queryArgs) ([]models.Songs, error) {
 	logrus.WithFields(logrus.Fields{
 		"query":  query,
-		"params": []interface{}{"%" + filter.Song + "%", "%" + filter.Group + "%", "%" + filter.Text + "%", "%" + filter.ReleaseDate + "%", "%" + filter.Link + "%", pageSize, offset},
+		"params": args,
 	}).Debug("Executing query")
 
-	rows, err := r.db.Query(context.Background(), query, "%"+filter.Song+"%", "%"+filter.Group+"%", "%"+filter.Text+"%", "%"+filter.ReleaseDate+"%", "%"+filter.Link+"%", pageSize, offset)
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
 	if err != nil {
-		logrus.WithError(err).Error("SongsRepository.GetAllSongs query error")
-		return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs query error: %w", err)
+		logrus.WithError(err).Error("SongsRepository query error")
+		return nil, fmt.Errorf("query error: %w", err)
 	}
 	defer rows.Close()
 
 	var songs []models.Songs
 
 	for rows.Next() {
-		var song models.Songs
-		if err := rows.Scan(&song.ID, &song.Song, &song.Group, &song.Text, &song.ReleaseDate, &song.Link); err != nil {
-			logrus.WithError(err).Error("SongsRepository.GetAllSongs scan error")
-			return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs scan error: %w", err)
+		song, err := scanSong(rows)
+		if err != nil {
+			logrus.WithError(err).Error("SongsRepository scan error")
+			return nil, fmt.Errorf("scan error: %w", err)
 		}
 		songs = append(songs, song)
 	}
 
 	if err := rows.Err(); err != nil {
-		logrus.WithError(err).Error("SongsRepository.GetAllSongs rows error")
-		return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs rows error: %w", err)
+		logrus.WithError(err).Error("SongsRepository rows error")
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	// Запрос для получения общего количества записей
+	return songs, nil
+}
+
+// Метод для подсчёта песен по фильтру
+func (r *SongsRepository) countSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	var args queryArgs
 	countQuery := `
-	SELECT COUNT(*)
-	FROM songs s
-	INNER JOIN groups g ON s.group_id = g.id
-	WHERE s.song ILIKE $1 AND g."group" ILIKE $2 AND s.text ILIKE $3 AND s.release_date ILIKE $4 AND s.link ILIKE $5`
+	SELECT COUNT(*)` + songsFrom + `
+	WHERE ` + songsWhere(filter, &args)
 
 	logrus.WithFields(logrus.Fields{
 		"query":  countQuery,
-		"params": []interface{}{"%" + filter.Song + "%", "%" + filter.Group + "%", "%" + filter.Text + "%", "%" + filter.ReleaseDate + "%", "%" + filter.Link + "%"},
+		"params": args,
 	}).Debug("Executing count query")
 
 	var totalRecords int
-	err = r.db.QueryRow(context.Background(), countQuery, "%"+filter.Song+"%", "%"+filter.Group+"%", "%"+filter.Text+"%", "%"+filter.ReleaseDate+"%", "%"+filter.Link+"%").Scan(&totalRecords)
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery, args...).Scan(&totalRecords)
 	if err != nil {
-		logrus.WithError(err).Error("SongsRepository.GetAllSongs count query error")
-		return nil, 0, fmt.Errorf("SongsRepository.GetAllSongs count query error: %w", err)
+		logrus.WithError(err).Error("SongsRepository count query error")
+		return 0, fmt.Errorf("count query error: %w", err)
 	}
 
-	// Вычисление общего количества страниц
-	totalPages := (totalRecords + pageSize - 1) / pageSize
-	return songs, totalPages, nil
+	return totalRecords, nil
 }
 
 // Метод для получения песни по ID
-func (r *SongsRepository) GetSongByID(id int) (models.Songs, error) {
+func (r *SongsRepository) GetSongByID(ctx context.Context, id int) (models.Songs, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	// Построение SQL-запроса для получения песни
-	query := `SELECT s.id, g."group", s.song, s.text, s.release_date, s.link
-	          FROM songs s
-	          INNER JOIN groups g ON s.group_id = g.id
-	          WHERE s.id = $1`
+	var args queryArgs
+	query := songsSelect(models.SongFilter{}, &args) + `
+	WHERE s.id = ` + args.add(id) + ` AND s.deleted_at IS NULL`
 
 	logrus.WithFields(logrus.Fields{
 		"query":  query,
@@ -98,12 +215,11 @@ func (r *SongsRepository) GetSongByID(id int) (models.Songs, error) {
 	}).Debug("Executing query")
 
 	// Выполнение запроса к базе данных
-	var song models.Songs
-	err := r.db.QueryRow(context.Background(), query, id).Scan(&song.ID, &song.Group, &song.Song, &song.Text, &song.ReleaseDate, &song.Link)
+	song, err := scanSong(conn(ctx, r.db).QueryRow(ctx, query, id))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			logrus.WithField("id", id).Info("Song not found")
-			return models.Songs{}, fmt.Errorf("song with id %d not found", id)
+			return models.Songs{}, fmt.Errorf("song with id %d %w", id, ErrNotFound)
 		}
 		logrus.WithError(err).Error("SongsRepository.GetSongByID query error")
 		return models.Songs{}, fmt.Errorf("SongsRepository.GetSongByID query error: %w", err)
@@ -112,251 +228,293 @@ func (r *SongsRepository) GetSongByID(id int) (models.Songs, error) {
 	return song, nil
 }
 
-// Метод для создания новой песни
-func (r *SongsRepository) PostSong(song models.Songs) error {
+// Метод для создания новой песни, возвращает ID созданной песни. Песня сразу ставится в очередь
+// обогащения: первая попытка выполняется без задержки.
+func (r *SongsRepository) PostSong(ctx context.Context, song models.Songs) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	// Убедиться, что группа существует или создать её
-	groupID, err := r.ensureGroupExists(song.Group)
-	if err != nil {
-		logrus.WithError(err).Error("Error ensuring group exists")
-		return err
-	}
-
-	// Построение SQL-запроса для вставки новой песни
-	query := `INSERT INTO songs (group_id, song, text, release_date, link) VALUES ($1, $2, $3, $4, $5)`
-	logrus.WithFields(logrus.Fields{
-		"query":  query,
-		"params": []interface{}{groupID, song.Song, song.Text, song.ReleaseDate, song.Link},
-	}).Debug("Executing query")
-
-	_, err = r.db.Exec(context.Background(), query, groupID, song.Song, song.Text, song.ReleaseDate, song.Link)
-	if err != nil {
-		logrus.WithError(err).Error("Error inserting song")
-	}
-	return err
-}
+	var songID int
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Убедиться, что группа существует или создать её
+		groupID, err := ensureGroupExists(ctx, conn(ctx, r.db), song.Group)
+		if err != nil {
+			logrus.WithError(err).Error("Error ensuring group exists")
+			return err
+		}
 
-// Метод для обновления песни по ID
-func (r *SongsRepository) UpdateSong(songID int, song models.Songs) error {
+		releaseDate, err := releaseDateValue(song.ReleaseDate)
+		if err != nil {
+			return err
+		}
 
-	var currentGroupID, groupID int
-	var err error
+		// Песня с тем же названием в группе уже существует
+		if err := checkDuplicateSong(ctx, conn(ctx, r.db), groupID, song.Song, 0); err != nil {
+			return err
+		}
 
-	if song.Group != "" {
+		// Построение SQL-запроса для вставки новой песни
+		query := `
+		INSERT INTO songs (group_id, song, title_key, text, release_date, link,
+			enrichment_status, enrichment_next_attempt_at, enrichment_updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', now(), now()) RETURNING id`
+		args := []interface{}{groupID, song.Song, models.SongKey(song.Song), song.Text, releaseDate, song.Link}
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
 
-		currentGroupID, err = r.getGroupIDByName(songID)
+		err = conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(&songID)
 		if err != nil {
-			logrus.WithError(err).Error("Error getting group ID")
+			logrus.WithError(err).Error("Error inserting song")
+			return fmt.Errorf("error inserting song: %w", mapPgError(err))
+		}
+
+		if err := setPrimaryArtist(ctx, conn(ctx, r.db), songID, groupID); err != nil {
 			return err
 		}
+		return setSongCredits(ctx, conn(ctx, r.db), songID, song.Artists)
+	})
+	if err != nil {
+		return 0, err
+	}
 
+	return songID, nil
+}
+
+// Метод для обновления песни по ID
+func (r *SongsRepository) UpdateSong(ctx context.Context, songID int, song models.Songs) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
 		// Убедиться, что группа существует или создать её
-		groupID, err = r.ensureGroupExists(song.Group)
+		groupID, err := ensureGroupExists(ctx, conn(ctx, r.db), song.Group)
 		if err != nil {
 			logrus.WithError(err).Error("Error ensuring group exists")
 			return err
 		}
-	}
 
-	// Построение SQL-запроса для обновления песни
-	query := `UPDATE songs SET `
-	var args []interface{}
-	var argIndex = 2
+		// Построение SQL-запроса для обновления песни
+		query := `UPDATE songs SET `
+		var args []interface{}
+		var argIndex = 2
 
-	if song.Song != "" {
-		query += `song = $` + strconv.Itoa(argIndex)
-		args = append(args, song.Song)
-		argIndex++
-	}
-	if groupID != 0 {
-		if len(args) > 0 {
-			query += `, `
+		if song.Song != "" || groupID != 0 {
+			// Новое название или группа не должны совпасть с другой песней группы
+			if err := checkDuplicateSong(ctx, conn(ctx, r.db), groupID, song.Song, songID); err != nil {
+				return err
+			}
 		}
-		query += `group_id = $` + strconv.Itoa(argIndex)
-		args = append(args, groupID)
-		argIndex++
-	}
-	if song.Text != "" {
-		if len(args) > 0 {
-			query += `, `
+
+		if song.Song != "" {
+			query += `song = $` + strconv.Itoa(argIndex) + `, title_key = $` + strconv.Itoa(argIndex+1)
+			args = append(args, song.Song, models.SongKey(song.Song))
+			argIndex += 2
 		}
-		query += `text = $` + strconv.Itoa(argIndex)
-		args = append(args, song.Text)
-		argIndex++
-	}
-	if song.ReleaseDate != "" {
-		if len(args) > 0 {
-			query += `, `
+		if groupID != 0 {
+			if len(args) > 0 {
+				query += `, `
+			}
+			// При переходе в другую группу песня исключается из альбома прежней группы
+			groupParam := `$` + strconv.Itoa(argIndex)
+			query += `group_id = ` + groupParam +
+				`, album_id = CASE WHEN group_id = ` + groupParam + ` THEN album_id END` +
+				`, track_number = CASE WHEN group_id = ` + groupParam + ` THEN track_number END`
+			args = append(args, groupID)
+			argIndex++
 		}
-		query += `release_date = $` + strconv.Itoa(argIndex)
-		args = append(args, song.ReleaseDate)
-		argIndex++
-	}
-	if song.Link != "" {
-		if len(args) > 0 {
-			query += `, `
+		if song.Text != "" {
+			if len(args) > 0 {
+				query += `, `
+			}
+			query += `text = $` + strconv.Itoa(argIndex)
+			args = append(args, song.Text)
+			argIndex++
+		}
+		if song.ReleaseDate != "" {
+			releaseDate, err := releaseDateValue(song.ReleaseDate)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				query += `, `
+			}
+			query += `release_date = $` + strconv.Itoa(argIndex)
+			args = append(args, releaseDate)
+			argIndex++
+		}
+		if song.Link != "" {
+			if len(args) > 0 {
+				query += `, `
+			}
+			query += `link = $` + strconv.Itoa(argIndex)
+			args = append(args, song.Link)
+			argIndex++
 		}
-		query += `link = $` + strconv.Itoa(argIndex)
-		args = append(args, song.Link)
-		argIndex++
-	}
 
-	query += ` WHERE id = $1`
-	args = append([]interface{}{songID}, args...)
+		if len(args) == 0 && song.Artists == nil {
+			return fmt.Errorf("invalid update id: %v data: no fields to update", songID)
+		}
 
-	logrus.WithFields(logrus.Fields{
-		"query":  query,
-		"params": args,
-	}).Debug("Executing query")
+		// Изменяются только исполнители: достаточно увеличить версию существующей песни
+		if len(args) == 0 {
+			if err := touchSong(ctx, conn(ctx, r.db), songID); err != nil {
+				return err
+			}
+			return setSongCredits(ctx, conn(ctx, r.db), songID, song.Artists)
+		}
+
+		query += `, version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+		args = append([]interface{}{songID}, args...)
 
-	_, err = r.db.Exec(context.Background(), query, args...)
-	if err != nil {
 		logrus.WithFields(logrus.Fields{
-			"songID": songID,
-			"error":  err,
-		}).Error("Error updating song")
-		return fmt.Errorf("invalid update id: %v data: %v", songID, err)
-	}
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
 
-	if currentGroupID != groupID {
-		if err = r.ensureGroupUsed(currentGroupID, songID); err != nil {
+		tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
+		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"songID": songID,
 				"error":  err,
-			}).Error("Error checking group_id for song")
+			}).Error("Error updating song")
+			return fmt.Errorf("invalid update id: %v data: %w", songID, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("songID", songID).Info("Song not found")
+			return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
 		}
-	}
-
-	return nil
-}
-
-// Метод для удаления песни по ID
-func (r *SongsRepository) DeleteSong(songID int) error {
-	// Получение group_id для песни
-	groupID, err := r.getGroupIDByName(songID)
-	if err != nil {
-		logrus.WithError(err).Error("Error getting group ID")
-		return err
-	}
-
-	if err = r.ensureGroupUsed(groupID, songID); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"songID": songID,
-			"error":  err,
-		}).Error("Error checking group_id for song")
-	}
-
-	// Удаление песни
-	query := `DELETE FROM songs WHERE id = $1`
-	logrus.WithFields(logrus.Fields{
-		"query":  query,
-		"params": songID,
-	}).Debug("Executing query")
 
-	_, err = r.db.Exec(context.Background(), query, songID)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"songID": songID,
-			"error":  err,
-		}).Error("Error deleting song")
-		return fmt.Errorf("error deleting song with id %d: %v", songID, err)
-	}
+		if groupID != 0 {
+			if err := setPrimaryArtist(ctx, conn(ctx, r.db), songID, groupID); err != nil {
+				return err
+			}
+		}
+		if song.Artists != nil {
+			return setSongCredits(ctx, conn(ctx, r.db), songID, song.Artists)
+		}
 
-	return nil
+		return nil
+	})
 }
 
-// Метод для обеспечения существования группы
-func (r *SongsRepository) ensureGroupExists(groupName string) (int, error) {
-	if groupName == "" {
-		return 0, nil
-	}
-
-	// Построение SQL-запроса для поиска группы
-	query := `SELECT id FROM groups WHERE "group" ILIKE '%' || $1 || '%' LIMIT 1`
-	logrus.WithFields(logrus.Fields{
-		"query":  query,
-		"params": groupName,
-	}).Debug("Executing query")
+// Метод для замены всех полей и исполнителей песни по ID: пустые значения очищают поля,
+// nil вместо списка исполнителей удаляет все дополнительные исполнители
+func (r *SongsRepository) ReplaceSong(ctx context.Context, songID int, song models.Songs) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	var groupID int
-	err := r.db.QueryRow(context.Background(), query, groupName).Scan(&groupID)
-	if err == pgx.ErrNoRows {
-		// Группа не существует, создать её
-		insertQuery := `INSERT INTO groups ("group") VALUES ($1) RETURNING id`
-		logrus.WithFields(logrus.Fields{
-			"query":  insertQuery,
-			"params": groupName,
-		}).Debug("Executing query")
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Убедиться, что группа существует или создать её
+		groupID, err := ensureGroupExists(ctx, conn(ctx, r.db), song.Group)
+		if err != nil {
+			logrus.WithError(err).Error("Error ensuring group exists")
+			return err
+		}
 
-		err := r.db.QueryRow(context.Background(), insertQuery, groupName).Scan(&groupID)
+		releaseDate, err := releaseDateValue(song.ReleaseDate)
 		if err != nil {
-			logrus.WithError(err).Error("Error inserting group")
-			return 0, fmt.Errorf("error inserting group: %v", err)
+			return err
 		}
-		return groupID, nil
-	} else if err != nil {
-		logrus.WithError(err).Error("Error querying group")
-		return 0, fmt.Errorf("error querying group: %v", err)
-	}
-	return groupID, nil
-}
 
-func (r *SongsRepository) ensureGroupUsed(groupID, songID int) error {
-	// Подсчет количества песен в группе
-	var count int
-	query := `SELECT COUNT(*) FROM songs WHERE group_id = $1 AND id <> $2`
-	logrus.WithFields(logrus.Fields{
-		"query":  query,
-		"params": []interface{}{groupID, songID},
-	}).Debug("Executing query")
+		if err := checkDuplicateSong(ctx, conn(ctx, r.db), groupID, song.Song, songID); err != nil {
+			return err
+		}
 
-	err := r.db.QueryRow(context.Background(), query, groupID, songID).Scan(&count)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"groupID": groupID,
-			"error":   err,
-		}).Error("Error counting songs for group")
-		return fmt.Errorf("error counting songs for group_id %d: %v", groupID, err)
-	}
+		// При переходе в другую группу песня исключается из альбома прежней группы
+		query := `
+		UPDATE songs SET song = $2, title_key = $7, group_id = $3,
+			album_id = CASE WHEN group_id = $3 THEN album_id END,
+			track_number = CASE WHEN group_id = $3 THEN track_number END,
+			text = $4, release_date = $5, link = $6, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL`
+		args := []interface{}{songID, song.Song, groupID, song.Text, releaseDate, song.Link, models.SongKey(song.Song)}
 
-	// Удаление группы, если она больше не используется
-	if count == 0 {
-		query = `DELETE FROM groups WHERE id = $1`
 		logrus.WithFields(logrus.Fields{
 			"query":  query,
-			"params": groupID,
+			"params": args,
 		}).Debug("Executing query")
 
-		_, err = r.db.Exec(context.Background(), query, groupID)
+		tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
-				"groupID": groupID,
-				"error":   err,
-			}).Error("Error deleting group")
-			return fmt.Errorf("error deleting group with id %d: %v", groupID, err)
+				"songID": songID,
+				"error":  err,
+			}).Error("Error replacing song")
+			return fmt.Errorf("invalid replace id: %v data: %w", songID, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("songID", songID).Info("Song not found")
+			return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
 		}
-	}
 
-	return nil
+		if err := setPrimaryArtist(ctx, conn(ctx, r.db), songID, groupID); err != nil {
+			return err
+		}
+		return setSongCredits(ctx, conn(ctx, r.db), songID, song.Artists)
+	})
 }
 
-func (r *SongsRepository) getGroupIDByName(songID int) (int, error) {
-	var groupID int
+// Метод для проверки, что песня не изменилась с версии version; строка песни блокируется
+// до конца транзакции. ErrNotFound, если песни нет, ErrVersionMismatch, если версия другая.
+func (r *SongsRepository) CheckSongVersion(ctx context.Context, songID, version int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	query := `SELECT group_id FROM songs WHERE id = $1`
+	query := `SELECT version FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
 	logrus.WithFields(logrus.Fields{
 		"query":  query,
 		"params": songID,
 	}).Debug("Executing query")
 
-	err := r.db.QueryRow(context.Background(), query, songID).Scan(&groupID)
-	if err != nil {
+	var current int
+	if err := conn(ctx, r.db).QueryRow(ctx, query, songID).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.WithField("songID", songID).Info("Song not found")
+			return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+		}
+		logrus.WithError(err).Error("SongsRepository.CheckSongVersion query error")
+		return fmt.Errorf("SongsRepository.CheckSongVersion query error: %w", err)
+	}
+	if current != version {
 		logrus.WithFields(logrus.Fields{
-			"songID": songID,
-			"error":  err,
-		}).Error("Error getting group_id for song")
-		return 0, fmt.Errorf("error getting group_id for song id %d: %v", songID, err)
+			"songID":   songID,
+			"expected": version,
+			"current":  current,
+		}).Info("Song version mismatch")
+		return fmt.Errorf("song with id %d has version %d, not %d: %w", songID, current, version, ErrVersionMismatch)
 	}
 
-	return groupID, nil
+	return nil
+}
+
+// Метод для удаления песни по ID: песня перемещается в корзину (deleted_at) и окончательно
+// удаляется очисткой корзины. Группа остаётся и после удаления её последней песни.
+func (r *SongsRepository) DeleteSong(ctx context.Context, songID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		query := `UPDATE songs SET deleted_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": songID,
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, songID)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"songID": songID,
+				"error":  err,
+			}).Error("Error deleting song")
+			return fmt.Errorf("error deleting song with id %d: %w", songID, mapPgError(err))
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithField("songID", songID).Info("Song not found")
+			return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+		}
+
+		return nil
+	})
 }
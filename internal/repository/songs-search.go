@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Параметры ts_headline для фрагментов куплетов
+const headlineOptions = `StartSel=<b>, StopSel=</b>, MaxWords=35, MinWords=10`
+
+// Метод для полнотекстового поиска по текстам песен.
+// Результаты упорядочены по ts_rank, для каждой песни возвращаются фрагменты совпавших куплетов
+// (куплеты разделены пустой строкой и нумеруются с 1, как параметр vers в GET /songs/{id}).
+func (r *SongsRepository) SearchSongs(ctx context.Context, query string, page, pageSize int) ([]models.SearchResult, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * pageSize
+
+	// Сначала выбирается страница по рангу, затем фрагменты строятся только для неё.
+	// Конфигурация поиска берётся из search_settings - той же, которой построен вектор search_vector,
+	// иначе запрос и вектор стеммились бы по-разному и совпадения терялись бы.
+	searchQuery := `
+	WITH q AS (SELECT language, websearch_to_tsquery(language, $1) AS query FROM search_settings),
+	page AS (
+		SELECT s.id, ts_rank(s.search_vector, q.query) AS rank
+		FROM songs s, q
+		WHERE s.search_vector @@ q.query AND s.deleted_at IS NULL
+		ORDER BY rank DESC, s.id
+		LIMIT $2 OFFSET $3
+	)
+	SELECT s.id, s.song, g."group", s.text, ` + releaseDateText + `, s.link, ` + songAlbumColumns + `, ` + songArtistsColumn + `,
+	       ` + genreKind.songColumn() + `, ` + tagKind.songColumn() + `, s.deleted_at, s.version, page.rank,
+	       COALESCE(h.verses, '{}'), COALESCE(h.headlines, '{}')
+	FROM page
+	INNER JOIN songs s ON s.id = page.id
+	INNER JOIN groups g ON s.group_id = g.id
+	LEFT JOIN albums a ON s.album_id = a.id
+	CROSS JOIN q
+	LEFT JOIN LATERAL (
+		SELECT array_agg(v.n::int ORDER BY v.n) AS verses,
+		       array_agg(ts_headline(q.language, v.verse, q.query, '` + headlineOptions + `') ORDER BY v.n) AS headlines
+		FROM regexp_split_to_table(s.text, E'\n\n') WITH ORDINALITY AS v(verse, n)
+		WHERE to_tsvector(q.language, v.verse) @@ q.query
+	) h ON true
+	ORDER BY page.rank DESC, s.id`
+
+	args := []interface{}{query, pageSize, offset}
+	logrus.WithFields(logrus.Fields{
+		"query":  searchQuery,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, searchQuery, args...)
+	if err != nil {
+		logrus.WithError(err).Error("SongsRepository.SearchSongs query error")
+		return nil, 0, fmt.Errorf("SongsRepository.SearchSongs query error: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+
+	for rows.Next() {
+		var result models.SearchResult
+		var verses []int32
+		var headlines []string
+		var albumID, trackNumber *int
+		var albumTitle *string
+		if err := rows.Scan(&result.ID, &result.Song, &result.Group, &result.Text, &result.ReleaseDate, &result.Link,
+			&albumID, &albumTitle, &trackNumber, &result.Artists, &result.Genres, &result.Tags,
+			&result.DeletedAt, &result.Version, &result.Rank, &verses, &headlines); err != nil {
+			logrus.WithError(err).Error("SongsRepository.SearchSongs scan error")
+			return nil, 0, fmt.Errorf("SongsRepository.SearchSongs scan error: %w", err)
+		}
+		result.Album = songAlbum(albumID, albumTitle, trackNumber)
+
+		result.Snippets = make([]models.Snippet, 0, len(verses))
+		for i := range verses {
+			result.Snippets = append(result.Snippets, models.Snippet{Verse: int(verses[i]), Text: headlines[i]})
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("SongsRepository.SearchSongs rows error")
+		return nil, 0, fmt.Errorf("SongsRepository.SearchSongs rows error: %w", err)
+	}
+
+	// Запрос для получения общего количества найденных песен
+	countQuery := `SELECT COUNT(*) FROM songs s
+	WHERE s.search_vector @@ websearch_to_tsquery((SELECT language FROM search_settings), $1) AND s.deleted_at IS NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": args[:1],
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, args[:1]...).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("SongsRepository.SearchSongs count query error")
+		return nil, 0, fmt.Errorf("SongsRepository.SearchSongs count query error: %w", err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return results, totalPages, nil
+}
+
+// Функция для перестройки вектора search_vector под конфигурацию полнотекстового поиска language
+// (regconfig, например russian или english) и сохранения её в search_settings.
+// Пустая конфигурация оставляет ту, которой вектор построен сейчас.
+func applySearchLanguage(db *pgxpool.Pool, language string) error {
+	if language == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error beginning search language change: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Строка настроек блокируется, чтобы вектор не перестраивали одновременно два экземпляра;
+	// приведение к regconfig отклоняет неизвестную конфигурацию
+	var current, requested string
+	var same bool
+	err = tx.QueryRow(ctx, `SELECT language::text, $1::regconfig::text, language = $1::regconfig
+	FROM search_settings FOR UPDATE`, language).Scan(&current, &requested, &same)
+	if err != nil {
+		return fmt.Errorf("error checking search language %q: %w", language, err)
+	}
+	if same {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"from": current,
+		"to":   requested,
+	}).Info("Rebuilding search vector for the new search language")
+
+	// Выражение генерируемого столбца не принимает параметры, поэтому конфигурация подставляется
+	// литералом в том виде, в котором её вернул PostgreSQL
+	config := "'" + strings.ReplaceAll(requested, "'", "''") + "'::regconfig"
+	statements := []string{
+		`DROP INDEX IF EXISTS idx_songs_search_vector`,
+		`ALTER TABLE songs DROP COLUMN IF EXISTS search_vector`,
+		`ALTER TABLE songs ADD COLUMN search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector(` + config + `, coalesce(song, '')), 'A') ||
+			setweight(to_tsvector(` + config + `, coalesce(text, '')), 'B')
+		) STORED`,
+		`CREATE INDEX idx_songs_search_vector ON songs USING GIN (search_vector)`,
+		`UPDATE search_settings SET language = ` + config,
+	}
+	for _, statement := range statements {
+		logrus.WithField("query", statement).Debug("Executing query")
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("error rebuilding search vector: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing search language change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для восстановления песни из корзины; ErrNotFound, если такой песни в корзине нет
+func (r *SongsRepository) RestoreSong(ctx context.Context, songID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// За время нахождения в корзине в группе могла появиться песня с тем же названием
+	if err := checkDuplicateSong(ctx, conn(ctx, r.db), 0, "", songID); err != nil {
+		return err
+	}
+
+	query := `UPDATE songs SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": songID,
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Error restoring song")
+		return fmt.Errorf("error restoring song with id %d: %w", songID, mapPgError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		logrus.WithField("songID", songID).Info("Song not found in trash")
+		return fmt.Errorf("song with id %d in trash %w", songID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// Метод для окончательного удаления песен, перемещённых в корзину раньше before.
+// Возвращает количество удалённых песен.
+func (r *SongsRepository) PurgeDeletedSongs(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM songs WHERE deleted_at < $1`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": before,
+	}).Debug("Executing query")
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, before)
+	if err != nil {
+		logrus.WithError(err).Error("Error purging deleted songs")
+		return 0, fmt.Errorf("error purging songs deleted before %s: %w", before.Format(time.RFC3339), err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура memoryLabels, описывающая справочник жанров или тегов в памяти
+type memoryLabels struct {
+	names  map[int]string
+	nextID int
+}
+
+// Функция для создания пустого справочника в памяти
+func newMemoryLabels() memoryLabels {
+	return memoryLabels{names: make(map[int]string), nextID: 1}
+}
+
+// Метод для получения копии справочника для снимка транзакции
+func (l memoryLabels) clone() memoryLabels {
+	names := make(map[int]string, len(l.names))
+	for id, name := range l.names {
+		names[id] = name
+	}
+	return memoryLabels{names: names, nextID: l.nextID}
+}
+
+// Метод для поиска имени в справочнике с его созданием при отсутствии
+func (l *memoryLabels) ensure(name string) int {
+	for id, existing := range l.names {
+		if existing == name {
+			return id
+		}
+	}
+	id := l.nextID
+	l.names[id] = name
+	l.nextID++
+	return id
+}
+
+// Метод для получения имён справочника по идентификаторам в порядке имён
+func (l memoryLabels) sortedNames(ids []int) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, l.names[id])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Метод для получения справочника жанров или тегов
+func (m *SongsMemory) dictionary(kind labelKind) *memoryLabels {
+	if kind == genreKind {
+		return &m.genres
+	}
+	return &m.tags
+}
+
+// Функция для получения идентификаторов жанров или тегов у записи песни
+func labelIDs(kind labelKind, record *songRecord) *[]int {
+	if kind == genreKind {
+		return &record.genres
+	}
+	return &record.tags
+}
+
+// Метод для получения жанров с количеством песен, фильтрацией по подстроке имени и пагинацией
+func (m *SongsMemory) GetAllGenres(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error) {
+	return m.getLabels(ctx, genreKind, name, page, pageSize)
+}
+
+// Метод для получения тегов с количеством песен, фильтрацией по подстроке имени и пагинацией
+func (m *SongsMemory) GetAllTags(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error) {
+	return m.getLabels(ctx, tagKind, name, page, pageSize)
+}
+
+// Метод для добавления песне жанров; отсутствующие жанры создаются
+func (m *SongsMemory) AddSongGenres(ctx context.Context, songID int, names []string) error {
+	return m.addSongLabels(ctx, genreKind, songID, names)
+}
+
+// Метод для добавления песне тегов; отсутствующие теги создаются
+func (m *SongsMemory) AddSongTags(ctx context.Context, songID int, names []string) error {
+	return m.addSongLabels(ctx, tagKind, songID, names)
+}
+
+// Метод для замены всех жанров песни; отсутствующие жанры создаются
+func (m *SongsMemory) SetSongGenres(ctx context.Context, songID int, names []string) error {
+	return m.setSongLabels(ctx, genreKind, songID, names)
+}
+
+// Метод для замены всех тегов песни; отсутствующие теги создаются
+func (m *SongsMemory) SetSongTags(ctx context.Context, songID int, names []string) error {
+	return m.setSongLabels(ctx, tagKind, songID, names)
+}
+
+// Метод для удаления жанра у песни
+func (m *SongsMemory) DeleteSongGenre(ctx context.Context, songID int, name string) error {
+	return m.deleteSongLabel(ctx, genreKind, songID, name)
+}
+
+// Метод для удаления тега у песни
+func (m *SongsMemory) DeleteSongTag(ctx context.Context, songID int, name string) error {
+	return m.deleteSongLabel(ctx, tagKind, songID, name)
+}
+
+// Метод для получения страницы справочника, как в TagsRepository
+func (m *SongsMemory) getLabels(ctx context.Context, kind labelKind, name string, page, pageSize int) ([]models.Label, int, error) {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 {
+		return nil, 0, fmt.Errorf("SongsMemory.getLabels invalid pagination: page %d, pageSize %d", page, pageSize)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SongsMemory.getLabels: %w", err)
+	}
+
+	defer m.rlock(ctx)()
+
+	dictionary := m.dictionary(kind)
+	counts := make(map[int]int, len(dictionary.names))
+	for _, record := range m.songs {
+		if !record.deletedAt.IsZero() {
+			continue
+		}
+		for _, id := range *labelIDs(kind, &record) {
+			counts[id]++
+		}
+	}
+
+	var labels []models.Label
+	for id, label := range dictionary.names {
+		if ilike(label, "%"+name+"%") {
+			labels = append(labels, models.Label{Name: label, SongCount: counts[id]})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].SongCount != labels[j].SongCount {
+			return labels[i].SongCount > labels[j].SongCount
+		}
+		return labels[i].Name < labels[j].Name
+	})
+
+	// Вычисление общего количества страниц
+	totalPages := (len(labels) + pageSize - 1) / pageSize
+
+	if offset >= len(labels) {
+		return []models.Label{}, totalPages, nil
+	}
+	return labels[offset:min(offset+pageSize, len(labels))], totalPages, nil
+}
+
+// Метод для связывания песни с именами справочника, как в TagsRepository
+func (m *SongsMemory) addSongLabels(ctx context.Context, kind labelKind, songID int, names []string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.addSongLabels: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	dictionary, ids := m.dictionary(kind), labelIDs(kind, &record)
+	attached := slices.Clone(*ids)
+	for _, name := range names {
+		if id := dictionary.ensure(models.NormalizeLabel(name)); !slices.Contains(attached, id) {
+			attached = append(attached, id)
+		}
+	}
+	*ids = attached
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для замены всех имён справочника, связанных с песней, как в TagsRepository
+func (m *SongsMemory) setSongLabels(ctx context.Context, kind labelKind, songID int, names []string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.setSongLabels: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	dictionary, ids := m.dictionary(kind), labelIDs(kind, &record)
+	attached := []int{}
+	for _, name := range names {
+		if id := dictionary.ensure(models.NormalizeLabel(name)); !slices.Contains(attached, id) {
+			attached = append(attached, id)
+		}
+	}
+	*ids = attached
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для удаления связи песни с именем справочника, как в TagsRepository
+func (m *SongsMemory) deleteSongLabel(ctx context.Context, kind labelKind, songID int, name string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SongsMemory.deleteSongLabel: %w", err)
+	}
+
+	defer m.lock(ctx)()
+
+	record, ok := m.songs[songID]
+	if !ok || !record.deletedAt.IsZero() {
+		logrus.WithField("songID", songID).Info("Song not found")
+		return fmt.Errorf("song with id %d %w", songID, ErrNotFound)
+	}
+
+	name = models.NormalizeLabel(name)
+	dictionary, ids := m.dictionary(kind), labelIDs(kind, &record)
+	index := slices.IndexFunc(*ids, func(id int) bool { return dictionary.names[id] == name })
+	if index < 0 {
+		logrus.WithFields(logrus.Fields{"songID": songID, kind.name: name}).Info("Label not attached")
+		return fmt.Errorf("%s %q of song with id %d %w", kind.name, name, songID, ErrNotFound)
+	}
+	*ids = slices.Delete(slices.Clone(*ids), index, index+1)
+	record.version++
+	m.songs[songID] = record
+
+	return nil
+}
+
+// Метод для получения имён справочника, связанных с песней, в порядке имён; nil, если их нет
+func (m *SongsMemory) songLabels(kind labelKind, record songRecord) []string {
+	dictionary, ids := m.dictionary(kind), labelIDs(kind, &record)
+	if len(*ids) == 0 {
+		return nil
+	}
+	return dictionary.sortedNames(*ids)
+}
+
+// Функция для проверки фильтра по справочнику, как labelKind.songCondition
+func labelsMatch(names []string, filter models.LabelFilter) bool {
+	if len(filter.Names) == 0 {
+		return true
+	}
+	for _, name := range filter.Names {
+		found := slices.Contains(names, models.NormalizeLabel(name))
+		if found && !filter.All {
+			return true
+		}
+		if !found && filter.All {
+			return false
+		}
+	}
+	return filter.All
+}
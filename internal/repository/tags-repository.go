@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Структура labelKind, описывающая справочник (жанры или теги) и таблицу его связей с песнями
+type labelKind struct {
+	name   string // genre или tag, для сообщений об ошибках
+	table  string
+	link   string
+	column string // столбец ссылки на справочник в таблице связей
+}
+
+// Справочники жанров и тегов
+var (
+	genreKind = labelKind{name: "genre", table: "genres", link: "song_genres", column: "genre_id"}
+	tagKind   = labelKind{name: "tag", table: "tags", link: "song_tags", column: "tag_id"}
+)
+
+// Метод для построения выражения со списком имён справочника, связанных с песней s, в порядке имён
+func (k labelKind) songColumn() string {
+	return `ARRAY(SELECT l.name FROM ` + k.link + ` sl INNER JOIN ` + k.table + ` l ON sl.` + k.column + ` = l.id
+		WHERE sl.song_id = s.id ORDER BY l.name)`
+}
+
+// Метод для построения условия фильтра песен s по справочнику: есть хотя бы одно из имён,
+// а при filter.All - все имена
+func (k labelKind) songCondition(filter models.LabelFilter, args *queryArgs) string {
+	names := make([]string, 0, len(filter.Names))
+	for _, name := range filter.Names {
+		if name = models.NormalizeLabel(name); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	from := `FROM ` + k.link + ` sl INNER JOIN ` + k.table + ` l ON sl.` + k.column + ` = l.id
+		WHERE sl.song_id = s.id AND l.name = ANY(` + args.add(names) + `)`
+	if !filter.All {
+		return `EXISTS (SELECT 1 ` + from + `)`
+	}
+	return `(SELECT COUNT(DISTINCT l.name) ` + from + `) = ` + args.add(len(names))
+}
+
+// Структура TagsRepository, которая инкапсулирует подключение к базе данных
+type TagsRepository struct {
+	db         *pgxpool.Pool
+	transactor Transactor
+	opts       Options
+}
+
+// Функция для создания нового экземпляра TagsRepository с подключением к базе данных
+func NewTagsRepository(db *pgxpool.Pool, transactor Transactor, opts Options) *TagsRepository {
+	return &TagsRepository{db: db, transactor: transactor, opts: opts}
+}
+
+// Метод для ограничения времени выполнения запросов в рамках одного вызова репозитория
+func (r *TagsRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.QueryTimeout)
+}
+
+// Метод для получения жанров с количеством песен, фильтрацией по подстроке имени и пагинацией
+func (r *TagsRepository) GetAllGenres(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error) {
+	return r.getLabels(ctx, genreKind, name, page, pageSize)
+}
+
+// Метод для получения тегов с количеством песен, фильтрацией по подстроке имени и пагинацией
+func (r *TagsRepository) GetAllTags(ctx context.Context, name string, page, pageSize int) ([]models.Label, int, error) {
+	return r.getLabels(ctx, tagKind, name, page, pageSize)
+}
+
+// Метод для добавления песне жанров; отсутствующие жанры создаются
+func (r *TagsRepository) AddSongGenres(ctx context.Context, songID int, names []string) error {
+	return r.addSongLabels(ctx, genreKind, songID, names)
+}
+
+// Метод для добавления песне тегов; отсутствующие теги создаются
+func (r *TagsRepository) AddSongTags(ctx context.Context, songID int, names []string) error {
+	return r.addSongLabels(ctx, tagKind, songID, names)
+}
+
+// Метод для замены всех жанров песни; отсутствующие жанры создаются
+func (r *TagsRepository) SetSongGenres(ctx context.Context, songID int, names []string) error {
+	return r.setSongLabels(ctx, genreKind, songID, names)
+}
+
+// Метод для замены всех тегов песни; отсутствующие теги создаются
+func (r *TagsRepository) SetSongTags(ctx context.Context, songID int, names []string) error {
+	return r.setSongLabels(ctx, tagKind, songID, names)
+}
+
+// Метод для удаления жанра у песни
+func (r *TagsRepository) DeleteSongGenre(ctx context.Context, songID int, name string) error {
+	return r.deleteSongLabel(ctx, genreKind, songID, name)
+}
+
+// Метод для удаления тега у песни
+func (r *TagsRepository) DeleteSongTag(ctx context.Context, songID int, name string) error {
+	return r.deleteSongLabel(ctx, tagKind, songID, name)
+}
+
+// Метод для получения страницы справочника; сначала самые используемые имена, песни в корзине не учитываются
+func (r *TagsRepository) getLabels(ctx context.Context, kind labelKind, name string, page, pageSize int) ([]models.Label, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * pageSize
+	query := `
+	SELECT l.name, COUNT(s.id)
+	FROM ` + kind.table + ` l
+	LEFT JOIN ` + kind.link + ` sl ON sl.` + kind.column + ` = l.id
+	LEFT JOIN songs s ON s.id = sl.song_id AND s.deleted_at IS NULL
+	WHERE l.name ILIKE $1
+	GROUP BY l.id
+	ORDER BY COUNT(s.id) DESC, l.name
+	LIMIT $2 OFFSET $3`
+	args := []interface{}{"%" + name + "%", pageSize, offset}
+
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": args,
+	}).Debug("Executing query")
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("TagsRepository query error")
+		return nil, 0, fmt.Errorf("TagsRepository.getLabels %s query error: %w", kind.table, err)
+	}
+	defer rows.Close()
+
+	labels := []models.Label{}
+	for rows.Next() {
+		var label models.Label
+		if err := rows.Scan(&label.Name, &label.SongCount); err != nil {
+			logrus.WithError(err).Error("TagsRepository scan error")
+			return nil, 0, fmt.Errorf("TagsRepository.getLabels %s scan error: %w", kind.table, err)
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("TagsRepository rows error")
+		return nil, 0, fmt.Errorf("TagsRepository.getLabels %s rows error: %w", kind.table, err)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM ` + kind.table + ` WHERE name ILIKE $1`
+	logrus.WithFields(logrus.Fields{
+		"query":  countQuery,
+		"params": args[:1],
+	}).Debug("Executing count query")
+
+	var totalRecords int
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, args[:1]...).Scan(&totalRecords); err != nil {
+		logrus.WithError(err).Error("TagsRepository count query error")
+		return nil, 0, fmt.Errorf("TagsRepository.getLabels %s count query error: %w", kind.table, err)
+	}
+
+	// Вычисление общего количества страниц
+	totalPages := (totalRecords + pageSize - 1) / pageSize
+	return labels, totalPages, nil
+}
+
+// Метод для связывания песни с именами справочника; уже связанные имена пропускаются
+func (r *TagsRepository) addSongLabels(ctx context.Context, kind labelKind, songID int, names []string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := touchSong(ctx, conn(ctx, r.db), songID); err != nil {
+			return err
+		}
+		return r.attachLabels(ctx, kind, songID, names)
+	})
+}
+
+// Метод для замены всех имён справочника, связанных с песней
+func (r *TagsRepository) setSongLabels(ctx context.Context, kind labelKind, songID int, names []string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := touchSong(ctx, conn(ctx, r.db), songID); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM ` + kind.link + ` WHERE song_id = $1`
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": songID,
+		}).Debug("Executing query")
+
+		if _, err := conn(ctx, r.db).Exec(ctx, query, songID); err != nil {
+			logrus.WithError(err).Errorf("Error detaching %s", kind.table)
+			return fmt.Errorf("error detaching %s from song with id %d: %w", kind.table, songID, err)
+		}
+		return r.attachLabels(ctx, kind, songID, names)
+	})
+}
+
+// Метод для связывания песни с именами справочника в текущей транзакции; отсутствующие имена создаются
+func (r *TagsRepository) attachLabels(ctx context.Context, kind labelKind, songID int, names []string) error {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, models.NormalizeLabel(name))
+	}
+
+	// DO UPDATE вместо DO NOTHING, чтобы RETURNING вернул id и уже существующих имён
+	query := `
+	WITH l AS (
+		INSERT INTO ` + kind.table + ` (name) SELECT unnest($2::text[])
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	)
+	INSERT INTO ` + kind.link + ` (song_id, ` + kind.column + `) SELECT $1, id FROM l
+	ON CONFLICT DO NOTHING`
+	logrus.WithFields(logrus.Fields{
+		"query":  query,
+		"params": []interface{}{songID, normalized},
+	}).Debug("Executing query")
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, songID, normalized); err != nil {
+		logrus.WithError(err).Errorf("Error attaching %s", kind.table)
+		return fmt.Errorf("error attaching %s to song with id %d: %w", kind.table, songID, mapPgError(err))
+	}
+	return nil
+}
+
+// Метод для удаления связи песни с именем справочника; ErrNotFound, если песни нет или имя не связано с ней
+func (r *TagsRepository) deleteSongLabel(ctx context.Context, kind labelKind, songID int, name string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := touchSong(ctx, conn(ctx, r.db), songID); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM ` + kind.link + ` sl USING ` + kind.table + ` l
+		WHERE sl.` + kind.column + ` = l.id AND sl.song_id = $1 AND l.name = $2`
+		args := []interface{}{songID, models.NormalizeLabel(name)}
+		logrus.WithFields(logrus.Fields{
+			"query":  query,
+			"params": args,
+		}).Debug("Executing query")
+
+		tag, err := conn(ctx, r.db).Exec(ctx, query, args...)
+		if err != nil {
+			logrus.WithError(err).Errorf("Error detaching %s", kind.name)
+			return fmt.Errorf("error detaching %s from song with id %d: %w", kind.name, songID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			logrus.WithFields(logrus.Fields{"songID": songID, kind.name: name}).Info("Label not attached")
+			return fmt.Errorf("%s %q of song with id %d %w", kind.name, args[1], songID, ErrNotFound)
+		}
+		return nil
+	})
+}
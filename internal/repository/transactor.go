@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Интерфейс Transactor, определяющий единицу работы над несколькими операциями репозитория
+type Transactor interface {
+	// Метод для выполнения fn в одной транзакции: все вызовы репозитория с переданным
+	// в fn контекстом используют эту транзакцию, ошибка fn откатывает все изменения
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Интерфейс querier, общий для пула соединений и транзакции pgx
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// Ключ контекста, под которым хранится активная транзакция
+type txKey struct{}
+
+// Структура PgTransactor, реализующая Transactor поверх пула соединений PostgreSQL
+type PgTransactor struct {
+	db *pgxpool.Pool
+}
+
+// Функция для создания нового экземпляра PgTransactor с подключением к базе данных
+func NewPgTransactor(db *pgxpool.Pool) *PgTransactor {
+	return &PgTransactor{db: db}
+}
+
+// Метод для выполнения fn в транзакции. Вложенные вызовы присоединяются к внешней транзакции.
+func (t *PgTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Error beginning transaction")
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	// Откат выполняется при ошибке fn, при ошибке фиксации и при панике внутри fn, чтобы
+	// соединение не вернулось в пул с открытой транзакцией
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			logrus.WithError(rbErr).Error("Error rolling back transaction")
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Error committing transaction")
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
+
+// Функция для получения активной транзакции из контекста или пула соединений вне транзакции
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Структура AlbumsService, которая инкапсулирует репозиторий для работы с альбомами
+type AlbumsService struct {
+	rep *repository.Repository
+}
+
+// Функция для создания нового экземпляра AlbumsService с заданным репозиторием
+func NewAlbumsService(rep *repository.Repository) *AlbumsService {
+	return &AlbumsService{rep}
+}
+
+// Метод для получения страницы альбомов с фильтрацией по названию и группе
+func (s *AlbumsService) GetAllAlbums(ctx context.Context, filter models.AlbumFilter, page, pageSize int) (models.AlbumsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.AlbumsPage{}, err
+	}
+
+	albums, totalPages, err := s.rep.GetAllAlbums(ctx, filter, page, pageSize)
+	if err != nil {
+		return models.AlbumsPage{}, err
+	}
+
+	return models.AlbumsPage{
+		Albums:      albums,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для получения альбома по ID
+func (s *AlbumsService) GetAlbumByID(ctx context.Context, id int) (models.Album, error) {
+	return s.rep.GetAlbumByID(ctx, id)
+}
+
+// Метод для создания нового альбома с проверкой полей и записью в журнал аудита
+func (s *AlbumsService) CreateAlbum(ctx context.Context, album models.Album) (models.Album, error) {
+	if err := ValidateAlbum(album); err != nil {
+		return models.Album{}, err
+	}
+
+	var created models.Album
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if created, err = s.rep.CreateAlbum(ctx, album); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityAlbum, models.AuditActionCreate, created.ID, nil, created)
+	})
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	return created, nil
+}
+
+// Метод для обновления альбома с проверкой полей
+func (s *AlbumsService) UpdateAlbum(ctx context.Context, id int, album models.Album) (models.Album, error) {
+	if err := ValidateAlbumUpdate(album); err != nil {
+		return models.Album{}, err
+	}
+	return s.updateAlbum(ctx, id, func(ctx context.Context, _ models.Album) (models.Album, error) {
+		return s.rep.UpdateAlbum(ctx, id, album)
+	})
+}
+
+// Метод для удаления альбома по ID с записью в журнал аудита; песни альбома
+// без альбома сохраняются как новые ревизии
+func (s *AlbumsService) DeleteAlbum(ctx context.Context, id int) error {
+	return s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetAlbumByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.rep.DeleteAlbum(ctx, id); err != nil {
+			return err
+		}
+		if err := recordTrackRevisions(ctx, s.rep, before.Tracks, nil); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityAlbum, models.AuditActionDelete, id, before, nil)
+	})
+}
+
+// Метод для замены списка треков альбома с проверкой номеров; песни, которые вошли в альбом,
+// вышли из него или сменили номер трека, сохраняются как новые ревизии
+func (s *AlbumsService) SetAlbumTracks(ctx context.Context, id int, request models.AlbumTracksRequest) (models.Album, error) {
+	if err := ValidateAlbumTracks(request); err != nil {
+		return models.Album{}, err
+	}
+	return s.updateAlbum(ctx, id, func(ctx context.Context, before models.Album) (models.Album, error) {
+		updated, err := s.rep.SetAlbumTracks(ctx, id, request.Tracks)
+		if err != nil {
+			return models.Album{}, err
+		}
+		if err := recordTrackRevisions(ctx, s.rep, before.Tracks, updated.Tracks); err != nil {
+			return models.Album{}, err
+		}
+		return updated, nil
+	})
+}
+
+// Функция для сохранения новых ревизий песен, у которых изменился номер трека альбома
+// или которые вошли в альбом либо вышли из него
+func recordTrackRevisions(ctx context.Context, rep *repository.Repository, before, after []models.AlbumTrack) error {
+	numbers := make(map[int]int, len(before))
+	for _, track := range before {
+		numbers[track.SongID] = track.TrackNumber
+	}
+
+	changed := []int{}
+	for _, track := range after {
+		if number, ok := numbers[track.SongID]; !ok || number != track.TrackNumber {
+			changed = append(changed, track.SongID)
+		}
+		delete(numbers, track.SongID)
+	}
+	for songID := range numbers {
+		changed = append(changed, songID)
+	}
+	sort.Ints(changed)
+
+	for _, songID := range changed {
+		if _, err := recordRevision(ctx, rep, songID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Метод для изменения альбома в транзакции с записью его состояния до и после в журнал аудита;
+// change получает альбом до изменения
+func (s *AlbumsService) updateAlbum(ctx context.Context, id int, change func(ctx context.Context, before models.Album) (models.Album, error)) (models.Album, error) {
+	var updated models.Album
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetAlbumByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if updated, err = change(ctx, before); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityAlbum, models.AuditActionUpdate, id, before, updated)
+	})
+	if err != nil {
+		return models.Album{}, err
+	}
+
+	return updated, nil
+}
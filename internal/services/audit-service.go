@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Функция для записи изменения в журнал аудита от имени запроса из контекста.
+// before и after - состояние сущности до и после изменения; nil не сохраняется.
+func recordAudit(ctx context.Context, rep *repository.Repository, entity, action string, entityID int, before, after interface{}) error {
+	info := requestInfoFromContext(ctx)
+	entry := models.AuditEntry{
+		Actor:     info.Author,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		RequestID: info.RequestID,
+		ClientIP:  info.ClientIP,
+	}
+
+	var err error
+	if entry.Before, err = auditState(before); err != nil {
+		return fmt.Errorf("error encoding %s with id %d for audit: %w", entity, entityID, err)
+	}
+	if entry.After, err = auditState(after); err != nil {
+		return fmt.Errorf("error encoding %s with id %d for audit: %w", entity, entityID, err)
+	}
+
+	return rep.AddAuditEntry(ctx, entry)
+}
+
+// Функция для кодирования состояния сущности в JSON для журнала аудита; nil - отсутствие состояния
+func auditState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+// Структура AuditService, которая инкапсулирует репозиторий для чтения журнала аудита
+type AuditService struct {
+	rep *repository.Repository
+}
+
+// Функция для создания нового экземпляра AuditService с заданным репозиторием
+func NewAuditService(rep *repository.Repository) *AuditService {
+	return &AuditService{rep}
+}
+
+// Метод для получения страницы журнала аудита с фильтрацией, от новых записей к старым
+func (s *AuditService) GetAudit(ctx context.Context, filter models.AuditFilter, page, pageSize int) (models.AuditPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.AuditPage{}, err
+	}
+	if err := ValidateAuditFilter(filter); err != nil {
+		return models.AuditPage{}, err
+	}
+
+	entries, totalPages, err := s.rep.GetAuditEntries(ctx, filter, page, pageSize)
+	if err != nil {
+		return models.AuditPage{}, err
+	}
+
+	return models.AuditPage{
+		Entries:     entries,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// EncodeCursor упаковывает позицию курсора в непрозрачную строку для клиента
+func EncodeCursor(cursor models.Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку курсора, полученную от клиента
+func DecodeCursor(token string) (models.Cursor, error) {
+	var cursor models.Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err == nil && cursor.ReleaseDate != "" {
+		_, err = time.Parse(time.DateOnly, cursor.ReleaseDate)
+	}
+	if err != nil || cursor.Sort == "" {
+		var v validator
+		v.add("cursor", "is malformed")
+		return models.Cursor{}, v.err()
+	}
+
+	return cursor, nil
+}
+
+// Функция для построения курсора по граничной песне страницы
+func cursorFromSong(sort []models.SortField, song models.Songs, backward bool) models.Cursor {
+	cursor := models.Cursor{Sort: models.FormatSort(sort), Backward: backward, ID: song.ID}
+	for _, field := range sort {
+		switch field.Key {
+		case models.SortBySong:
+			cursor.Song = song.Song
+		case models.SortByGroup:
+			cursor.Group = song.Group
+		case models.SortByReleaseDate:
+			if date, err := models.ParseReleaseDate(song.ReleaseDate); err == nil {
+				cursor.ReleaseDate = date.Format(time.DateOnly)
+			}
+		case models.SortByLink:
+			cursor.Link = song.Link
+		}
+	}
+	return cursor
+}
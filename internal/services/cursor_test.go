@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor models.Cursor
+	}{
+		{name: "id only", cursor: models.Cursor{Sort: "id", ID: 42}},
+		{name: "backward", cursor: models.Cursor{Sort: "-id", Backward: true, ID: 1}},
+		{name: "all keys", cursor: models.Cursor{Sort: "group,song,-releaseDate,link", ID: 7, Song: "Ünïcode / song", Group: "Muse", ReleaseDate: "2006-07-16", Link: "https://example.com/?a=1&b=2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := EncodeCursor(tt.cursor)
+			got, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q): %v", token, err)
+			}
+			if got != tt.cursor {
+				t.Errorf("cursor = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "!!!"},
+		{name: "not JSON", token: base64.RawURLEncoding.EncodeToString([]byte("id=1"))},
+		{name: "missing sort", token: base64.RawURLEncoding.EncodeToString([]byte(`{"id":1}`))},
+		{name: "invalid date", token: base64.RawURLEncoding.EncodeToString([]byte(`{"sort":"releaseDate","id":1,"releaseDate":"16.07.2006"}`))},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte(`{"sort":"id","id":1}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCursor(tt.token)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "cursor" {
+				t.Errorf("DecodeCursor(%q) error = %v, want cursor validation error", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestCursorFromSong(t *testing.T) {
+	sort := []models.SortField{{Key: models.SortByReleaseDate, Desc: true}, {Key: models.SortBySong}}
+	song := models.Songs{ID: 3, Song: "Uprising", Group: "Muse", ReleaseDate: "16.07.2006", Link: "https://example.com"}
+
+	got := cursorFromSong(sort, song, false)
+	want := models.Cursor{Sort: models.FormatSort(sort), ID: 3, Song: "Uprising", ReleaseDate: "2006-07-16"}
+	if got != want {
+		t.Errorf("cursor = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Ktuty/internal/enrichment"
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Автор ревизий и записей журнала аудита, вносимых обогащением песен
+const enrichmentAuthor = "enrichment"
+
+// Запас времени захвата песни сверх ограничения времени попытки: за это время worker
+// успевает сохранить результат, прежде чем песню захватят снова
+const enrichmentLeaseMargin = time.Minute
+
+// Структура EnricherOptions с настройками обогащения песен
+type EnricherOptions struct {
+	Workers      int           // количество одновременно обогащаемых песен
+	MaxAttempts  int           // количество попыток, после которого песня получает статус failed
+	Timeout      time.Duration // ограничение времени одной попытки
+	Backoff      time.Duration // задержка перед второй попыткой, удваивается с каждой следующей
+	MaxBackoff   time.Duration // наибольшая задержка между попытками
+	PollInterval time.Duration // интервал проверки наступления сроков повторных попыток
+}
+
+// Структура Enricher, обогащающая песни в статусе pending сведениями внешнего API в фоне.
+// Очередью служит хранилище: песни захватываются на время попытки, поэтому обработка,
+// прерванная остановкой сервера, продолжится после запуска.
+type Enricher struct {
+	rep    *repository.Repository
+	client enrichment.Client
+	opts   EnricherOptions
+	wake   chan struct{}
+}
+
+// Функция для создания нового экземпляра Enricher с клиентом внешнего API и настройками
+func NewEnricher(rep *repository.Repository, client enrichment.Client, opts EnricherOptions) *Enricher {
+	opts.Workers = max(opts.Workers, 1)
+	opts.MaxAttempts = max(opts.MaxAttempts, 1)
+	opts.MaxBackoff = max(opts.MaxBackoff, opts.Backoff)
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	return &Enricher{rep: rep, client: client, opts: opts, wake: make(chan struct{}, 1)}
+}
+
+// Метод для пробуждения Enricher после постановки песни в очередь, не дожидаясь интервала проверки
+func (e *Enricher) Wake() {
+	if e == nil {
+		return
+	}
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Метод для обогащения песен до отмены ctx: свободным worker'ам раздаются песни, срок попытки
+// которых наступил. Возвращается после завершения начатых попыток.
+func (e *Enricher) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.PollInterval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	busy := make(chan struct{}, e.opts.Workers)
+	for {
+		if free := e.opts.Workers - len(busy); free > 0 {
+			jobs, err := e.rep.ClaimSongEnrichments(ctx, free, e.opts.Timeout+enrichmentLeaseMargin)
+			if err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Error("Ошибка при выборке песен для обогащения")
+			}
+			for _, job := range jobs {
+				busy <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer e.Wake()
+					defer func() { <-busy }()
+					e.enrich(ctx, job)
+				}()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.wake:
+		}
+	}
+}
+
+// Метод для одной попытки обогащения песни
+func (e *Enricher) enrich(ctx context.Context, job models.EnrichmentJob) {
+	log := logrus.WithFields(logrus.Fields{
+		"songID":  job.SongID,
+		"attempt": job.Attempts + 1,
+	})
+
+	attemptCtx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	details, err := e.client.FetchSong(attemptCtx, job.Group, job.Song)
+	cancel()
+	if ctx.Err() != nil {
+		// Остановка сервера: песня будет захвачена снова после истечения срока захвата
+		return
+	}
+	if err == nil {
+		err = e.apply(ctx, job, details)
+	}
+	if errors.Is(err, ErrNotFound) {
+		log.Info("Song was deleted before enrichment")
+		return
+	}
+	if err == nil {
+		log.Info("Song enriched")
+		return
+	}
+
+	// Запрос, не отправленный из-за открытого предохранителя, не расходует попытку
+	state := models.SongEnrichment{Status: models.EnrichmentFailed, Attempts: job.Attempts + 1, Error: err.Error()}
+	if errors.Is(err, enrichment.ErrCircuitOpen) {
+		state.Attempts = job.Attempts
+	}
+	if retryableEnrichment(err) && state.Attempts < e.opts.MaxAttempts {
+		next := time.Now().Add(e.backoff(max(state.Attempts, 1)))
+		state.Status, state.NextAttemptAt = models.EnrichmentPending, &next
+	}
+	log.WithError(err).WithField("status", state.Status).Error("Ошибка при обогащении песни")
+
+	if err := e.rep.SetSongEnrichment(ctx, job.SongID, state); err != nil && !errors.Is(err, ErrNotFound) {
+		log.WithError(err).Error("Ошибка при сохранении состояния обогащения")
+	}
+}
+
+// Функция для проверки, может ли повторная попытка обогащения завершиться иначе. Песня, которой нет
+// во внешнем API, отклонённый запрос, некорректные сведения и ненастроенный внешний API не меняются
+// от повтора.
+func retryableEnrichment(err error) bool {
+	return !errors.Is(err, enrichment.ErrNotFound) && !errors.Is(err, enrichment.ErrRejected) &&
+		!errors.Is(err, enrichment.ErrInvalidResponse) && !errors.Is(err, enrichment.ErrDisabled) &&
+		!errors.Is(err, ErrValidation)
+}
+
+// Метод для сохранения полученных сведений о песне: изменившиеся поля обновляются с новой ревизией
+// и записью в журнале аудита от имени enrichment, песня получает статус enriched
+func (e *Enricher) apply(ctx context.Context, job models.EnrichmentJob, details models.Songs) error {
+	if err := ValidateSongUpdate(models.Songs{Text: details.Text, ReleaseDate: details.ReleaseDate, Link: details.Link}); err != nil {
+		return err
+	}
+
+	ctx = WithRequestInfo(ctx, RequestInfo{Author: enrichmentAuthor})
+	return e.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := e.rep.GetSongByID(ctx, job.SongID)
+		if err != nil {
+			return err
+		}
+
+		// Обновляются только поля, которые внешний API вернул и которые отличаются от текущих
+		var update models.Songs
+		if details.Text != "" && details.Text != before.Text {
+			update.Text = details.Text
+		}
+		if details.ReleaseDate != "" && !sameReleaseDate(details.ReleaseDate, before.ReleaseDate) {
+			update.ReleaseDate = details.ReleaseDate
+		}
+		if details.Link != "" && details.Link != before.Link {
+			update.Link = details.Link
+		}
+		changed := update.Text != "" || update.ReleaseDate != "" || update.Link != ""
+		if changed {
+			if err := e.rep.UpdateSong(ctx, job.SongID, update); err != nil {
+				return err
+			}
+		}
+
+		enriched := models.SongEnrichment{Status: models.EnrichmentEnriched, Attempts: job.Attempts + 1}
+		if err := e.rep.SetSongEnrichment(ctx, job.SongID, enriched); err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+
+		if _, err := recordRevision(ctx, e.rep, job.SongID, nil); err != nil {
+			return err
+		}
+		after, err := e.rep.GetSongByID(ctx, job.SongID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, e.rep, models.AuditEntitySong, models.AuditActionUpdate, job.SongID, before, after)
+	})
+}
+
+// Метод для вычисления задержки перед попыткой, следующей за attempts неудачными
+func (e *Enricher) backoff(attempts int) time.Duration {
+	delay := e.opts.Backoff
+	for i := 1; i < attempts && delay < e.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, e.opts.MaxBackoff)
+}
+
+// Функция для сравнения дат выпуска в любых допустимых форматах
+func sameReleaseDate(a, b string) bool {
+	dateA, errA := models.ParseReleaseDate(a)
+	dateB, errB := models.ParseReleaseDate(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return dateA.Equal(dateB)
+}
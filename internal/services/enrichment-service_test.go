@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ktuty/internal/enrichment"
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Структура fakeClient, отвечающая сведениями о песнях по названию вместо внешнего API
+type fakeClient struct {
+	mu      sync.Mutex
+	details map[string]models.Songs
+	errs    map[string]error
+	calls   map[string]int
+}
+
+// Метод для получения заранее заданных сведений о песне или ошибки
+func (c *fakeClient) FetchSong(ctx context.Context, group, song string) (models.Songs, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[song]++
+	if err, ok := c.errs[song]; ok {
+		return models.Songs{}, err
+	}
+	return c.details[song], nil
+}
+
+// Функция для ожидания состояния обогащения песни, отличного от pending
+func waitEnrichment(t *testing.T, repo *repository.Repository, songID int) models.Songs {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		song, err := repo.GetSongByID(context.Background(), songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.Enrichment.Status != models.EnrichmentPending {
+			return song
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("song %d is still pending", songID)
+	return models.Songs{}
+}
+
+func TestEnricher(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	client := &fakeClient{
+		details: map[string]models.Songs{"Uprising": {Text: "Paranoia is in bloom", ReleaseDate: "16.07.2006", Link: "https://example.com"}},
+		errs: map[string]error{
+			"Unknown": enrichment.ErrNotFound,
+			"Flaky":   fmt.Errorf("%w: status 503", enrichment.ErrUnavailable),
+			"Broken":  &enrichment.SchemaError{Fields: []models.FieldError{{Field: "link", Message: "is required"}}},
+		},
+		calls: make(map[string]int),
+	}
+	enricher := NewEnricher(repo, client, EnricherOptions{Workers: 2, MaxAttempts: 3, Timeout: time.Second, Backoff: time.Millisecond, PollInterval: time.Millisecond})
+	service := NewService(repo, enricher)
+
+	ids := make(map[string]int)
+	for _, title := range []string{"Uprising", "Unknown", "Flaky", "Broken"} {
+		song, _, err := service.Create(context.Background(), models.Songs{Group: "Muse", Song: title}, models.OnConflictError)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = song.ID
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		enricher.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	song := waitEnrichment(t, repo, ids["Uprising"])
+	if song.Enrichment.Status != models.EnrichmentEnriched || song.Text != "Paranoia is in bloom" || song.ReleaseDate != "16.07.2006" {
+		t.Errorf("enriched song = %+v, enrichment %+v", song, song.Enrichment)
+	}
+	revisions, _, err := repo.GetSongRevisions(context.Background(), ids["Uprising"], 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Author != enrichmentAuthor {
+		t.Errorf("revisions = %+v, want a second revision by %s", revisions, enrichmentAuthor)
+	}
+
+	// Повтор не поможет: песня сразу получает статус failed
+	for _, title := range []string{"Unknown", "Broken"} {
+		if song := waitEnrichment(t, repo, ids[title]); song.Enrichment.Status != models.EnrichmentFailed || song.Enrichment.Attempts != 1 {
+			t.Errorf("%s enrichment = %+v, want failed after 1 attempt", title, song.Enrichment)
+		}
+	}
+	// Недоступный внешний API повторяется до исчерпания попыток
+	if song := waitEnrichment(t, repo, ids["Flaky"]); song.Enrichment.Status != models.EnrichmentFailed || song.Enrichment.Attempts != 3 || song.Enrichment.Error == "" {
+		t.Errorf("Flaky enrichment = %+v, want failed after 3 attempts", song.Enrichment)
+	}
+	client.mu.Lock()
+	if client.calls["Flaky"] != 3 || client.calls["Unknown"] != 1 {
+		t.Errorf("calls = %v", client.calls)
+	}
+	client.mu.Unlock()
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Ktuty/internal/enrichment"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Ошибки сервисного слоя, по которым обработчики выбирают HTTP-статус ответа
+var (
+	// ErrNotFound - запрошенная сущность не существует
+	ErrNotFound = repository.ErrNotFound
+	// ErrConflict - операция конфликтует с текущим состоянием данных
+	ErrConflict = repository.ErrConflict
+	// ErrVersionMismatch - версия сущности не совпадает с ожидаемой клиентом
+	ErrVersionMismatch = repository.ErrVersionMismatch
+	// ErrValidation - входные данные не прошли проверку
+	ErrValidation = errors.New("validation failed")
+	// ErrUpstreamFailure - внешний API недоступен, отклонил запрос или вернул некорректный ответ
+	ErrUpstreamFailure = enrichment.ErrUpstream
+	// ErrUpstreamPaused - запросы к внешнему API приостановлены предохранителем после неудач подряд
+	ErrUpstreamPaused = enrichment.ErrCircuitOpen
+)
+
+// DuplicateSongError - ErrConflict при создании или изменении песни, название которой совпадает
+// с другой песней той же группы; содержит ID существующей песни
+type DuplicateSongError = repository.DuplicateSongError
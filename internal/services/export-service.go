@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Метод для потоковой выгрузки всех песен, подходящих под фильтр, в порядке сортировки.
+// Песни передаются в fn по одной по мере чтения; ошибка fn прерывает выгрузку.
+func (s *SongsService) Export(ctx context.Context, filter models.SongFilter, sort []models.SortField, fn func(song models.Songs) error) error {
+	if err := ValidateSongFilter(filter); err != nil {
+		return err
+	}
+	return s.rep.ExportSongs(ctx, filter, sort, fn)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Структура GroupsService, которая инкапсулирует репозиторий для работы с группами
+type GroupsService struct {
+	rep *repository.Repository
+}
+
+// Функция для создания нового экземпляра GroupsService с заданным репозиторием
+func NewGroupsService(rep *repository.Repository) *GroupsService {
+	return &GroupsService{rep}
+}
+
+// Метод для получения страницы групп с фильтрацией по подстроке имени
+func (s *GroupsService) GetAllGroups(ctx context.Context, name string, page, pageSize int) (models.GroupsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.GroupsPage{}, err
+	}
+
+	groups, totalPages, err := s.rep.GetAllGroups(ctx, name, page, pageSize)
+	if err != nil {
+		return models.GroupsPage{}, err
+	}
+
+	return models.GroupsPage{
+		Groups:      groups,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для получения группы по ID
+func (s *GroupsService) GetGroupByID(ctx context.Context, id int) (models.Group, error) {
+	return s.rep.GetGroupByID(ctx, id)
+}
+
+// Метод для создания новой группы с проверкой имени и записью в журнал аудита
+func (s *GroupsService) CreateGroup(ctx context.Context, group models.Group) (models.Group, error) {
+	if err := ValidateGroup(group); err != nil {
+		return models.Group{}, err
+	}
+
+	var created models.Group
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if created, err = s.rep.CreateGroup(ctx, group.Name); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityGroup, models.AuditActionCreate, created.ID, nil, created)
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return created, nil
+}
+
+// Метод для переименования группы с проверкой имени
+func (s *GroupsService) RenameGroup(ctx context.Context, id int, group models.Group) (models.Group, error) {
+	if err := ValidateGroup(group); err != nil {
+		return models.Group{}, err
+	}
+	return s.updateGroup(ctx, id, func(ctx context.Context) (models.Group, error) {
+		return s.rep.RenameGroup(ctx, id, group.Name)
+	})
+}
+
+// Метод для удаления группы по ID с записью в журнал аудита
+func (s *GroupsService) DeleteGroup(ctx context.Context, id int, cascade bool) error {
+	return s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetGroupByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.rep.DeleteGroup(ctx, id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityGroup, models.AuditActionDelete, id, before, nil)
+	})
+}
+
+// Метод для добавления псевдонима группы с проверкой имени
+func (s *GroupsService) AddGroupAlias(ctx context.Context, groupID int, alias models.GroupAlias) (models.Group, error) {
+	if err := ValidateGroupAlias(alias); err != nil {
+		return models.Group{}, err
+	}
+	return s.updateGroup(ctx, groupID, func(ctx context.Context) (models.Group, error) {
+		return s.rep.AddGroupAlias(ctx, groupID, alias.Alias)
+	})
+}
+
+// Метод для удаления псевдонима группы по ID
+func (s *GroupsService) DeleteGroupAlias(ctx context.Context, groupID, aliasID int) error {
+	_, err := s.updateGroup(ctx, groupID, func(ctx context.Context) (models.Group, error) {
+		if err := s.rep.DeleteGroupAlias(ctx, groupID, aliasID); err != nil {
+			return models.Group{}, err
+		}
+		return s.rep.GetGroupByID(ctx, groupID)
+	})
+	return err
+}
+
+// Метод для объединения исходных групп с целевой. В журнал аудита записывается объединение
+// для целевой группы и удаление каждой исходной.
+func (s *GroupsService) MergeGroups(ctx context.Context, targetID int, request models.GroupMergeRequest) (models.GroupMerge, error) {
+	if err := ValidateGroupMerge(targetID, request); err != nil {
+		return models.GroupMerge{}, err
+	}
+
+	var merge models.GroupMerge
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetGroupByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		sources := make([]models.Group, 0, len(request.Sources))
+		for _, id := range request.Sources {
+			source, err := s.rep.GetGroupByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, source)
+		}
+
+		if merge, err = s.rep.MergeGroups(ctx, targetID, request.Sources); err != nil {
+			return err
+		}
+		for _, source := range sources {
+			if err := recordAudit(ctx, s.rep, models.AuditEntityGroup, models.AuditActionDelete, source.ID, source, nil); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityGroup, models.AuditActionMerge, targetID, before, merge.Group)
+	})
+	if err != nil {
+		return models.GroupMerge{}, err
+	}
+
+	return merge, nil
+}
+
+// Метод для изменения группы в транзакции с записью её состояния до и после в журнал аудита
+func (s *GroupsService) updateGroup(ctx context.Context, id int, change func(ctx context.Context) (models.Group, error)) (models.Group, error) {
+	var updated models.Group
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetGroupByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if updated, err = change(ctx); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntityGroup, models.AuditActionUpdate, id, before, updated)
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	return updated, nil
+}
+
+// Метод для получения страницы песен группы с сортировкой
+func (s *GroupsService) GetGroupSongs(ctx context.Context, id int, sort []models.SortField, page, pageSize int) (models.SongsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.SongsPage{}, err
+	}
+
+	// Несуществующая группа - 404, а не пустой список
+	if _, err := s.rep.GetGroupByID(ctx, id); err != nil {
+		return models.SongsPage{}, err
+	}
+
+	songs, totalPages, err := s.rep.GetAllSongs(ctx, models.SongFilter{GroupID: id}, sort, page, pageSize)
+	if err != nil {
+		return models.SongsPage{}, err
+	}
+
+	return models.SongsPage{
+		Songs:       songs,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Sort:        models.FormatSort(sort),
+	}, nil
+}
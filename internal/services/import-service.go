@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Количество песен, вставляемых в базу одним пакетом при импорте
+const importBatchSize = 1000
+
+// Наибольшая длина строки NDJSON: текст песни со всеми куплетами в одной строке
+const maxImportLineSize = 4 << 20
+
+// Ошибка, которой откатывается транзакция пробного импорта
+var errDryRun = errors.New("dry run")
+
+// Интерфейс SongReader, построчно читающий песни импортируемого каталога
+type SongReader interface {
+	// Метод для чтения следующей песни с номером её строки. В конце каталога возвращает io.EOF,
+	// для некорректной строки - *RowError; остальные ошибки прерывают импорт.
+	Read() (int, models.Songs, error)
+}
+
+// Структура RowError - ошибка разбора одной строки каталога; импорт продолжается со следующей строки
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Метод для получения текста ошибки
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Метод для получения исходной ошибки
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Структура csvSongReader, читающая песни из CSV с заголовком из имён полей models.Songs
+type csvSongReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// Столбцы CSV, соответствующие полям models.Songs. Столбец id есть в CSV-выгрузке каталога
+// и при импорте пропускается: новые песни получают свои ID.
+var csvSongColumns = []string{"id", "group", "song", "text", "releaseDate", "link"}
+
+// Функция для создания SongReader для CSV: первая строка - заголовок, обязательны столбцы group и song
+func NewCSVSongReader(r io.Reader) (SongReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: CSV header is missing", ErrValidation)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: invalid CSV header: %v", ErrValidation, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: CSV column %q is duplicated", ErrValidation, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"group", "song"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: CSV column %q is required", ErrValidation, name)
+		}
+	}
+	for name := range columns {
+		if !slices.Contains(csvSongColumns, name) {
+			return nil, fmt.Errorf("%w: unknown CSV column %q: must be one of %s", ErrValidation, name, strings.Join(csvSongColumns, ", "))
+		}
+	}
+
+	return &csvSongReader{reader: reader, columns: columns}, nil
+}
+
+// Метод для чтения следующей песни из CSV
+func (c *csvSongReader) Read() (int, models.Songs, error) {
+	record, err := c.reader.Read()
+	var parseErr *csv.ParseError
+	switch {
+	case errors.Is(err, io.EOF):
+		return 0, models.Songs{}, io.EOF
+	case errors.As(err, &parseErr):
+		return parseErr.StartLine, models.Songs{}, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	case err != nil:
+		return 0, models.Songs{}, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	return line, models.Songs{
+		Group:       field("group"),
+		Song:        field("song"),
+		Text:        field("text"),
+		ReleaseDate: field("releaseDate"),
+		Link:        field("link"),
+	}, nil
+}
+
+// Структура ndjsonSongReader, читающая песни из JSON Lines: один объект models.Songs в строке
+type ndjsonSongReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// Функция для создания SongReader для JSON Lines; пустые строки пропускаются
+func NewNDJSONSongReader(r io.Reader) SongReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	return &ndjsonSongReader{scanner: scanner}
+}
+
+// Метод для чтения следующей песни из JSON Lines
+func (n *ndjsonSongReader) Read() (int, models.Songs, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := strings.TrimSpace(n.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var song models.Songs
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&song); err != nil {
+			return n.line, models.Songs{}, &RowError{Line: n.line, Err: err}
+		}
+		return n.line, song, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return 0, models.Songs{}, fmt.Errorf("%w: line %d is longer than %d bytes", ErrValidation, n.line+1, maxImportLineSize)
+		}
+		return 0, models.Songs{}, fmt.Errorf("error reading JSON Lines: %w", err)
+	}
+	return 0, models.Songs{}, io.EOF
+}
+
+// Метод для импорта каталога песен без обращения к внешнему API: строки проверяются как при создании
+// песни и вставляются пакетами по importBatchSize, каждый пакет в своей транзакции, поэтому размер
+// каталога не ограничен. Если импорт прерывается ошибкой, уже вставленные пакеты остаются сохранены,
+// а вместе с ошибкой возвращается отчёт о прочитанных строках: несохранённые проверенные строки
+// отмечаются как not_processed.
+// Песни с названием, которое уже есть в группе, пропускаются, некорректные строки отмечаются
+// как failed. Каждая вставленная песня получает первую ревизию и запись в журнале аудита.
+// При dryRun весь каталог проверяется в одной транзакции, которая откатывается, а отчёт
+// показывает результат, который дал бы импорт.
+func (s *SongsService) Import(ctx context.Context, reader SongReader, dryRun bool) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: dryRun, Rows: []models.ImportRow{}}
+
+	if dryRun {
+		err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.importRows(ctx, reader, &report, s.importBatch); err != nil {
+				return err
+			}
+			return errDryRun
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			return models.ImportReport{}, err
+		}
+		return report, nil
+	}
+
+	err := s.importRows(ctx, reader, &report, func(ctx context.Context, batch []models.Songs, positions []int, report *models.ImportReport) error {
+		inserted, skipped := report.Inserted, report.Skipped
+		err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+			return s.importBatch(ctx, batch, positions, report)
+		})
+		if err != nil {
+			// Строки откатившегося пакета возвращаются к состоянию до вставки
+			report.Inserted, report.Skipped = inserted, skipped
+			for _, i := range positions {
+				report.Rows[i] = models.ImportRow{Line: report.Rows[i].Line}
+			}
+		}
+		return err
+	})
+	if err != nil {
+		for i, row := range report.Rows {
+			if row.Status == "" {
+				report.Rows[i].Status = models.ImportNotProcessed
+				report.NotProcessed++
+			}
+		}
+		return report, err
+	}
+
+	return report, nil
+}
+
+// Метод для чтения и проверки строк каталога: некорректные строки записываются в отчёт как failed,
+// проверенные песни передаются в flush пакетами по importBatchSize
+func (s *SongsService) importRows(ctx context.Context, reader SongReader, report *models.ImportReport,
+	flush func(ctx context.Context, batch []models.Songs, positions []int, report *models.ImportReport) error) error {
+	var batch []models.Songs
+	var positions []int
+	flushBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := flush(ctx, batch, positions, report); err != nil {
+			return err
+		}
+		batch, positions = batch[:0], positions[:0]
+		return nil
+	}
+
+	for {
+		line, song, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			report.Rows = append(report.Rows, models.ImportRow{Line: rowErr.Line, Status: models.ImportFailed, Error: rowErr.Err.Error()})
+			report.Failed++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		song.Artists = withoutGroupCredit(song)
+		if err := ValidateSong(song); err != nil {
+			row := models.ImportRow{Line: line, Status: models.ImportFailed, Error: err.Error()}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				row.Error, row.Fields = ErrValidation.Error(), validationErr.Fields
+			}
+			report.Rows = append(report.Rows, row)
+			report.Failed++
+			continue
+		}
+
+		report.Rows = append(report.Rows, models.ImportRow{Line: line})
+		batch = append(batch, song)
+		positions = append(positions, len(report.Rows)-1)
+		if len(batch) == importBatchSize {
+			if err := flushBatch(); err != nil {
+				return err
+			}
+		}
+	}
+	return flushBatch()
+}
+
+// Функция для получения соавторов песни без основной группы: в выгрузке каталога она указана
+// и полем group, и соавтором с ролью primary. Основной соавтор с другой группой остаётся
+// и отклоняется проверкой.
+func withoutGroupCredit(song models.Songs) []models.SongArtist {
+	return slices.DeleteFunc(slices.Clone(song.Artists), func(artist models.SongArtist) bool {
+		return artist.Role == models.ArtistRolePrimary && models.GroupKey(artist.Group) == models.GroupKey(song.Group)
+	})
+}
+
+// Метод для вставки пакета проверенных песен с сохранением первых ревизий и записей журнала аудита.
+// positions - индексы строк пакета в отчёте.
+func (s *SongsService) importBatch(ctx context.Context, batch []models.Songs, positions []int, report *models.ImportReport) error {
+	rows, err := s.rep.ImportSongs(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	info := requestInfoFromContext(ctx)
+	var inserted []int
+	var entries []models.AuditEntry
+	for i, row := range rows {
+		row.Line = report.Rows[positions[i]].Line
+		report.Rows[positions[i]] = row
+		if row.Status == models.ImportSkipped {
+			report.Skipped++
+			continue
+		}
+		report.Inserted++
+
+		song := batch[i]
+		song.ID, song.Group = row.ID, models.NormalizeGroupName(song.Group)
+		after, err := auditState(song)
+		if err != nil {
+			return fmt.Errorf("error encoding song with id %d for audit: %w", row.ID, err)
+		}
+		inserted = append(inserted, row.ID)
+		entries = append(entries, models.AuditEntry{
+			Actor:     info.Author,
+			Action:    models.AuditActionCreate,
+			Entity:    models.AuditEntitySong,
+			EntityID:  row.ID,
+			After:     after,
+			RequestID: info.RequestID,
+			ClientIP:  info.ClientIP,
+		})
+	}
+	if len(inserted) == 0 {
+		return nil
+	}
+
+	if err := s.rep.AddInitialSongRevisions(ctx, inserted, info.Author); err != nil {
+		return err
+	}
+	return s.rep.AddAuditEntries(ctx, entries)
+}
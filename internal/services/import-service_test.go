@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Структура readResult - одно прочитанное значение SongReader
+type readResult struct {
+	line    int
+	song    models.Songs
+	rowErr  bool
+	lastErr error // ошибка, прервавшая чтение; io.EOF в конце каталога
+}
+
+// Функция для чтения всех песен каталога до конца или до ошибки, прерывающей импорт
+func readAll(reader SongReader) []readResult {
+	var results []readResult
+	for {
+		line, song, err := reader.Read()
+		var rowErr *RowError
+		switch {
+		case errors.As(err, &rowErr):
+			results = append(results, readResult{line: line, rowErr: true})
+		case err != nil:
+			return append(results, readResult{lastErr: err})
+		default:
+			results = append(results, readResult{line: line, song: song})
+		}
+	}
+}
+
+func TestNewCSVSongReaderHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "required columns", body: "group,song\n"},
+		{name: "export columns", body: "id,group,song,text,releaseDate,link\n"},
+		{name: "byte order mark and spaces", body: "\ufeffgroup, song \n"},
+		{name: "empty body", body: "", wantErr: true},
+		{name: "missing song", body: "group,text\n", wantErr: true},
+		{name: "duplicated column", body: "group,song,group\n", wantErr: true},
+		{name: "unknown column", body: "group,song,album\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCSVSongReader(strings.NewReader(tt.body))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want error: %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Errorf("error %v does not wrap ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestCSVSongReader(t *testing.T) {
+	body := "song,group,releaseDate\n" +
+		"Uprising,Muse,16.07.2006\n" +
+		"\"Multi\nline\",Muse,\n" +
+		"Broken,\"Muse\n" +
+		"\n"
+	reader, err := NewCSVSongReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := readAll(reader)
+	want := []readResult{
+		{line: 2, song: models.Songs{Group: "Muse", Song: "Uprising", ReleaseDate: "16.07.2006"}},
+		{line: 3, song: models.Songs{Group: "Muse", Song: "Multi\nline"}},
+		{line: 5, rowErr: true},
+	}
+	if len(got) < len(want) {
+		t.Fatalf("read %d results, want at least %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].line != w.line || got[i].rowErr != w.rowErr || got[i].song.Group != w.song.Group ||
+			got[i].song.Song != w.song.Song || got[i].song.ReleaseDate != w.song.ReleaseDate {
+			t.Errorf("result %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestNDJSONSongReader(t *testing.T) {
+	body := `{"group":"Muse","song":"Uprising"}` + "\n" +
+		"\n" +
+		`{"group":"Muse","song":"Starlight","album":"x"}` + "\n" +
+		`not json` + "\n" +
+		`  {"group":"Muse","song":"Knights"}  `
+	got := readAll(NewNDJSONSongReader(strings.NewReader(body)))
+	want := []readResult{
+		{line: 1, song: models.Songs{Group: "Muse", Song: "Uprising"}},
+		{line: 3, rowErr: true},
+		{line: 4, rowErr: true},
+		{line: 5, song: models.Songs{Group: "Muse", Song: "Knights"}},
+		{lastErr: io.EOF},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d results, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].line != w.line || got[i].rowErr != w.rowErr || got[i].song.Song != w.song.Song ||
+			!errors.Is(got[i].lastErr, w.lastErr) {
+			t.Errorf("result %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestNDJSONSongReaderLongLine(t *testing.T) {
+	body := `{"group":"Muse","song":"Uprising","text":"` + strings.Repeat("a", maxImportLineSize) + `"}`
+	got := readAll(NewNDJSONSongReader(strings.NewReader(body)))
+	if len(got) != 1 || !errors.Is(got[0].lastErr, ErrValidation) {
+		t.Errorf("results = %+v, want a single validation error", got)
+	}
+}
+
+// Структура failingRevisions - хранилище ревизий, которое отказывает после calls успешных
+// сохранений первых ревизий
+type failingRevisions struct {
+	repository.Revisions
+	calls int
+}
+
+// Метод для сохранения первых ревизий, отказывающий после calls успешных вызовов
+func (f *failingRevisions) AddInitialSongRevisions(ctx context.Context, songIDs []int, author string) error {
+	if f.calls == 0 {
+		return errors.New("connection reset")
+	}
+	f.calls--
+	return f.Revisions.AddInitialSongRevisions(ctx, songIDs, author)
+}
+
+// Функция для построения каталога JSON Lines из count песен одной группы
+func ndjsonCatalog(count int) string {
+	var catalog strings.Builder
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&catalog, `{"group":"Muse","song":"Song %d"}`+"\n", i)
+	}
+	return catalog.String()
+}
+
+func TestImportStoppedReport(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	repo.Revisions = &failingRevisions{Revisions: repo.Revisions, calls: 1}
+	service := NewSongsService(repo, nil)
+
+	// Второй пакет откатывается, первый остаётся сохранён
+	catalog := ndjsonCatalog(importBatchSize+2) + "{\"group\"\n"
+	report, err := service.Import(ctx, NewNDJSONSongReader(strings.NewReader(catalog)), false)
+	if err == nil {
+		t.Fatal("Import error = nil, want the batch error")
+	}
+	if report.Inserted != importBatchSize || report.Skipped != 0 || report.Failed != 1 || report.NotProcessed != 2 {
+		t.Errorf("report = %d inserted, %d skipped, %d failed, %d not processed, want %d, 0, 1, 2",
+			report.Inserted, report.Skipped, report.Failed, report.NotProcessed, importBatchSize)
+	}
+	if len(report.Rows) != importBatchSize+3 {
+		t.Fatalf("report rows = %d, want %d", len(report.Rows), importBatchSize+3)
+	}
+	if row := report.Rows[importBatchSize-1]; row.Status != models.ImportInserted || row.ID == 0 {
+		t.Errorf("last row of the saved batch = %+v", row)
+	}
+	if row := report.Rows[importBatchSize]; row.Line != importBatchSize+1 || row.Status != models.ImportNotProcessed || row.ID != 0 {
+		t.Errorf("first row of the failed batch = %+v", row)
+	}
+	if row := report.Rows[importBatchSize+2]; row.Status != models.ImportFailed {
+		t.Errorf("invalid row = %+v", row)
+	}
+	if _, totalPages, err := repo.GetAllSongs(ctx, models.SongFilter{}, nil, 1, 1); err != nil || totalPages != importBatchSize {
+		t.Errorf("saved songs = %d, %v, want %d", totalPages, err, importBatchSize)
+	}
+
+	// Ошибка чтения каталога возвращает отчёт о прочитанных строках
+	reader := NewNDJSONSongReader(io.MultiReader(strings.NewReader(`{"group":"Queen","song":"Innuendo"}`+"\n"), iotest.ErrReader(io.ErrUnexpectedEOF)))
+	report, err = NewSongsService(repository.NewMemoryRepository(), nil).Import(ctx, reader, false)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Import error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if report.Inserted != 0 || report.NotProcessed != 1 || len(report.Rows) != 1 || report.Rows[0].Status != models.ImportNotProcessed {
+		t.Errorf("report after a read error = %+v", report)
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Функция для применения JSON Merge Patch (RFC 7396) к документу: null удаляет поле,
+// объекты объединяются рекурсивно, остальные значения заменяются целиком
+func applyMergePatch(document, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, fmt.Errorf("%w: invalid merge patch: %v", ErrValidation, err)
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+// Функция для рекурсивного слияния значения с изменениями merge patch
+func mergeValue(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{}, len(changes))
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergeValue(result[key], value)
+	}
+	return result
+}
+
+// Ошибка операции test, значение которой не совпало с документом
+var errPatchTestFailed = errors.New("test failed")
+
+// Структура jsonPatchOperation, описывающая одну операцию JSON Patch (RFC 6902)
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Функция для применения JSON Patch (RFC 6902) к документу; операции выполняются по порядку,
+// ошибка любой из них отменяет весь патч. Неудачная операция test возвращает ErrConflict.
+func applyJSONPatch(document, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON patch: %v", ErrValidation, err)
+	}
+
+	var v validator
+	for i, operation := range operations {
+		field := fmt.Sprintf("patch[%d]", i)
+		var err error
+		target, err = operation.apply(target)
+		if err != nil {
+			if errors.Is(err, errPatchTestFailed) {
+				return nil, fmt.Errorf("%s: %v: %w", field, err, ErrConflict)
+			}
+			v.add(field, err.Error())
+			return nil, v.err()
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+// Метод для применения операции JSON Patch к документу
+func (o jsonPatchOperation) apply(document interface{}) (interface{}, error) {
+	if o.Path == nil {
+		return nil, fmt.Errorf("path is required")
+	}
+	path, err := parsePointer(*o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add", "replace", "test":
+		if len(o.Value) == 0 {
+			return nil, fmt.Errorf("value is required for %s", o.Op)
+		}
+		var value interface{}
+		if err := json.Unmarshal(o.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %v", err)
+		}
+		switch o.Op {
+		case "add":
+			return pointerAdd(document, path, value)
+		case "replace":
+			if _, err := pointerGet(document, path); err != nil {
+				return nil, err
+			}
+			if len(path) == 0 {
+				return value, nil
+			}
+			removed, err := pointerRemove(document, path)
+			if err != nil {
+				return nil, err
+			}
+			return pointerAdd(removed, path, value)
+		default:
+			current, err := pointerGet(document, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: value at %q differs", errPatchTestFailed, *o.Path)
+			}
+			return document, nil
+		}
+	case "remove":
+		return pointerRemove(document, path)
+	case "move", "copy":
+		if o.From == nil {
+			return nil, fmt.Errorf("from is required for %s", o.Op)
+		}
+		from, err := parsePointer(*o.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := pointerGet(document, from)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == "move" {
+			if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+				return nil, fmt.Errorf("cannot move %q into its own child", *o.From)
+			}
+			if document, err = pointerRemove(document, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return pointerAdd(document, path, value)
+	default:
+		return nil, fmt.Errorf("unknown op %q: must be one of add, remove, replace, move, copy, test", o.Op)
+	}
+}
+
+// Функция для разбора JSON Pointer (RFC 6901) на токены пути
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Функция для получения значения по пути
+func pointerGet(document interface{}, path []string) (interface{}, error) {
+	current := document
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path /%s does not exist", strings.Join(path, "/"))
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path /%s does not exist", strings.Join(path, "/"))
+		}
+	}
+	return current, nil
+}
+
+// Функция для добавления значения по пути: поле объекта создаётся или заменяется,
+// в массив значение вставляется перед индексом или в конец для "-"
+func pointerAdd(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+		return document, nil
+	case []interface{}:
+		index := len(node)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		updated := append(node[:index:index], append([]interface{}{value}, node[index:]...)...)
+		return pointerSet(document, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("path /%s does not exist", strings.Join(path, "/"))
+	}
+}
+
+// Функция для удаления значения по пути
+func pointerRemove(document interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[token]; !ok {
+			return nil, fmt.Errorf("path /%s does not exist", strings.Join(path, "/"))
+		}
+		delete(node, token)
+		return document, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated := append(node[:index:index], node[index+1:]...)
+		return pointerSet(document, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("path /%s does not exist", strings.Join(path, "/"))
+	}
+}
+
+// Функция для замены значения по существующему пути; нужна для массивов, длина которых меняется
+func pointerSet(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+	case []interface{}:
+		index, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	}
+	return document, nil
+}
+
+// Функция для разбора индекса массива в диапазоне от 0 до max без ведущих нулей
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d is out of range", index)
+	}
+	return index, nil
+}
+
+// Функция для глубокого копирования значения, разобранного из JSON
+func deepCopy(value interface{}) interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(node))
+		for key, item := range node {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(node))
+		for i, item := range node {
+			result[i] = deepCopy(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// Функция для разбора документа песни после применения патча; неизвестные поля и неверные типы
+// возвращаются как ошибки валидации
+func decodeSnapshot(document []byte) (models.SongSnapshot, error) {
+	var snapshot models.SongSnapshot
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&snapshot); err != nil {
+		var v validator
+		v.add("body", fmt.Sprintf("patched song is invalid: %v", err))
+		return models.SongSnapshot{}, v.err()
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Функция для сравнения JSON-документов без учёта порядка полей
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid result %q: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected document %q: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+		wantErr  error
+	}{
+		{name: "replace field", document: `{"song":"a","text":"b"}`, patch: `{"text":"c"}`, want: `{"song":"a","text":"c"}`},
+		{name: "null removes field", document: `{"song":"a","text":"b"}`, patch: `{"text":null}`, want: `{"song":"a"}`},
+		{name: "nested objects merge", document: `{"a":{"b":1,"c":2}}`, patch: `{"a":{"c":3,"d":4}}`, want: `{"a":{"b":1,"c":3,"d":4}}`},
+		{name: "arrays are replaced", document: `{"a":[1,2]}`, patch: `{"a":[3]}`, want: `{"a":[3]}`},
+		{name: "non-object patch replaces document", document: `{"a":1}`, patch: `[1]`, want: `[1]`},
+		{name: "invalid patch", document: `{}`, patch: `{`, wantErr: ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyMergePatch([]byte(tt.document), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	const document = `{"song":"a","artists":[{"id":1},{"id":2}],"a/b":1,"m~n":2}`
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/song","value":"b"}]`,
+			want:  `{"song":"b","artists":[{"id":1},{"id":2}],"a/b":1,"m~n":2}`,
+		},
+		{
+			name:  "add to array end and remove",
+			patch: `[{"op":"add","path":"/artists/-","value":{"id":3}},{"op":"remove","path":"/artists/0"}]`,
+			want:  `{"song":"a","artists":[{"id":2},{"id":3}],"a/b":1,"m~n":2}`,
+		},
+		{
+			name:  "insert before index",
+			patch: `[{"op":"add","path":"/artists/1","value":{"id":5}}]`,
+			want:  `{"song":"a","artists":[{"id":1},{"id":5},{"id":2}],"a/b":1,"m~n":2}`,
+		},
+		{
+			name:  "escaped pointer tokens",
+			patch: `[{"op":"remove","path":"/a~1b"},{"op":"remove","path":"/m~0n"}]`,
+			want:  `{"song":"a","artists":[{"id":1},{"id":2}]}`,
+		},
+		{
+			name:  "move and copy",
+			patch: `[{"op":"copy","from":"/song","path":"/text"},{"op":"move","from":"/artists/1","path":"/artists/0"}]`,
+			want:  `{"song":"a","text":"a","artists":[{"id":2},{"id":1}],"a/b":1,"m~n":2}`,
+		},
+		{
+			name:  "passing test",
+			patch: `[{"op":"test","path":"/artists/0/id","value":1}]`,
+			want:  document,
+		},
+		{name: "failing test is a conflict", patch: `[{"op":"test","path":"/song","value":"x"}]`, wantErr: ErrConflict},
+		{name: "missing path", patch: `[{"op":"remove","path":"/text"}]`, wantErr: ErrValidation},
+		{name: "index out of range", patch: `[{"op":"replace","path":"/artists/2","value":{}}]`, wantErr: ErrValidation},
+		{name: "leading zero index", patch: `[{"op":"remove","path":"/artists/01"}]`, wantErr: ErrValidation},
+		{name: "move into own child", patch: `[{"op":"move","from":"/artists","path":"/artists/0"}]`, wantErr: ErrValidation},
+		{name: "unknown op", patch: `[{"op":"rename","path":"/song"}]`, wantErr: ErrValidation},
+		{name: "not an array", patch: `{"op":"remove","path":"/song"}`, wantErr: ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyJSONPatch([]byte(document), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchIsAtomic(t *testing.T) {
+	// Ошибка второй операции отменяет и первую: исходный документ не меняется
+	document := []byte(`{"song":"a"}`)
+	if _, err := applyJSONPatch(document, []byte(`[{"op":"replace","path":"/song","value":"b"},{"op":"remove","path":"/text"}]`)); err == nil {
+		t.Fatal("expected an error")
+	}
+	assertJSONEqual(t, document, `{"song":"a"}`)
+}
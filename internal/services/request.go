@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"strings"
+)
+
+// Автор изменений, пришедших без указания автора
+const anonymousAuthor = "anonymous"
+
+// Структура RequestInfo с данными запроса, которые сохраняются в ревизиях и журнале аудита
+type RequestInfo struct {
+	Author    string // автор изменений из заголовка X-Author
+	RequestID string // идентификатор запроса из заголовка X-Request-ID или сгенерированный
+	ClientIP  string // адрес клиента
+}
+
+// Ключ контекста, под которым хранятся данные запроса
+type requestInfoKey struct{}
+
+// Функция для сохранения в контексте данных запроса, от имени которого вносятся изменения
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	info.Author = strings.TrimSpace(info.Author)
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// Функция для получения данных запроса из контекста; автор anonymous, если он не указан
+func requestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	if info.Author == "" {
+		info.Author = anonymousAuthor
+	}
+	return info
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Функция для сохранения текущего состояния песни как новой ревизии от автора из контекста
+func recordRevision(ctx context.Context, rep *repository.Repository, songID int, revertedFrom *int) (models.Revision, error) {
+	song, err := rep.GetSongByID(ctx, songID)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	snapshot := models.NewSongSnapshot(song)
+	return rep.AddSongRevision(ctx, models.Revision{
+		SongID:       songID,
+		Author:       requestInfoFromContext(ctx).Author,
+		RevertedFrom: revertedFrom,
+		Snapshot:     &snapshot,
+	})
+}
+
+// Структура RevisionsService, которая инкапсулирует репозиторий для работы с историей изменений песен
+type RevisionsService struct {
+	rep *repository.Repository
+}
+
+// Функция для создания нового экземпляра RevisionsService с заданным репозиторием
+func NewRevisionsService(rep *repository.Repository) *RevisionsService {
+	return &RevisionsService{rep}
+}
+
+// Метод для получения страницы ревизий песни, от новых к старым
+func (s *RevisionsService) GetRevisions(ctx context.Context, songID, page, pageSize int) (models.RevisionsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.RevisionsPage{}, err
+	}
+	if _, err := s.rep.GetSongByID(ctx, songID); err != nil {
+		return models.RevisionsPage{}, err
+	}
+
+	revisions, totalPages, err := s.rep.GetSongRevisions(ctx, songID, page, pageSize)
+	if err != nil {
+		return models.RevisionsPage{}, err
+	}
+
+	return models.RevisionsPage{
+		Revisions:   revisions,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для получения ревизии песни по номеру вместе со снимком
+func (s *RevisionsService) GetRevision(ctx context.Context, songID, revision int) (models.Revision, error) {
+	if _, err := s.rep.GetSongByID(ctx, songID); err != nil {
+		return models.Revision{}, err
+	}
+	return s.rep.GetSongRevision(ctx, songID, revision)
+}
+
+// Метод для построения различий между двумя ревизиями песни: изменённые поля и построчный diff текста
+func (s *RevisionsService) DiffRevisions(ctx context.Context, songID, from, to int) (models.RevisionDiff, error) {
+	if err := ValidateRevisionDiff(from, to); err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	fromRevision, err := s.GetRevision(ctx, songID, from)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	toRevision, err := s.rep.GetSongRevision(ctx, songID, to)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+
+	before, after := fromRevision.Snapshot, toRevision.Snapshot
+	diff := models.RevisionDiff{
+		SongID: songID,
+		From:   from,
+		To:     to,
+		Fields: []models.FieldChange{},
+		Text:   diffLines(before.Text, after.Text),
+	}
+	fields := []models.FieldChange{
+		{Field: "song", From: before.Song, To: after.Song},
+		{Field: "group", From: before.Group, To: after.Group},
+		{Field: "releaseDate", From: before.ReleaseDate, To: after.ReleaseDate},
+		{Field: "link", From: before.Link, To: after.Link},
+		{Field: "artists", From: before.FormatArtists(), To: after.FormatArtists()},
+	}
+	// Ревизии, сохранённые до появления жанров, тегов и альбома в снимках, по ним не сравниваются
+	if before.HasLabels() && after.HasLabels() {
+		fields = append(fields,
+			models.FieldChange{Field: "genres", From: strings.Join(before.Genres, ", "), To: strings.Join(after.Genres, ", ")},
+			models.FieldChange{Field: "tags", From: strings.Join(before.Tags, ", "), To: strings.Join(after.Tags, ", ")},
+			models.FieldChange{Field: "album", From: before.FormatAlbum(), To: after.FormatAlbum()},
+		)
+	}
+	for _, field := range fields {
+		if field.From != field.To {
+			diff.Fields = append(diff.Fields, field)
+		}
+	}
+
+	return diff, nil
+}
+
+// Метод для возврата песни к состоянию ревизии вместе с жанрами, тегами и альбомом; возврат
+// сохраняется как новая ревизия и записывается в журнал аудита. Возвращает песню после возврата.
+func (s *RevisionsService) RevertRevision(ctx context.Context, songID, revision int) (models.Songs, error) {
+	var reverted models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		target, err := s.rep.GetSongRevision(ctx, songID, revision)
+		if err != nil {
+			return err
+		}
+
+		if err := s.rep.ReplaceSong(ctx, songID, target.Snapshot.ToSong()); err != nil {
+			return err
+		}
+		if err := s.revertLabels(ctx, songID, target); err != nil {
+			return err
+		}
+		if _, err := recordRevision(ctx, s.rep, songID, &revision); err != nil {
+			return err
+		}
+
+		if reverted, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionRevert, songID, before, reverted)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	return reverted, nil
+}
+
+// Метод для возврата жанров, тегов и альбома песни к состоянию ревизии; ревизии без них
+// оставляют текущие значения. Удалённый с тех пор альбом - ErrConflict.
+func (s *RevisionsService) revertLabels(ctx context.Context, songID int, target models.Revision) error {
+	snapshot := target.Snapshot
+	if !snapshot.HasLabels() {
+		return nil
+	}
+
+	if err := s.rep.SetSongGenres(ctx, songID, snapshot.Genres); err != nil {
+		return err
+	}
+	if err := s.rep.SetSongTags(ctx, songID, snapshot.Tags); err != nil {
+		return err
+	}
+	if err := s.rep.SetSongAlbum(ctx, songID, snapshot.Album); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("album %q of revision %d no longer exists: %w", snapshot.Album.Title, target.Revision, ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+// Функция для построчного сравнения текстов по наибольшей общей подпоследовательности строк.
+// Удалённые строки идут перед вставленными на их месте, как в unified diff.
+func diffLines(from, to string) []models.DiffLine {
+	a, b := splitLines(from), splitLines(to)
+
+	// lcs[i][j] - длина наибольшей общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]models.DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, models.DiffLine{Op: models.DiffEqual, Line: a[i]})
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, models.DiffLine{Op: models.DiffDelete, Line: a[i]})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: models.DiffInsert, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffDelete, Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffInsert, Line: b[j]})
+	}
+
+	return lines
+}
+
+// Функция для разбиения текста на строки; пустой текст не содержит строк
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
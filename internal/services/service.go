@@ -1,32 +1,139 @@
 package services
 
 import (
+	"context"
+
 	"github.com/Ktuty/internal/models"
 	"github.com/Ktuty/internal/repository"
 )
 
 // Интерфейс Songs, определяющий методы для работы с песнями
 type Songs interface {
-	// Метод для получения всех песен с фильтрацией, пагинацией и возвратом общего количества страниц
-	GetAll(filter models.Songs, page, pageSize int) ([]models.Songs, int, error)
+	// Метод для получения страницы песен с фильтрацией, сортировкой и подсказкой при отсутствии точных совпадений
+	GetAll(ctx context.Context, filter models.SongFilter, sort []models.SortField, page, pageSize int) (models.SongsPage, error)
+	// Метод для потоковой выгрузки всех песен, подходящих под фильтр, без пагинации
+	Export(ctx context.Context, filter models.SongFilter, sort []models.SortField, fn func(song models.Songs) error) error
+	// Метод для получения страницы песен по курсору (keyset-пагинация) с необязательным общим количеством
+	GetByCursor(ctx context.Context, filter models.SongFilter, sort, cursor string, limit int, withTotal bool) (models.SongsCursorPage, error)
+	// Метод для импорта каталога песен пакетами с отчётом по каждой строке и пробным режимом
+	Import(ctx context.Context, reader SongReader, dryRun bool) (models.ImportReport, error)
+	// Метод для получения страницы пар песен с похожими текстами
+	Duplicates(ctx context.Context, threshold float64, page, pageSize int) (models.DuplicatesPage, error)
+	// Метод для полнотекстового поиска по текстам песен
+	Search(ctx context.Context, query string, page, pageSize int) (models.SearchPage, error)
 	// Метод для получения песни по ID
-	GetByID(id int) (models.Songs, error)
-	// Метод для создания новой песни
-	Create(song models.Songs) error
-	// Метод для обновления песни по ID
-	Update(songID int, song models.Songs) error
-	// Метод для удаления песни по ID
-	Delete(songID int) error
+	GetByID(ctx context.Context, id int) (models.Songs, error)
+	// Метод для создания новой песни с политикой onConflict для повтора названия в группе
+	// и постановкой в очередь обогащения; возвращает песню и признак того, что она создана
+	Create(ctx context.Context, song models.Songs, onConflict string) (models.Songs, bool, error)
+	// Метод для повторной постановки песни в очередь обогащения сведениями внешнего API
+	Enrich(ctx context.Context, songID int) (models.Songs, error)
+	// Метод для обновления песни по ID с необязательной проверкой версии; возвращает обновлённую песню
+	Update(ctx context.Context, songID int, song models.Songs, version int) (models.Songs, error)
+	// Метод для полной замены песни по ID с необязательной проверкой версии; возвращает новую песню
+	Replace(ctx context.Context, songID int, song models.Songs, version int) (models.Songs, error)
+	// Метод для частичного обновления песни по JSON Merge Patch с необязательной проверкой версии
+	MergePatch(ctx context.Context, songID int, patch []byte, version int) (models.Songs, error)
+	// Метод для частичного обновления песни по JSON Patch с необязательной проверкой версии
+	JSONPatch(ctx context.Context, songID int, patch []byte, version int) (models.Songs, error)
+	// Метод для перемещения песни в корзину по ID с необязательной проверкой версии
+	Delete(ctx context.Context, songID, version int) error
+	// Метод для получения страницы песен в корзине
+	Trash(ctx context.Context, page, pageSize int) (models.SongsPage, error)
+	// Метод для восстановления песни из корзины по ID
+	Restore(ctx context.Context, songID int) (models.Songs, error)
+}
+
+// Интерфейс Groups, определяющий методы для работы с группами
+type Groups interface {
+	// Метод для получения страницы групп с фильтрацией по подстроке имени
+	GetAllGroups(ctx context.Context, name string, page, pageSize int) (models.GroupsPage, error)
+	// Метод для получения группы по ID
+	GetGroupByID(ctx context.Context, id int) (models.Group, error)
+	// Метод для создания новой группы
+	CreateGroup(ctx context.Context, group models.Group) (models.Group, error)
+	// Метод для переименования группы вместе со всеми её песнями
+	RenameGroup(ctx context.Context, id int, group models.Group) (models.Group, error)
+	// Метод для удаления группы по ID, с её песнями при cascade
+	DeleteGroup(ctx context.Context, id int, cascade bool) error
+	// Метод для добавления псевдонима группы
+	AddGroupAlias(ctx context.Context, groupID int, alias models.GroupAlias) (models.Group, error)
+	// Метод для удаления псевдонима группы по ID
+	DeleteGroupAlias(ctx context.Context, groupID, aliasID int) error
+	// Метод для объединения исходных групп с целевой с переносом песен и псевдонимов
+	MergeGroups(ctx context.Context, targetID int, request models.GroupMergeRequest) (models.GroupMerge, error)
+	// Метод для получения страницы песен группы
+	GetGroupSongs(ctx context.Context, id int, sort []models.SortField, page, pageSize int) (models.SongsPage, error)
+}
+
+// Интерфейс Albums, определяющий методы для работы с альбомами
+type Albums interface {
+	// Метод для получения страницы альбомов с фильтрацией по названию и группе
+	GetAllAlbums(ctx context.Context, filter models.AlbumFilter, page, pageSize int) (models.AlbumsPage, error)
+	// Метод для получения альбома по ID вместе со списком треков
+	GetAlbumByID(ctx context.Context, id int) (models.Album, error)
+	// Метод для создания нового альбома
+	CreateAlbum(ctx context.Context, album models.Album) (models.Album, error)
+	// Метод для обновления альбома по ID
+	UpdateAlbum(ctx context.Context, id int, album models.Album) (models.Album, error)
+	// Метод для удаления альбома по ID
+	DeleteAlbum(ctx context.Context, id int) error
+	// Метод для замены списка треков альбома
+	SetAlbumTracks(ctx context.Context, id int, request models.AlbumTracksRequest) (models.Album, error)
+}
+
+// Интерфейс Tags, определяющий методы для работы с жанрами и тегами песен
+type Tags interface {
+	// Метод для получения страницы жанров с количеством песен
+	GetAllGenres(ctx context.Context, name string, page, pageSize int) (models.GenresPage, error)
+	// Метод для получения страницы тегов с количеством песен
+	GetAllTags(ctx context.Context, name string, page, pageSize int) (models.TagsPage, error)
+	// Метод для добавления песне жанров
+	AddSongGenres(ctx context.Context, songID int, request models.GenresRequest) (models.Songs, error)
+	// Метод для добавления песне тегов
+	AddSongTags(ctx context.Context, songID int, request models.TagsRequest) (models.Songs, error)
+	// Метод для удаления жанра у песни
+	DeleteSongGenre(ctx context.Context, songID int, name string) error
+	// Метод для удаления тега у песни
+	DeleteSongTag(ctx context.Context, songID int, name string) error
+}
+
+// Интерфейс Revisions, определяющий методы для работы с историей изменений песен
+type Revisions interface {
+	// Метод для получения страницы ревизий песни, от новых к старым
+	GetRevisions(ctx context.Context, songID, page, pageSize int) (models.RevisionsPage, error)
+	// Метод для получения ревизии песни по номеру вместе со снимком
+	GetRevision(ctx context.Context, songID, revision int) (models.Revision, error)
+	// Метод для построения различий между двумя ревизиями песни
+	DiffRevisions(ctx context.Context, songID, from, to int) (models.RevisionDiff, error)
+	// Метод для возврата песни к состоянию ревизии с сохранением новой ревизии
+	RevertRevision(ctx context.Context, songID, revision int) (models.Songs, error)
+}
+
+// Интерфейс Audit, определяющий методы для чтения журнала аудита изменений
+type Audit interface {
+	// Метод для получения страницы журнала аудита с фильтрацией, от новых записей к старым
+	GetAudit(ctx context.Context, filter models.AuditFilter, page, pageSize int) (models.AuditPage, error)
 }
 
-// Структура Service, реализующая интерфейс Songs
+// Структура Service, реализующая интерфейсы Songs, Groups, Albums, Tags, Revisions и Audit
 type Service struct {
 	Songs
+	Groups
+	Albums
+	Tags
+	Revisions
+	Audit
 }
 
 // Функция для создания нового экземпляра Service с заданным репозиторием
-func NewService(repo *repository.Repository) *Service {
+func NewService(repo *repository.Repository, enricher *Enricher) *Service {
 	return &Service{
-		Songs: NewSongsService(repo), // Инициализация сервиса песен с заданным репозиторием
+		Songs:     NewSongsService(repo, enricher), // Инициализация сервиса песен с заданным репозиторием и обогащением
+		Groups:    NewGroupsService(repo),          // Инициализация сервиса групп с заданным репозиторием
+		Albums:    NewAlbumsService(repo),          // Инициализация сервиса альбомов с заданным репозиторием
+		Tags:      NewTagsService(repo),            // Инициализация сервиса жанров и тегов с заданным репозиторием
+		Revisions: NewRevisionsService(repo),       // Инициализация сервиса ревизий песен с заданным репозиторием
+		Audit:     NewAuditService(repo),           // Инициализация сервиса журнала аудита с заданным репозиторием
 	}
 }
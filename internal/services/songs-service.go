@@ -1,41 +1,424 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/Ktuty/internal/models"
 	"github.com/Ktuty/internal/repository"
 )
 
 // Структура SongsService, которая инкапсулирует репозиторий для работы с песнями
 type SongsService struct {
-	rep *repository.Repository
+	rep      *repository.Repository
+	enricher *Enricher
+}
+
+// Функция для создания нового экземпляра SongsService с заданным репозиторием; enricher
+// пробуждается при постановке песен в очередь обогащения и может быть nil
+func NewSongsService(rep *repository.Repository, enricher *Enricher) *SongsService {
+	return &SongsService{rep: rep, enricher: enricher}
+}
+
+// Метод для получения страницы песен с фильтрацией и сортировкой.
+// Если по фильтрам song и group нет точных совпадений, в ответ добавляются ближайшие по написанию имена.
+func (s *SongsService) GetAll(ctx context.Context, filter models.SongFilter, sort []models.SortField, page, pageSize int) (models.SongsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.SongsPage{}, err
+	}
+	if err := ValidateSongFilter(filter); err != nil {
+		return models.SongsPage{}, err
+	}
+
+	songs, totalPages, err := s.rep.GetAllSongs(ctx, filter, sort, page, pageSize)
+	if err != nil {
+		return models.SongsPage{}, err
+	}
+
+	result := models.SongsPage{
+		Songs:       songs,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Sort:        models.FormatSort(sort),
+	}
+	if !filter.HasNameFilter() {
+		return result, nil
+	}
+
+	// Точные совпадения: без нечёткого режима найдена хотя бы одна песня
+	exact := totalPages > 0
+	if filter.Fuzzy {
+		exactFilter := filter
+		exactFilter.Fuzzy = false
+		count, err := s.rep.CountSongs(ctx, exactFilter)
+		if err != nil {
+			return models.SongsPage{}, err
+		}
+		exact = count > 0
+	}
+	if !exact {
+		if result.DidYouMean, err = s.rep.SuggestSongs(ctx, filter); err != nil {
+			return models.SongsPage{}, err
+		}
+	}
+
+	return result, nil
 }
 
-// Функция для создания нового экземпляра SongsService с заданным репозиторием
-func NewSongsService(rep *repository.Repository) *SongsService {
-	return &SongsService{rep}
+// Метод для получения страницы песен по курсору (keyset-пагинация) с необязательным общим количеством.
+// Если sort не задан, используется сортировка, для которой был выдан курсор.
+func (s *SongsService) GetByCursor(ctx context.Context, filter models.SongFilter, sort, cursor string, limit int, withTotal bool) (models.SongsCursorPage, error) {
+	if err := ValidateLimit(limit); err != nil {
+		return models.SongsCursorPage{}, err
+	}
+	if err := ValidateSongFilter(filter); err != nil {
+		return models.SongsCursorPage{}, err
+	}
+
+	var position *models.Cursor
+	if cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return models.SongsCursorPage{}, err
+		}
+		if sort == "" {
+			sort = decoded.Sort
+		}
+		position = &decoded
+	}
+
+	sortFields, err := ParseSongSort(sort)
+	if err != nil {
+		return models.SongsCursorPage{}, err
+	}
+	if position != nil && position.Sort != models.FormatSort(sortFields) {
+		var v validator
+		v.add("cursor", fmt.Sprintf("was issued for sort %q", position.Sort))
+		return models.SongsCursorPage{}, v.err()
+	}
+
+	songs, hasMore, err := s.rep.GetSongsByCursor(ctx, filter, sortFields, position, limit)
+	if err != nil {
+		return models.SongsCursorPage{}, err
+	}
+	if songs == nil {
+		songs = []models.Songs{}
+	}
+
+	page := models.SongsCursorPage{Songs: songs, Limit: limit, Sort: models.FormatSort(sortFields)}
+
+	// При обходе назад следующая страница существует всегда, а о предыдущей сообщает hasMore
+	backward := position != nil && position.Backward
+	hasNext, hasPrev := hasMore, position != nil
+	if backward {
+		hasNext, hasPrev = position != nil, hasMore
+	}
+
+	if len(songs) > 0 {
+		if hasNext {
+			page.NextCursor = EncodeCursor(cursorFromSong(sortFields, songs[len(songs)-1], false))
+		}
+		if hasPrev {
+			page.PrevCursor = EncodeCursor(cursorFromSong(sortFields, songs[0], true))
+		}
+	} else if position != nil {
+		// Пустая страница: обход в обратную сторону от той же позиции
+		reverse := *position
+		reverse.Backward = !backward
+		if backward {
+			page.NextCursor = EncodeCursor(reverse)
+		} else {
+			page.PrevCursor = EncodeCursor(reverse)
+		}
+	}
+
+	if withTotal {
+		total, err := s.rep.CountSongs(ctx, filter)
+		if err != nil {
+			return models.SongsCursorPage{}, err
+		}
+		page.TotalCount = &total
+	}
+
+	return page, nil
 }
 
-// Метод для получения всех песен с фильтрацией, пагинацией и возвратом общего количества страниц
-func (s *SongsService) GetAll(filter models.Songs, page, pageSize int) ([]models.Songs, int, error) {
-	return s.rep.GetAllSongs(filter, page, pageSize)
+// Метод для полнотекстового поиска по текстам песен
+func (s *SongsService) Search(ctx context.Context, query string, page, pageSize int) (models.SearchPage, error) {
+	if err := ValidateSearch(query, page, pageSize); err != nil {
+		return models.SearchPage{}, err
+	}
+
+	results, totalPages, err := s.rep.SearchSongs(ctx, strings.TrimSpace(query), page, pageSize)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+
+	return models.SearchPage{
+		Results:     results,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
 }
 
 // Метод для получения песни по ID
-func (s *SongsService) GetByID(id int) (models.Songs, error) {
-	return s.rep.GetSongByID(id)
+func (s *SongsService) GetByID(ctx context.Context, id int) (models.Songs, error) {
+	return s.rep.GetSongByID(ctx, id)
+}
+
+// Метод для создания новой песни; её начальное состояние сохраняется как первая ревизия
+// и записывается в журнал аудита, а сама песня ставится в очередь обогащения сведениями внешнего API.
+// Если в группе уже есть песня с тем же названием, onConflict определяет результат: error
+// (по умолчанию) возвращает *DuplicateSongError, skip возвращает существующую песню без изменений,
+// update обновляет её непустыми полями новой песни и снова ставит в очередь обогащения.
+// Возвращает песню и признак того, что она создана.
+func (s *SongsService) Create(ctx context.Context, song models.Songs, onConflict string) (models.Songs, bool, error) {
+	if err := ValidateSong(song); err != nil {
+		return models.Songs{}, false, err
+	}
+	if err := ValidateOnConflict(onConflict); err != nil {
+		return models.Songs{}, false, err
+	}
+
+	var result models.Songs
+	var created bool
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		songID, err := s.rep.PostSong(ctx, song)
+		var duplicate *DuplicateSongError
+		if errors.As(err, &duplicate) {
+			switch onConflict {
+			case models.OnConflictSkip:
+				result, err = s.rep.GetSongByID(ctx, duplicate.ExistingID)
+				return err
+			case models.OnConflictUpdate:
+				if _, err := s.Update(ctx, duplicate.ExistingID, song, 0); err != nil {
+					return err
+				}
+				if err := s.rep.QueueSongEnrichment(ctx, duplicate.ExistingID); err != nil {
+					return err
+				}
+				result, err = s.rep.GetSongByID(ctx, duplicate.ExistingID)
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := recordRevision(ctx, s.rep, songID, nil); err != nil {
+			return err
+		}
+
+		if result, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		created = true
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionCreate, songID, nil, result)
+	})
+	if err != nil {
+		return models.Songs{}, false, err
+	}
+	if result.Enrichment != nil && result.Enrichment.Status == models.EnrichmentPending {
+		s.enricher.Wake()
+	}
+
+	return result, created, nil
 }
 
-// Метод для создания новой песни
-func (s *SongsService) Create(song models.Songs) error {
-	return s.rep.PostSong(song)
+// Метод для повторной постановки песни в очередь обогащения сведениями внешнего API:
+// счётчик попыток сбрасывается, первая попытка выполняется сразу. Возвращает песню в статусе pending.
+func (s *SongsService) Enrich(ctx context.Context, songID int) (models.Songs, error) {
+	var result models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		if err := s.rep.QueueSongEnrichment(ctx, songID); err != nil {
+			return err
+		}
+		if result, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionEnrich, songID, before, result)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+	s.enricher.Wake()
+
+	return result, nil
+}
+
+// Метод для получения страницы пар песен с похожими текстами: схожесть триграмм не ниже threshold
+func (s *SongsService) Duplicates(ctx context.Context, threshold float64, page, pageSize int) (models.DuplicatesPage, error) {
+	if err := ValidateDuplicates(threshold, page, pageSize); err != nil {
+		return models.DuplicatesPage{}, err
+	}
+
+	duplicates, hasMore, err := s.rep.FindDuplicateSongs(ctx, threshold, page, pageSize)
+	if err != nil {
+		return models.DuplicatesPage{}, err
+	}
+
+	return models.DuplicatesPage{
+		Duplicates:  duplicates,
+		Threshold:   threshold,
+		HasMore:     hasMore,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для обновления песни по ID; состояние после изменения сохраняется как новая ревизия
+// и записывается в журнал аудита.
+// Если version больше 0, песня обновляется, только пока её версия совпадает с ним.
+// Возвращает песню после обновления.
+func (s *SongsService) Update(ctx context.Context, songID int, song models.Songs, version int) (models.Songs, error) {
+	if err := ValidateSongUpdate(song); err != nil {
+		return models.Songs{}, err
+	}
+
+	var updated models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkVersion(ctx, songID, version); err != nil {
+			return err
+		}
+		before, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		if err := s.rep.UpdateSong(ctx, songID, song); err != nil {
+			return err
+		}
+		if _, err := recordRevision(ctx, s.rep, songID, nil); err != nil {
+			return err
+		}
+
+		if updated, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionUpdate, songID, before, updated)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	return updated, nil
+}
+
+// Метод для полной замены песни по ID: непереданные необязательные поля очищаются,
+// отсутствующий список исполнителей удаляет дополнительных исполнителей.
+// Если version больше 0, песня заменяется, только пока её версия совпадает с ним.
+func (s *SongsService) Replace(ctx context.Context, songID int, song models.Songs, version int) (models.Songs, error) {
+	if err := ValidateSong(song); err != nil {
+		return models.Songs{}, err
+	}
+	return s.replace(ctx, songID, version, func(models.SongSnapshot) (models.Songs, error) {
+		return song, nil
+	})
+}
+
+// Метод для частичного обновления песни по JSON Merge Patch (RFC 7396): null очищает поле
+func (s *SongsService) MergePatch(ctx context.Context, songID int, patch []byte, version int) (models.Songs, error) {
+	return s.patch(ctx, songID, version, func(document []byte) ([]byte, error) {
+		return applyMergePatch(document, patch)
+	})
+}
+
+// Метод для частичного обновления песни по JSON Patch (RFC 6902)
+func (s *SongsService) JSONPatch(ctx context.Context, songID int, patch []byte, version int) (models.Songs, error) {
+	return s.patch(ctx, songID, version, func(document []byte) ([]byte, error) {
+		return applyJSONPatch(document, patch)
+	})
+}
+
+// Метод для применения патча к документу песни (поля снимка ревизии) и замены песни результатом
+func (s *SongsService) patch(ctx context.Context, songID, version int, apply func(document []byte) ([]byte, error)) (models.Songs, error) {
+	return s.replace(ctx, songID, version, func(current models.SongSnapshot) (models.Songs, error) {
+		document, err := json.Marshal(current)
+		if err != nil {
+			return models.Songs{}, fmt.Errorf("error encoding song with id %d: %w", songID, err)
+		}
+		patched, err := apply(document)
+		if err != nil {
+			return models.Songs{}, err
+		}
+		snapshot, err := decodeSnapshot(patched)
+		if err != nil {
+			return models.Songs{}, err
+		}
+
+		song := snapshot.ToSong()
+		if err := ValidateSong(song); err != nil {
+			return models.Songs{}, err
+		}
+		return song, nil
+	})
+}
+
+// Метод для замены песни результатом build от её текущего состояния в одной транзакции
+// с проверкой версии, сохранением ревизии и записью в журнал аудита. Возвращает песню после замены.
+func (s *SongsService) replace(ctx context.Context, songID, version int, build func(current models.SongSnapshot) (models.Songs, error)) (models.Songs, error) {
+	var replaced models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkVersion(ctx, songID, version); err != nil {
+			return err
+		}
+		current, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		song, err := build(models.NewSongSnapshot(current))
+		if err != nil {
+			return err
+		}
+
+		if err := s.rep.ReplaceSong(ctx, songID, song); err != nil {
+			return err
+		}
+		if _, err := recordRevision(ctx, s.rep, songID, nil); err != nil {
+			return err
+		}
+
+		if replaced, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionUpdate, songID, current, replaced)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	return replaced, nil
 }
 
-// Метод для обновления песни по ID
-func (s *SongsService) Update(songID int, song models.Songs) error {
-	return s.rep.UpdateSong(songID, song)
+// Метод для перемещения песни в корзину по ID с записью в журнал аудита.
+// Если version больше 0, песня удаляется, только пока её версия совпадает с ним.
+func (s *SongsService) Delete(ctx context.Context, songID, version int) error {
+	return s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkVersion(ctx, songID, version); err != nil {
+			return err
+		}
+		before, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		if err := s.rep.DeleteSong(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionDelete, songID, before, nil)
+	})
 }
 
-// Метод для удаления песни по ID
-func (s *SongsService) Delete(songID int) error {
-	return s.rep.DeleteSong(songID)
+// Метод для проверки версии песни перед изменением; 0 означает изменение без проверки
+func (s *SongsService) checkVersion(ctx context.Context, songID, version int) error {
+	if version <= 0 {
+		return nil
+	}
+	return s.rep.CheckSongVersion(ctx, songID, version)
 }
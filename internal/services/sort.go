@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// ParseSongSort разбирает параметр sort вида "group,-releaseDate,song".
+// Допускаются только ключи из models.SongSortKeys, каждый не более одного раза.
+// В конец добавляется сортировка по id, чтобы порядок страниц был детерминированным.
+func ParseSongSort(raw string) ([]models.SortField, error) {
+	var v validator
+	var fields []models.SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := models.SortField{Key: part}
+		if strings.HasPrefix(part, "-") {
+			field = models.SortField{Key: part[1:], Desc: true}
+		} else if strings.HasPrefix(part, "+") {
+			field.Key = part[1:]
+		}
+
+		switch {
+		case !isSongSortKey(field.Key):
+			v.add("sort", fmt.Sprintf("unknown sort key %q, allowed: %s", field.Key, strings.Join(models.SongSortKeys, ", ")))
+		case seen[field.Key]:
+			v.add("sort", fmt.Sprintf("duplicate sort key %q", field.Key))
+		default:
+			seen[field.Key] = true
+			fields = append(fields, field)
+		}
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if !seen[models.SortByID] {
+		fields = append(fields, models.SortField{Key: models.SortByID})
+	}
+
+	return fields, nil
+}
+
+// Функция для проверки, входит ли ключ в список разрешённых ключей сортировки
+func isSongSortKey(key string) bool {
+	for _, allowed := range models.SongSortKeys {
+		if key == allowed {
+			return true
+		}
+	}
+	return false
+}
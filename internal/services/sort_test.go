@@ -0,0 +1,34 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+func TestParseSongSort(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		fields []string
+	}{
+		{raw: "", want: "id"},
+		{raw: "group,-releaseDate", want: "group,-releaseDate,id"},
+		{raw: " +song , -id ", want: "song,-id"},
+		{raw: "title", fields: []string{"sort"}},
+		{raw: "song,-song", fields: []string{"sort"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSongSort(tt.raw)
+			if fields := errorFields(t, err); strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Fatalf("fields = %v, want %v", fields, tt.fields)
+			}
+			if err == nil && models.FormatSort(got) != tt.want {
+				t.Errorf("ParseSongSort(%q) = %s, want %s", tt.raw, models.FormatSort(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+)
+
+// Структура TagsService, которая инкапсулирует репозиторий для работы с жанрами и тегами
+type TagsService struct {
+	rep *repository.Repository
+}
+
+// Функция для создания нового экземпляра TagsService с заданным репозиторием
+func NewTagsService(rep *repository.Repository) *TagsService {
+	return &TagsService{rep}
+}
+
+// Метод для получения страницы жанров с количеством песен
+func (s *TagsService) GetAllGenres(ctx context.Context, name string, page, pageSize int) (models.GenresPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.GenresPage{}, err
+	}
+
+	genres, totalPages, err := s.rep.GetAllGenres(ctx, name, page, pageSize)
+	if err != nil {
+		return models.GenresPage{}, err
+	}
+
+	return models.GenresPage{
+		Genres:      genres,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для получения страницы тегов с количеством песен
+func (s *TagsService) GetAllTags(ctx context.Context, name string, page, pageSize int) (models.TagsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.TagsPage{}, err
+	}
+
+	tags, totalPages, err := s.rep.GetAllTags(ctx, name, page, pageSize)
+	if err != nil {
+		return models.TagsPage{}, err
+	}
+
+	return models.TagsPage{
+		Tags:        tags,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}, nil
+}
+
+// Метод для добавления песне жанров с проверкой имён; возвращает обновлённую песню
+func (s *TagsService) AddSongGenres(ctx context.Context, songID int, request models.GenresRequest) (models.Songs, error) {
+	if err := ValidateLabels("genres", request.Genres); err != nil {
+		return models.Songs{}, err
+	}
+	return s.updateSong(ctx, songID, func(ctx context.Context) error {
+		return s.rep.AddSongGenres(ctx, songID, request.Genres)
+	})
+}
+
+// Метод для добавления песне тегов с проверкой имён; возвращает обновлённую песню
+func (s *TagsService) AddSongTags(ctx context.Context, songID int, request models.TagsRequest) (models.Songs, error) {
+	if err := ValidateLabels("tags", request.Tags); err != nil {
+		return models.Songs{}, err
+	}
+	return s.updateSong(ctx, songID, func(ctx context.Context) error {
+		return s.rep.AddSongTags(ctx, songID, request.Tags)
+	})
+}
+
+// Метод для удаления жанра у песни
+func (s *TagsService) DeleteSongGenre(ctx context.Context, songID int, name string) error {
+	_, err := s.updateSong(ctx, songID, func(ctx context.Context) error {
+		return s.rep.DeleteSongGenre(ctx, songID, name)
+	})
+	return err
+}
+
+// Метод для удаления тега у песни
+func (s *TagsService) DeleteSongTag(ctx context.Context, songID int, name string) error {
+	_, err := s.updateSong(ctx, songID, func(ctx context.Context) error {
+		return s.rep.DeleteSongTag(ctx, songID, name)
+	})
+	return err
+}
+
+// Метод для изменения жанров или тегов песни в транзакции с записью в журнал аудита;
+// изменённый набор жанров или тегов сохраняется как новая ревизия. Возвращает песню после изменения.
+func (s *TagsService) updateSong(ctx context.Context, songID int, change func(ctx context.Context) error) (models.Songs, error) {
+	var updated models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.rep.GetSongByID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		if err := change(ctx); err != nil {
+			return err
+		}
+
+		if updated, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		if !slices.Equal(before.Genres, updated.Genres) || !slices.Equal(before.Tags, updated.Tags) {
+			if _, err := recordRevision(ctx, s.rep, songID, nil); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionUpdate, songID, before, updated)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ktuty/internal/models"
+	"github.com/Ktuty/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Метод для получения страницы песен в корзине
+func (s *SongsService) Trash(ctx context.Context, page, pageSize int) (models.SongsPage, error) {
+	if err := ValidatePagination(page, pageSize); err != nil {
+		return models.SongsPage{}, err
+	}
+
+	songs, totalPages, err := s.rep.GetAllSongs(ctx, models.SongFilter{Deleted: true}, nil, page, pageSize)
+	if err != nil {
+		return models.SongsPage{}, err
+	}
+
+	return models.SongsPage{
+		Songs:       songs,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+		Sort:        models.SortByID,
+	}, nil
+}
+
+// Метод для восстановления песни из корзины с записью в журнал аудита; возвращает восстановленную песню
+func (s *SongsService) Restore(ctx context.Context, songID int) (models.Songs, error) {
+	var restored models.Songs
+	err := s.rep.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.rep.RestoreSong(ctx, songID); err != nil {
+			return err
+		}
+
+		var err error
+		if restored, err = s.rep.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.rep, models.AuditEntitySong, models.AuditActionRestore, songID, nil, restored)
+	})
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	return restored, nil
+}
+
+// Структура Purger, периодически удаляющая из корзины песни старше срока хранения
+type Purger struct {
+	rep       *repository.Repository
+	retention time.Duration
+	interval  time.Duration
+}
+
+// Функция для создания нового экземпляра Purger с заданным сроком хранения и интервалом очистки
+func NewPurger(rep *repository.Repository, retention, interval time.Duration) *Purger {
+	return &Purger{rep: rep, retention: retention, interval: interval}
+}
+
+// Метод для очистки корзины каждые interval до отмены ctx; первая очистка выполняется сразу
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Метод для однократного удаления из корзины песен, удалённых раньше срока хранения
+func (p *Purger) Purge(ctx context.Context) {
+	before := time.Now().Add(-p.retention)
+	purged, err := p.rep.PurgeDeletedSongs(ctx, before)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при очистке корзины")
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"purged": purged,
+		"before": before,
+	}).Info("Trash purged")
+}
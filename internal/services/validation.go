@@ -0,0 +1,372 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Максимальная длина строковых полей, совпадающая с VARCHAR(255) в схеме
+const maxFieldLength = 255
+
+// Максимальная длина имени жанра или тега, совпадающая с VARCHAR(64) в схеме
+const maxLabelLength = 64
+
+// Структура ValidationError, содержащая ошибки по каждому невалидному полю
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+// Метод для получения текстового описания всех ошибок валидации
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(messages, "; "))
+}
+
+// Метод, позволяющий сравнивать ValidationError с ErrValidation через errors.Is
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// Структура validator, накапливающая ошибки полей
+type validator struct {
+	fields []models.FieldError
+}
+
+// Метод для добавления ошибки поля
+func (v *validator) add(field, message string) {
+	v.fields = append(v.fields, models.FieldError{Field: field, Message: message})
+}
+
+// Метод для проверки обязательного строкового поля
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+// Метод для проверки максимальной длины строкового поля в символах
+func (v *validator) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.add(field, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// Метод для проверки ссылки: абсолютный URL со схемой http или https
+func (v *validator) link(field, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.ParseRequestURI(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "must be an absolute http(s) URL")
+	}
+}
+
+// Метод для проверки даты выпуска
+func (v *validator) releaseDate(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := models.ParseReleaseDate(value); err != nil {
+		v.add(field, "must be a date in DD.MM.YYYY or YYYY-MM-DD format")
+	}
+}
+
+// Метод для получения итоговой ошибки валидации или nil, если ошибок нет
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// ValidateParams проверяет параметры создания песни, передаваемые во внешний API
+func ValidateParams(params models.Params) error {
+	var v validator
+	v.required("group", params.Group)
+	v.maxLength("group", params.Group, maxFieldLength)
+	v.required("song", params.Song)
+	v.maxLength("song", params.Song, maxFieldLength)
+	validateArtists(&v, params.Artists)
+	return v.err()
+}
+
+// ValidateSong проверяет песню перед созданием: группа и название обязательны
+func ValidateSong(song models.Songs) error {
+	var v validator
+	v.required("group", song.Group)
+	v.required("song", song.Song)
+	validateSongFields(&v, song)
+	return v.err()
+}
+
+// ValidateSongUpdate проверяет данные для обновления песни: все поля необязательны,
+// но хотя бы одно должно быть передано
+func ValidateSongUpdate(song models.Songs) error {
+	var v validator
+	if song.Group == "" && song.Song == "" && song.Text == "" && song.ReleaseDate == "" && song.Link == "" && song.Artists == nil {
+		v.add("body", "at least one field must be provided")
+	}
+	validateSongFields(&v, song)
+	return v.err()
+}
+
+// ValidateGroup проверяет группу перед созданием или переименованием
+func ValidateGroup(group models.Group) error {
+	var v validator
+	v.required("name", group.Name)
+	v.maxLength("name", group.Name, maxFieldLength)
+	return v.err()
+}
+
+// ValidateGroupAlias проверяет псевдоним группы перед добавлением
+func ValidateGroupAlias(alias models.GroupAlias) error {
+	var v validator
+	v.required("alias", alias.Alias)
+	v.maxLength("alias", alias.Alias, maxFieldLength)
+	return v.err()
+}
+
+// ValidateGroupMerge проверяет список исходных групп: не пустой, без повторов и без целевой группы
+func ValidateGroupMerge(targetID int, request models.GroupMergeRequest) error {
+	var v validator
+	if len(request.Sources) == 0 {
+		v.add("sources", "must contain at least one group id")
+	}
+	seen := make(map[int]bool, len(request.Sources))
+	for i, id := range request.Sources {
+		field := fmt.Sprintf("sources[%d]", i)
+		switch {
+		case id == targetID:
+			v.add(field, "must differ from the target group")
+		case seen[id]:
+			v.add(field, "is duplicated")
+		}
+		seen[id] = true
+	}
+	return v.err()
+}
+
+// ValidateAlbum проверяет альбом перед созданием: название и группа обязательны
+func ValidateAlbum(album models.Album) error {
+	var v validator
+	v.required("title", album.Title)
+	v.required("group", album.Group)
+	validateAlbumFields(&v, album)
+	return v.err()
+}
+
+// ValidateAlbumUpdate проверяет данные для обновления альбома: все поля необязательны,
+// но хотя бы одно должно быть передано; группа альбома не меняется
+func ValidateAlbumUpdate(album models.Album) error {
+	var v validator
+	if album.Title == "" && album.ReleaseDate == "" && album.CoverLink == "" {
+		v.add("body", "at least one of title, releaseDate, coverLink must be provided")
+	}
+	if album.Group != "" {
+		v.add("group", "cannot be changed")
+	}
+	validateAlbumFields(&v, album)
+	return v.err()
+}
+
+// ValidateAlbumTracks проверяет список треков: номера положительные, без повторов номеров и песен
+func ValidateAlbumTracks(request models.AlbumTracksRequest) error {
+	var v validator
+	numbers := make(map[int]bool, len(request.Tracks))
+	songs := make(map[int]bool, len(request.Tracks))
+	for i, track := range request.Tracks {
+		field := fmt.Sprintf("tracks[%d]", i)
+		switch {
+		case track.TrackNumber < 1:
+			v.add(field+".trackNumber", "must be greater than 0")
+		case numbers[track.TrackNumber]:
+			v.add(field+".trackNumber", "is duplicated")
+		}
+		if songs[track.SongID] {
+			v.add(field+".songId", "is duplicated")
+		}
+		numbers[track.TrackNumber], songs[track.SongID] = true, true
+	}
+	return v.err()
+}
+
+// ValidateLabels проверяет имена жанров или тегов: хотя бы одно, без повторов после нормализации
+func ValidateLabels(field string, names []string) error {
+	var v validator
+	if len(names) == 0 {
+		v.add(field, "must contain at least one name")
+	}
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		item := fmt.Sprintf("%s[%d]", field, i)
+		name = models.NormalizeLabel(name)
+		v.required(item, name)
+		v.maxLength(item, name, maxLabelLength)
+		if name != "" && seen[name] {
+			v.add(item, "is duplicated")
+		}
+		seen[name] = true
+	}
+	return v.err()
+}
+
+// ValidatePagination проверяет параметры постраничного вывода
+func ValidatePagination(page, pageSize int) error {
+	var v validator
+	if page < 1 {
+		v.add("page", "must be greater than 0")
+	}
+	if pageSize < 1 {
+		v.add("pageSize", "must be greater than 0")
+	}
+	return v.err()
+}
+
+// ValidateSearch проверяет поисковый запрос и параметры постраничного вывода
+func ValidateSearch(query string, page, pageSize int) error {
+	var v validator
+	v.required("q", query)
+	v.maxLength("q", query, maxFieldLength)
+	if err := ValidatePagination(page, pageSize); err != nil {
+		v.fields = append(v.fields, err.(*ValidationError).Fields...)
+	}
+	return v.err()
+}
+
+// ValidateLimit проверяет размер страницы в режиме курсора
+func ValidateLimit(limit int) error {
+	var v validator
+	if limit < 1 {
+		v.add("limit", "must be greater than 0")
+	}
+	return v.err()
+}
+
+// ValidateOnConflict проверяет политику создания песни с повторяющимся названием; пустая - error
+func ValidateOnConflict(onConflict string) error {
+	var v validator
+	switch onConflict {
+	case "", models.OnConflictError, models.OnConflictSkip, models.OnConflictUpdate:
+	default:
+		v.add("onConflict", "must be one of error, skip, update")
+	}
+	return v.err()
+}
+
+// ValidateExportFormat проверяет формат выгрузки каталога песен
+func ValidateExportFormat(format string) error {
+	var v validator
+	switch format {
+	case models.ExportFormatCSV, models.ExportFormatNDJSON, models.ExportFormatJSON:
+	default:
+		v.add("format", "must be one of csv, ndjson, json")
+	}
+	return v.err()
+}
+
+// ValidateDuplicates проверяет порог схожести текстов и параметры постраничного вывода
+func ValidateDuplicates(threshold float64, page, pageSize int) error {
+	var v validator
+	if !(threshold > 0 && threshold <= 1) {
+		v.add("threshold", "must be greater than 0 and at most 1")
+	}
+	if err := ValidatePagination(page, pageSize); err != nil {
+		v.fields = append(v.fields, err.(*ValidationError).Fields...)
+	}
+	return v.err()
+}
+
+// ValidateRevisionDiff проверяет номера сравниваемых ревизий
+func ValidateRevisionDiff(from, to int) error {
+	var v validator
+	if from < 1 {
+		v.add("from", "must be greater than 0")
+	}
+	if to < 1 {
+		v.add("to", "must be greater than 0")
+	}
+	return v.err()
+}
+
+// ValidateSongFilter проверяет фильтр списка песен
+func ValidateSongFilter(filter models.SongFilter) error {
+	var v validator
+	if !filter.ReleaseDateFrom.IsZero() && !filter.ReleaseDateTo.IsZero() && filter.ReleaseDateTo.Before(filter.ReleaseDateFrom) {
+		v.add("releaseDateTo", "must not be before releaseDateFrom")
+	}
+	return v.err()
+}
+
+// ValidateAuditFilter проверяет фильтр журнала аудита
+func ValidateAuditFilter(filter models.AuditFilter) error {
+	var v validator
+	switch filter.Entity {
+	case "", models.AuditEntitySong, models.AuditEntityGroup, models.AuditEntityAlbum:
+	default:
+		v.add("entity", "must be one of song, group, album")
+	}
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete,
+		models.AuditActionRestore, models.AuditActionRevert, models.AuditActionMerge, models.AuditActionEnrich:
+	default:
+		v.add("action", "must be one of create, update, delete, restore, revert, merge, enrich")
+	}
+	if filter.EntityID < 0 {
+		v.add("entityId", "must not be negative")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		v.add("to", "must be after from")
+	}
+	return v.err()
+}
+
+// Функция для проверки ограничений полей песни, общих для создания и обновления
+func validateSongFields(v *validator, song models.Songs) {
+	v.maxLength("group", song.Group, maxFieldLength)
+	v.maxLength("song", song.Song, maxFieldLength)
+	v.maxLength("link", song.Link, maxFieldLength)
+	v.link("link", song.Link)
+	v.releaseDate("releaseDate", song.ReleaseDate)
+	validateArtists(v, song.Artists)
+}
+
+// Функция для проверки дополнительных исполнителей песни: основной исполнитель задаётся полем group,
+// одна группа не указывается дважды в одной роли
+func validateArtists(v *validator, artists []models.SongArtist) {
+	seen := make(map[models.SongArtist]bool, len(artists))
+	for i, artist := range artists {
+		field := fmt.Sprintf("artists[%d]", i)
+		v.required(field+".group", artist.Group)
+		v.maxLength(field+".group", artist.Group, maxFieldLength)
+		switch artist.Role {
+		case models.ArtistRoleFeatured, models.ArtistRoleComposer, models.ArtistRoleLyricist:
+		case models.ArtistRolePrimary:
+			v.add(field+".role", "primary artist is set by the group field")
+		default:
+			v.add(field+".role", "must be one of featured, composer, lyricist")
+		}
+
+		key := models.SongArtist{Group: models.GroupKey(artist.Group), Role: artist.Role}
+		if seen[key] {
+			v.add(field, "is duplicated")
+		}
+		seen[key] = true
+	}
+}
+
+// Функция для проверки ограничений полей альбома, общих для создания и обновления
+func validateAlbumFields(v *validator, album models.Album) {
+	v.maxLength("title", album.Title, maxFieldLength)
+	v.maxLength("group", album.Group, maxFieldLength)
+	v.maxLength("coverLink", album.CoverLink, maxFieldLength)
+	v.link("coverLink", album.CoverLink)
+	v.releaseDate("releaseDate", album.ReleaseDate)
+}
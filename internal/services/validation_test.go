@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ktuty/internal/models"
+)
+
+// Функция для получения имён полей ошибки валидации; nil без ошибки
+func errorFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error %v is not a *ValidationError", err)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("error %v does not wrap ErrValidation", err)
+	}
+	fields := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	return fields
+}
+
+func TestValidateSong(t *testing.T) {
+	tests := []struct {
+		name string
+		song models.Songs
+		want []string
+	}{
+		{name: "valid", song: models.Songs{Group: "Muse", Song: "Uprising"}},
+		{name: "all fields", song: models.Songs{Group: "Muse", Song: "Uprising", Text: "text", ReleaseDate: "2006-07-16", Link: "https://example.com"}},
+		{name: "blank group and song", song: models.Songs{Group: " ", Song: ""}, want: []string{"group", "song"}},
+		{name: "too long song", song: models.Songs{Group: "Muse", Song: strings.Repeat("я", 256)}, want: []string{"song"}},
+		{name: "255 characters", song: models.Songs{Group: "Muse", Song: strings.Repeat("я", 255)}},
+		{name: "invalid date", song: models.Songs{Group: "Muse", Song: "Uprising", ReleaseDate: "31.02.2006"}, want: []string{"releaseDate"}},
+		{name: "relative link", song: models.Songs{Group: "Muse", Song: "Uprising", Link: "/watch"}, want: []string{"link"}},
+		{name: "ftp link", song: models.Songs{Group: "Muse", Song: "Uprising", Link: "ftp://example.com"}, want: []string{"link"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(t, ValidateSong(tt.song))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSongUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		song models.Songs
+		want []string
+	}{
+		{name: "one field", song: models.Songs{Text: "text"}},
+		{name: "empty", song: models.Songs{}, want: []string{"body"}},
+		{name: "only artists", song: models.Songs{Artists: []models.SongArtist{}}},
+		{name: "invalid date", song: models.Songs{ReleaseDate: "16/07/2006"}, want: []string{"releaseDate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(t, ValidateSongUpdate(tt.song))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDuplicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		page      int
+		pageSize  int
+		want      []string
+	}{
+		{name: "valid", threshold: 0.7, page: 1, pageSize: 10},
+		{name: "threshold one", threshold: 1, page: 1, pageSize: 10},
+		{name: "zero threshold", threshold: 0, page: 1, pageSize: 10, want: []string{"threshold"}},
+		{name: "threshold above one", threshold: 1.5, page: 1, pageSize: 10, want: []string{"threshold"}},
+		{name: "invalid pagination", threshold: 0.7, page: 0, pageSize: 0, want: []string{"page", "pageSize"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(t, ValidateDuplicates(tt.threshold, tt.page, tt.pageSize))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
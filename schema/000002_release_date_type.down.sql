@@ -0,0 +1,9 @@
+-- Вернуть release_date в строковый формат внешнего API; непреобразованные значения
+-- восстанавливаются из release_date_raw
+ALTER TABLE songs
+    ALTER COLUMN release_date TYPE VARCHAR(255)
+    USING COALESCE(to_char(release_date, 'DD.MM.YYYY'), release_date_raw, '');
+
+ALTER TABLE songs ALTER COLUMN release_date SET NOT NULL;
+
+ALTER TABLE songs DROP COLUMN release_date_raw;
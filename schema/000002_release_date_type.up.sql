@@ -0,0 +1,31 @@
+-- Перевести release_date из строки в DATE.
+-- Поддерживаются формат внешнего API (16.07.2006) и ISO (2006-07-16).
+-- Непустые значения, которые не удалось преобразовать (другой формат или несуществующая дата
+-- вроде 31.02.2006), сохраняются в release_date_raw, а release_date становится NULL.
+ALTER TABLE songs ADD COLUMN release_date_raw VARCHAR(255);
+
+CREATE FUNCTION pg_temp.parse_release_date(value TEXT) RETURNS DATE AS $$
+BEGIN
+    IF trim(value) ~ '^\d{2}\.\d{2}\.\d{4}$' THEN
+        RETURN to_date(trim(value), 'DD.MM.YYYY');
+    ELSIF trim(value) ~ '^\d{4}-\d{2}-\d{2}$' THEN
+        RETURN to_date(trim(value), 'YYYY-MM-DD');
+    END IF;
+    RETURN NULL;
+EXCEPTION
+    WHEN datetime_field_overflow OR invalid_datetime_format THEN
+        RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE songs
+SET release_date_raw = release_date
+WHERE trim(release_date) <> '' AND pg_temp.parse_release_date(release_date) IS NULL;
+
+ALTER TABLE songs ALTER COLUMN release_date DROP NOT NULL;
+
+ALTER TABLE songs
+    ALTER COLUMN release_date TYPE DATE
+    USING pg_temp.parse_release_date(release_date);
+
+DROP FUNCTION pg_temp.parse_release_date(TEXT);
@@ -0,0 +1,12 @@
+-- Полнотекстовый поиск по названию и тексту песни.
+-- Конфигурация russian разбирает латиницу английским стеммером,
+-- поэтому один вектор обслуживает и русские, и английские тексты.
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(song, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(text, '')), 'B')
+    ) STORED;
+
+-- Создать GIN-индекс для поиска по вектору
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN (search_vector);
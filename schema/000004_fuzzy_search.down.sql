@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_groups_group_trgm;
+DROP INDEX IF EXISTS idx_songs_song_trgm;
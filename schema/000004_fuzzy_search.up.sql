@@ -0,0 +1,6 @@
+-- Нечёткий поиск по названию песни и имени группы на основе триграмм
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Создать GIN-индексы триграмм для операторов ILIKE и <%
+CREATE INDEX IF NOT EXISTS idx_songs_song_trgm ON songs USING GIN (song gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_groups_group_trgm ON groups USING GIN ("group" gin_trgm_ops);
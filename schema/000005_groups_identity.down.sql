@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_groups_name_key;
+ALTER TABLE groups DROP COLUMN IF EXISTS name_key;
@@ -0,0 +1,9 @@
+-- Идентичность групп: имя без учёта регистра, пробелов и диакритических знаков.
+-- Ключ name_key вычисляется приложением (models.GroupKey). У существующих групп его заполняет
+-- миграция данных приложения сразу после этой миграции (repository.backfillGroupKeys): она
+-- нормализует имена, объединяет группы с совпавшим ключом и делает name_key обязательным.
+-- Миграция повторяемая: при ошибке миграции данных она выполнится снова при следующем запуске.
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS name_key VARCHAR(255);
+
+-- Создать уникальный индекс по ключу имени группы
+CREATE UNIQUE INDEX IF NOT EXISTS uq_groups_name_key ON groups (name_key);
@@ -0,0 +1,12 @@
+-- Альтернативные написания имён групп ("ACDC" для "AC/DC").
+-- Ключ name_key вычисляется так же, как groups.name_key, и не совпадает с ключом ни одной группы.
+CREATE TABLE IF NOT EXISTS group_aliases (
+    id SERIAL PRIMARY KEY,
+    group_id INT REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    alias VARCHAR(255) NOT NULL,
+    name_key VARCHAR(255) NOT NULL
+);
+
+-- Создать индексы для group_aliases
+CREATE UNIQUE INDEX IF NOT EXISTS uq_group_aliases_name_key ON group_aliases (name_key);
+CREATE INDEX IF NOT EXISTS idx_group_aliases_group_id ON group_aliases (group_id);
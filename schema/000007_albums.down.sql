@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS uq_songs_album_track;
+DROP INDEX IF EXISTS idx_songs_album_id;
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS track_number,
+    DROP COLUMN IF EXISTS album_id;
+DROP TABLE IF EXISTS albums;
@@ -0,0 +1,20 @@
+-- Создать таблицу albums
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    group_id INT REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    release_date DATE,
+    cover_link VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- Создать индексы для albums; название альбома уникально в пределах группы
+CREATE INDEX IF NOT EXISTS idx_albums_group_id ON albums (group_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_albums_group_title ON albums (group_id, lower(title));
+
+-- Песня входит не более чем в один альбом под уникальным номером трека
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS album_id INT REFERENCES albums(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS track_number INT CHECK (track_number > 0);
+
+CREATE INDEX IF NOT EXISTS idx_songs_album_id ON songs (album_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_songs_album_track ON songs (album_id, track_number);
@@ -0,0 +1,19 @@
+-- Исполнители песни с ролями. Основной исполнитель дублирует songs.group_id,
+-- остальные роли описывают участие других групп (feat., авторы музыки и слов).
+CREATE TABLE IF NOT EXISTS song_artists (
+    song_id INT REFERENCES songs(id) ON DELETE CASCADE NOT NULL,
+    group_id INT REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('primary', 'featured', 'composer', 'lyricist')),
+    PRIMARY KEY (song_id, group_id, role)
+);
+
+-- Создать индекс для поиска песен по исполнителю
+CREATE INDEX IF NOT EXISTS idx_song_artists_group_id ON song_artists (group_id);
+
+-- У песни ровно один основной исполнитель
+CREATE UNIQUE INDEX IF NOT EXISTS uq_song_artists_primary ON song_artists (song_id) WHERE role = 'primary';
+
+-- Перенести группы существующих песен как основных исполнителей
+INSERT INTO song_artists (song_id, group_id, role)
+SELECT id, group_id, 'primary' FROM songs
+ON CONFLICT DO NOTHING;
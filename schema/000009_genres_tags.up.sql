@@ -0,0 +1,27 @@
+-- Справочники жанров и свободных тегов; имена хранятся нормализованными (models.NormalizeLabel)
+CREATE TABLE IF NOT EXISTS genres (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+-- Связи песен с жанрами и тегами
+CREATE TABLE IF NOT EXISTS song_genres (
+    song_id INT REFERENCES songs(id) ON DELETE CASCADE NOT NULL,
+    genre_id INT REFERENCES genres(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (song_id, genre_id)
+);
+
+CREATE TABLE IF NOT EXISTS song_tags (
+    song_id INT REFERENCES songs(id) ON DELETE CASCADE NOT NULL,
+    tag_id INT REFERENCES tags(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (song_id, tag_id)
+);
+
+-- Создать индексы для фильтрации песен по жанру и тегу
+CREATE INDEX IF NOT EXISTS idx_song_genres_genre_id ON song_genres (genre_id);
+CREATE INDEX IF NOT EXISTS idx_song_tags_tag_id ON song_tags (tag_id);
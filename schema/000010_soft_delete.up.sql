@@ -0,0 +1,5 @@
+-- Мягкое удаление песен: удалённые песни хранятся в корзине до окончательной очистки
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Создать индекс для выборки корзины и очистки по давности удаления
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at) WHERE deleted_at IS NOT NULL;
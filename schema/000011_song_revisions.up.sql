@@ -0,0 +1,26 @@
+-- История изменений песен: каждая ревизия хранит полный снимок текста и метаданных
+CREATE TABLE IF NOT EXISTS song_revisions (
+    song_id INT REFERENCES songs(id) ON DELETE CASCADE NOT NULL,
+    revision INT NOT NULL CHECK (revision > 0),
+    author VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reverted_from INT,
+    snapshot JSONB NOT NULL,
+    PRIMARY KEY (song_id, revision)
+);
+
+-- Сохранить текущее состояние существующих песен как первую ревизию
+INSERT INTO song_revisions (song_id, revision, author, snapshot)
+SELECT s.id, 1, 'system', jsonb_build_object(
+    'song', s.song,
+    'group', g."group",
+    'text', s.text,
+    'releaseDate', COALESCE(to_char(s.release_date, 'DD.MM.YYYY'), ''),
+    'link', s.link,
+    'artists', COALESCE((SELECT jsonb_agg(jsonb_build_object('group', ag."group", 'role', sa.role)
+        ORDER BY array_position(ARRAY['featured', 'composer', 'lyricist'], sa.role::text), ag."group")
+        FROM song_artists sa INNER JOIN groups ag ON sa.group_id = ag.id
+        WHERE sa.song_id = s.id AND sa.role <> 'primary'), '[]'::jsonb))
+FROM songs s
+INNER JOIN groups g ON s.group_id = g.id
+ON CONFLICT DO NOTHING;
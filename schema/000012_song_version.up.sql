@@ -0,0 +1,2 @@
+-- Версия песни для оптимистичной блокировки: увеличивается при каждом изменении песни
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_songs_group_title_key;
+ALTER TABLE songs DROP COLUMN IF EXISTS title_key;
@@ -0,0 +1,10 @@
+-- Идентичность песни в группе: название без учёта регистра, пробелов и диакритических знаков.
+-- Ключ title_key вычисляется приложением (models.SongKey), как groups.name_key: после этой
+-- миграции приложение заполняет ключи существующих песен (backfillSongTitleKeys) и делает
+-- столбец NOT NULL. Если в группе есть песни вне корзины с одинаковым ключом, миграция данных
+-- завершается ошибкой со списком повторов, ничего не удаляя: повторы нужно переименовать
+-- или переместить в корзину вручную и перезапустить сервер.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS title_key VARCHAR(255);
+
+-- Создать уникальный индекс по ключу названия песни в группе без учёта корзины
+CREATE UNIQUE INDEX IF NOT EXISTS uq_songs_group_title_key ON songs (group_id, title_key) WHERE deleted_at IS NULL;
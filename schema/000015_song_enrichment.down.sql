@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_songs_enrichment_pending;
+
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_updated_at;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_next_attempt_at;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_error;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_attempts;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_status;
@@ -0,0 +1,12 @@
+-- Обогащение песен сведениями внешнего API в фоне: новые песни ждут обогащения в статусе pending,
+-- существующие песни считаются обогащёнными
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'enriched'
+    CHECK (enrichment_status IN ('pending', 'enriched', 'failed'));
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_error TEXT;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_next_attempt_at TIMESTAMPTZ;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Создать индекс для выборки песен, ожидающих обогащения
+CREATE INDEX IF NOT EXISTS idx_songs_enrichment_pending ON songs (enrichment_next_attempt_at)
+    WHERE enrichment_status = 'pending';
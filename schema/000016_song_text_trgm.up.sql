@@ -0,0 +1,2 @@
+-- Создать триграммный индекс по текстам песен для поиска похожих песен оператором %
+CREATE INDEX IF NOT EXISTS idx_songs_text_trgm ON songs USING GIN (text gin_trgm_ops);
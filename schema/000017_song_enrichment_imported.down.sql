@@ -0,0 +1,5 @@
+UPDATE songs SET enrichment_status = 'enriched' WHERE enrichment_status = 'imported';
+
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS songs_enrichment_status_check;
+ALTER TABLE songs ADD CONSTRAINT songs_enrichment_status_check
+    CHECK (enrichment_status IN ('pending', 'enriched', 'failed'));
@@ -0,0 +1,5 @@
+-- Статус обогащения imported: песни из импорта каталога не запрашиваются во внешнем API,
+-- пока их не поставят в очередь через POST /songs/{id}/enrich
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS songs_enrichment_status_check;
+ALTER TABLE songs ADD CONSTRAINT songs_enrichment_status_check
+    CHECK (enrichment_status IN ('pending', 'enriched', 'failed', 'imported'));
@@ -0,0 +1,10 @@
+-- Конфигурация полнотекстового поиска, которой построен вектор search_vector.
+-- Запросы поиска читают её отсюда, поэтому запрос и вектор всегда стеммятся одинаково;
+-- при запуске с другим SEARCH_LANGUAGE приложение перестраивает вектор и меняет эту строку.
+CREATE TABLE IF NOT EXISTS search_settings (
+    id       boolean PRIMARY KEY DEFAULT true CHECK (id),
+    language regconfig NOT NULL
+);
+
+-- Вектор из миграции 000003 построен конфигурацией russian
+INSERT INTO search_settings (language) VALUES ('russian') ON CONFLICT (id) DO NOTHING;
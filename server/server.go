@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 )
@@ -9,10 +10,15 @@ import (
 // Структура Server, которая инкапсулирует HTTP-сервер
 type Server struct {
 	HttpServer *http.Server
+	cancel     context.CancelFunc
 }
 
 // Метод для запуска HTTP-сервера на указанном порту с заданным обработчиком
 func (server *Server) Run(port string, handler http.Handler) error {
+	// Базовый контекст запросов, отменяемый при завершении работы сервера
+	baseCtx, cancel := context.WithCancel(context.Background())
+	server.cancel = cancel
+
 	// Настройка HTTP-сервера
 	server.HttpServer = &http.Server{
 		Addr:           ":" + port,       // Адрес и порт для прослушивания
@@ -20,14 +26,23 @@ func (server *Server) Run(port string, handler http.Handler) error {
 		MaxHeaderBytes: 1 << 20,          // Максимальный размер заголовков (1MB)
 		ReadTimeout:    10 * time.Second, // Таймаут на чтение запроса
 		WriteTimeout:   10 * time.Second, // Таймаут на запись ответа
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// Запуск HTTP-сервера и прослушивание входящих запросов
 	return server.HttpServer.ListenAndServe()
 }
 
-// Метод для корректного завершения работы HTTP-сервера
+// Метод для корректного завершения работы HTTP-сервера.
+// Ожидает завершения активных запросов до истечения ctx, после чего отменяет их контексты.
 func (server *Server) Shutdown(ctx context.Context) error {
 	// Завершение работы HTTP-сервера с использованием контекста
-	return server.HttpServer.Shutdown(ctx)
+	err := server.HttpServer.Shutdown(ctx)
+
+	// Отмена контекстов запросов, которые не успели завершиться
+	server.cancel()
+
+	return err
 }